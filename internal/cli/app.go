@@ -3,8 +3,12 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/urfave/cli/v3"
 
@@ -13,6 +17,7 @@ import (
 	"gh.tarampamp.am/error-pages/internal/cli/healthcheck"
 	"gh.tarampamp.am/error-pages/internal/cli/perftest"
 	"gh.tarampamp.am/error-pages/internal/cli/serve"
+	"gh.tarampamp.am/error-pages/internal/logfile"
 	"gh.tarampamp.am/error-pages/internal/logger"
 )
 
@@ -22,14 +27,15 @@ import (
 func NewApp(appName string) *cli.Command {
 	var (
 		logLevelFlag = cli.StringFlag{
-			Name:     "log-level",
-			Value:    logger.InfoLevel.String(),
-			Usage:    "Logging level (" + strings.Join(logger.LevelStrings(), "/") + ")",
+			Name:  "log-level",
+			Value: logger.InfoLevel.String(),
+			Usage: "Logging level (" + strings.Join(logger.LevelStrings(), "/") + "), optionally per component " +
+				"(e.g., info,http=warn,render=debug)",
 			Sources:  cli.EnvVars("LOG_LEVEL"),
 			OnlyOnce: true,
 			Config:   cli.StringConfig{TrimSpace: true},
 			Validator: func(s string) error {
-				if _, err := logger.ParseLevel(s); err != nil {
+				if _, err := logger.ParseLevelSpec(s); err != nil {
 					return err
 				}
 
@@ -52,8 +58,55 @@ func NewApp(appName string) *cli.Command {
 				return nil
 			},
 		}
+
+		logFileFlag = cli.StringFlag{
+			Name:     "log-file",
+			Usage:    "Write logs to this file instead of stderr (the file is reopened on SIGUSR1 for the external rotation)",
+			Sources:  cli.EnvVars("LOG_FILE"),
+			OnlyOnce: true,
+			Config:   cli.StringConfig{TrimSpace: true},
+		}
+
+		logFileMaxSizeFlag = cli.UintFlag{
+			Name:     "log-file-max-size",
+			Value:    100, //nolint:mnd
+			Usage:    "Rotate the log file when its size exceeds this value in megabytes (0 to disable)",
+			Sources:  cli.EnvVars("LOG_FILE_MAX_SIZE"),
+			OnlyOnce: true,
+		}
+
+		logFileMaxAgeFlag = cli.DurationFlag{
+			Name:     "log-file-max-age",
+			Usage:    "Rotate the log file when it's older than this duration (e.g., 24h; 0 to disable)",
+			Sources:  cli.EnvVars("LOG_FILE_MAX_AGE"),
+			OnlyOnce: true,
+			Validator: func(d time.Duration) error {
+				if d < 0 {
+					return fmt.Errorf("wrong log file max age [%s]: it should not be negative", d)
+				}
+
+				return nil
+			},
+		}
+
+		logFileMaxBackupsFlag = cli.UintFlag{
+			Name:     "log-file-max-backups",
+			Value:    5, //nolint:mnd
+			Usage:    "Number of rotated log files to keep (0 to keep all)",
+			Sources:  cli.EnvVars("LOG_FILE_MAX_BACKUPS"),
+			OnlyOnce: true,
+		}
+
+		logFileCompressFlag = cli.BoolFlag{
+			Name:     "log-file-compress",
+			Usage:    "Compress the rotated log files using gzip",
+			Sources:  cli.EnvVars("LOG_FILE_COMPRESS"),
+			OnlyOnce: true,
+		}
 	)
 
+	var logFile *logfile.File // nil if logs are written to stderr
+
 	// create a "default" logger (will be swapped later with customized)
 	var log, _ = logger.New(logger.InfoLevel, logger.ConsoleFormat) // error will never occur
 
@@ -62,19 +115,46 @@ func NewApp(appName string) *cli.Command {
 		Suggest: true,
 		Before: func(ctx context.Context, c *cli.Command) (context.Context, error) {
 			var (
-				logLevel, _  = logger.ParseLevel(c.String(logLevelFlag.Name))   // error ignored because the flag validates itself
-				logFormat, _ = logger.ParseFormat(c.String(logFormatFlag.Name)) // --//--
+				logLevels, _ = logger.ParseLevelSpec(c.String(logLevelFlag.Name)) // error ignored because the flag validates itself
+				logFormat, _ = logger.ParseFormat(c.String(logFormatFlag.Name))   // --//--
 			)
 
-			configured, err := logger.New(logLevel, logFormat) // create a new logger instance
+			var writer io.Writer // nil means stderr
+
+			if path := c.String(logFileFlag.Name); path != "" {
+				f, err := logfile.Open(path, logfile.Options{
+					MaxSize:    int64(c.Uint(logFileMaxSizeFlag.Name)) * 1024 * 1024, //nolint:gosec,mnd
+					MaxAge:     c.Duration(logFileMaxAgeFlag.Name),
+					MaxBackups: c.Uint(logFileMaxBackupsFlag.Name),
+					Compress:   c.Bool(logFileCompressFlag.Name),
+				})
+				if err != nil {
+					return ctx, err
+				}
+
+				logFile, writer = f, f
+
+				go reopenOnSignal(ctx, f)
+			}
+
+			configured, err := logger.New(logLevels[""], logFormat, writer) // create a new logger instance
 			if err != nil {
 				return ctx, err
 			}
 
+			configured.ComponentLevels().Apply(logLevels) // set the per-component levels
+
 			*log = *configured // swap the "default" logger with customized
 
 			return ctx, nil
 		},
+		After: func(context.Context, *cli.Command) error {
+			if logFile != nil {
+				return logFile.Close()
+			}
+
+			return nil
+		},
 		Commands: []*cli.Command{
 			serve.NewCommand(log),
 			build.NewCommand(log),
@@ -85,6 +165,35 @@ func NewApp(appName string) *cli.Command {
 		Flags: []cli.Flag{ // global flags
 			&logLevelFlag,
 			&logFormatFlag,
+			&logFileFlag,
+			&logFileMaxSizeFlag,
+			&logFileMaxAgeFlag,
+			&logFileMaxBackupsFlag,
+			&logFileCompressFlag,
 		},
 	}
 }
+
+// reopenOnSignal reopens the log file every time the signal is received (until the context is canceled). This
+// allows the external tools (like logrotate) to move the log file away.
+func reopenOnSignal(ctx context.Context, f *logfile.File) {
+	if len(reopenLogFileSignals) == 0 {
+		return
+	}
+
+	var sig = make(chan os.Signal, 1)
+
+	signal.Notify(sig, reopenLogFileSignals...)
+	defer signal.Stop(sig)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sig:
+			if err := f.Reopen(); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "cannot reopen the log file: %s\n", err)
+			}
+		}
+	}
+}
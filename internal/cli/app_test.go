@@ -2,9 +2,11 @@ package cli_test
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"gh.tarampamp.am/error-pages/internal/cli"
 )
@@ -16,3 +18,15 @@ func TestNewApp(t *testing.T) {
 
 	assert.NoError(t, app.Run(context.Background(), []string{""}))
 }
+
+func TestNewApp_LogFile(t *testing.T) {
+	t.Parallel()
+
+	var path = filepath.Join(t.TempDir(), "logs", "app.log")
+
+	app := cli.NewApp("appName")
+
+	require.NoError(t, app.Run(context.Background(), []string{"", "--log-file", path, "--log-file-compress"}))
+
+	assert.FileExists(t, path)
+}
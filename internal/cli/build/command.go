@@ -30,6 +30,13 @@ type command struct {
 	opt struct {
 		createIndex      bool
 		targetDirAbsPath string
+		ssiDialect       string // empty if the server-side includes are disabled
+
+		emitConfig struct {
+			server       string // empty if the web server config snippet is not needed
+			templateName string
+			root         string
+		}
 	}
 }
 
@@ -50,6 +57,58 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit
 			Usage:    "Generate index.html file with links to all error pages",
 			Category: shared.CategoryBuild,
 		}
+		ssiFlag = cli.BoolFlag{
+			Name: "ssi",
+			Usage: "Render the request details (request ID, host, original URI, etc.) as server-side includes " +
+				"directives, so the web server fills them in per request",
+			Category: shared.CategoryBuild,
+		}
+		ssiDialectFlag = cli.StringFlag{
+			Name:     "ssi-dialect",
+			Usage:    "Server-side includes dialect (" + strings.Join(ssiDialects, "/") + ")",
+			Value:    ssiNginx,
+			Config:   cli.StringConfig{TrimSpace: true},
+			Category: shared.CategoryBuild,
+			OnlyOnce: true,
+			Validator: func(dialect string) error {
+				if !slices.Contains(ssiDialects, dialect) {
+					return fmt.Errorf("unsupported server-side includes dialect: %q", dialect)
+				}
+
+				return nil
+			},
+		}
+		emitConfigFlag = cli.StringFlag{
+			Name: "emit-config",
+			Usage: "Write a ready-to-include web server config snippet, mapping every code to the built error page (" +
+				strings.Join(webServerNames(), "/") + ")",
+			Config:   cli.StringConfig{TrimSpace: true},
+			Category: shared.CategoryBuild,
+			OnlyOnce: true,
+			Validator: func(server string) error {
+				if _, ok := webServerConfigs[server]; !ok {
+					return fmt.Errorf("unsupported web server: %q", server)
+				}
+
+				return nil
+			},
+		}
+		emitConfigTemplateFlag = cli.StringFlag{
+			Name:     "emit-config-template",
+			Usage:    "Name of the template to use in the web server config snippet",
+			Value:    cfg.TemplateName,
+			Config:   cli.StringConfig{TrimSpace: true},
+			Category: shared.CategoryBuild,
+			OnlyOnce: true,
+		}
+		emitConfigRootFlag = cli.StringFlag{
+			Name: "emit-config-root",
+			Usage: "Path to the built error pages on the web server, used in the config snippet (the target directory " +
+				"by default)",
+			Config:   cli.StringConfig{TrimSpace: true},
+			Category: shared.CategoryBuild,
+			OnlyOnce: true,
+		}
 		targetDirFlag = cli.StringFlag{
 			Name:     "target-dir",
 			Aliases:  []string{"out", "dir", "o"},
@@ -86,6 +145,17 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit
 			cmd.opt.createIndex = c.Bool(createIndexFlag.Name)
 			cmd.opt.targetDirAbsPath, _ = filepath.Abs(c.String(targetDirFlag.Name)) // an error checked by [os.Stat] validator
 
+			if c.Bool(ssiFlag.Name) {
+				cmd.opt.ssiDialect = c.String(ssiDialectFlag.Name)
+			}
+
+			cmd.opt.emitConfig.server = c.String(emitConfigFlag.Name)
+			cmd.opt.emitConfig.templateName = c.String(emitConfigTemplateFlag.Name)
+
+			if cmd.opt.emitConfig.root = c.String(emitConfigRootFlag.Name); cmd.opt.emitConfig.root == "" {
+				cmd.opt.emitConfig.root = cmd.opt.targetDirAbsPath
+			}
+
 			// add templates from files to the configuration
 			if add := c.StringSlice(addTplFlag.Name); len(add) > 0 {
 				for _, templatePath := range add {
@@ -126,11 +196,17 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit
 				return errors.New("no templates specified")
 			}
 
+			if cmd.opt.emitConfig.server != "" && !cfg.Templates.Has(cmd.opt.emitConfig.templateName) {
+				return fmt.Errorf("template '%s' for the web server config not found", cmd.opt.emitConfig.templateName)
+			}
+
 			log.Info("Building error pages",
 				logger.String("targetDir", cmd.opt.targetDirAbsPath),
 				logger.Strings("templates", cfg.Templates.Names()...),
 				logger.Bool("index", cmd.opt.createIndex),
 				logger.Bool("l10n", !cfg.L10n.Disable),
+				logger.String("ssi", cmd.opt.ssiDialect),
+				logger.String("emit config", cmd.opt.emitConfig.server),
 			)
 
 			return cmd.Run(ctx, log, &cfg)
@@ -142,6 +218,11 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit
 			&disableL10nFlag,
 			&createIndexFlag,
 			&targetDirFlag,
+			&ssiFlag,
+			&ssiDialectFlag,
+			&emitConfigFlag,
+			&emitConfigTemplateFlag,
+			&emitConfigRootFlag,
 			&disableMinificationFlag,
 		},
 	}
@@ -173,15 +254,27 @@ func (cmd *command) Run( //nolint:funlen,gocognit
 				continue
 			}
 
-			var outFilePath = path.Join(cmd.opt.targetDirAbsPath, templateName, code+".html")
+			var (
+				outFilePath = path.Join(cmd.opt.targetDirAbsPath, templateName, code+".html")
+				props       = appTemplate.Props{
+					Code:               uint16(codeAsUint), //nolint:gosec
+					Message:            codeDescription.Message,
+					Description:        codeDescription.Description,
+					L10nDisabled:       cfg.L10n.Disable,
+					ShowRequestDetails: false,
+				}
+			)
+
+			var (
+				source  = templateContent
+				fillSSI = func(content string) string { return content } // noop
+			)
+
+			if cmd.opt.ssiDialect != "" {
+				source, fillSSI = setSSIDetails(&props, templateContent, cmd.opt.ssiDialect)
+			}
 
-			if content, renderErr := appTemplate.Render(templateContent, appTemplate.Props{ //nolint:nestif
-				Code:               uint16(codeAsUint), //nolint:gosec
-				Message:            codeDescription.Message,
-				Description:        codeDescription.Description,
-				L10nDisabled:       cfg.L10n.Disable,
-				ShowRequestDetails: false,
-			}); renderErr == nil {
+			if content, renderErr := appTemplate.Render(source, props); renderErr == nil { //nolint:nestif
 				if !cfg.DisableMinification {
 					if mini, minErr := appTemplate.MiniHTML(content); minErr != nil {
 						log.Warn("Cannot minify the content", logger.Error(minErr))
@@ -190,7 +283,7 @@ func (cmd *command) Run( //nolint:funlen,gocognit
 					}
 				}
 
-				if err := os.WriteFile(outFilePath, []byte(content), os.FileMode(0664)); err != nil { //nolint:mnd
+				if err := os.WriteFile(outFilePath, []byte(fillSSI(content)), os.FileMode(0664)); err != nil { //nolint:mnd
 					return err
 				}
 			} else {
@@ -207,6 +300,12 @@ func (cmd *command) Run( //nolint:funlen,gocognit
 		}
 	}
 
+	if cmd.opt.emitConfig.server != "" {
+		if err := cmd.writeWebServerConfig(log, cfg.Codes); err != nil {
+			return err
+		}
+	}
+
 	if cmd.opt.createIndex {
 		log.Debug("Creating the index file")
 
@@ -235,6 +334,36 @@ func (cmd *command) Run( //nolint:funlen,gocognit
 	return nil
 }
 
+// writeWebServerConfig writes the web server config snippet for the built error pages into the target directory.
+func (cmd *command) writeWebServerConfig(log *logger.Logger, codes config.Codes) error {
+	var (
+		emit   = cmd.opt.emitConfig
+		server = webServerConfigs[emit.server]
+		list   = make([]uint16, 0, len(codes))
+	)
+
+	for code := range codes {
+		if codeAsUint, err := strconv.ParseUint(code, 10, 16); err == nil { // the same codes as for the built pages
+			list = append(list, uint16(codeAsUint))
+		}
+	}
+
+	content, err := server.render(emit.root, emit.templateName, list, cmd.opt.ssiDialect != "")
+	if err != nil {
+		return err
+	}
+
+	var outFilePath = filepath.Join(cmd.opt.targetDirAbsPath, server.fileName)
+
+	if err = os.WriteFile(outFilePath, []byte(content), os.FileMode(0664)); err != nil { //nolint:mnd
+		return err
+	}
+
+	log.Info("Web server config written", logger.String("path", outFilePath))
+
+	return nil
+}
+
 func createDirectory(path string) error {
 	var stat, err = os.Stat(path)
 	if err != nil {
@@ -0,0 +1,71 @@
+package build_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gh.tarampamp.am/error-pages/internal/cli/build"
+	"gh.tarampamp.am/error-pages/internal/logger"
+)
+
+func TestCommand_Run_SSI(t *testing.T) {
+	t.Parallel()
+
+	for dialect, want := range map[string][]string{
+		"nginx": {
+			`<!--# echo var="request_id" default="" -->`,
+			`<!--# config timefmt="%s" --><!--# echo var="date_local" -->`,
+			`<!--# echo var="request_uri" default="" -->`,
+			`<!--# echo var="host" default="" -->`,
+		},
+		"apache": {
+			`<!--#config echomsg="" --><!--#echo encoding="entity" var="UNIQUE_ID" -->`,
+			`<!--#config echomsg="" --><!--#echo encoding="entity" var="REDIRECT_URL" -->`,
+			`<!--#config timefmt="%s" --><!--#echo var="DATE_LOCAL" -->`,
+		},
+		"esi": {
+			`<esi:vars>$url_encode($(HTTP_X_REQUEST_ID))</esi:vars>`,
+			`<esi:vars>$url_encode($(HTTP_X_ORIGINAL_URI))</esi:vars>`,
+		},
+	} {
+		t.Run(dialect, func(t *testing.T) {
+			t.Parallel()
+
+			var dir = t.TempDir()
+
+			require.NoError(t, build.NewCommand(logger.NewNop()).Run(context.Background(), []string{
+				"build", "--target-dir", dir, "--ssi", "--ssi-dialect", dialect,
+			}))
+
+			content, err := os.ReadFile(filepath.Join(dir, "ghost", "404.html"))
+			require.NoError(t, err)
+
+			// the directives survive the minification (enabled by default), and no placeholders are left
+			for _, directive := range want {
+				assert.Contains(t, string(content), directive)
+			}
+
+			assert.NotContains(t, string(content), "ssi-placeholder")
+			assert.NotRegexp(t, `\d{10}`, string(content)) // the build time is not shown as the timestamp
+		})
+	}
+}
+
+func TestCommand_Run_WithoutSSI(t *testing.T) {
+	t.Parallel()
+
+	var dir = t.TempDir()
+
+	require.NoError(t, build.NewCommand(logger.NewNop()).Run(context.Background(), []string{"build", "--target-dir", dir}))
+
+	content, err := os.ReadFile(filepath.Join(dir, "ghost", "404.html"))
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(content), "<!--#")
+	assert.NotContains(t, string(content), "ssi-placeholder")
+}
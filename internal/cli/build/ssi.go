@@ -0,0 +1,129 @@
+package build
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	appTemplate "gh.tarampamp.am/error-pages/internal/template"
+)
+
+// Server-side includes dialects, used to fill in the request details by the web server when serving the pre-built
+// error pages.
+const (
+	ssiNginx  = "nginx"  // https://nginx.org/en/docs/http/ngx_http_ssi_module.html
+	ssiApache = "apache" // https://httpd.apache.org/docs/current/mod/mod_include.html
+	ssiESI    = "esi"    // https://www.w3.org/TR/esi-lang/ (Akamai, Fastly, etc.)
+)
+
+// ssiDialects is a list of the supported server-side includes dialects.
+var ssiDialects = []string{ssiNginx, ssiApache, ssiESI} //nolint:gochecknoglobals
+
+// ssiVariables maps the request details to the web server variables for every dialect.
+var ssiVariables = map[string]struct { //nolint:gochecknoglobals
+	requestID, host, originalURI, forwardedFor, namespace, ingressName, serviceName, servicePort string
+}{
+	ssiNginx: {
+		requestID:    "request_id",
+		host:         "host",
+		originalURI:  "request_uri", // the original request URI, even after the internal redirect to the error page
+		forwardedFor: "http_x_forwarded_for",
+		namespace:    "http_x_namespace",
+		ingressName:  "http_x_ingress_name",
+		serviceName:  "http_x_service_name",
+		servicePort:  "http_x_service_port",
+	},
+	ssiApache: {
+		requestID:    "UNIQUE_ID", // mod_unique_id
+		host:         "HTTP_HOST",
+		originalURI:  "REDIRECT_URL", // the original URL path, set for the ErrorDocument
+		forwardedFor: "HTTP_X_FORWARDED_FOR",
+		namespace:    "HTTP_X_NAMESPACE",
+		ingressName:  "HTTP_X_INGRESS_NAME",
+		serviceName:  "HTTP_X_SERVICE_NAME",
+		servicePort:  "HTTP_X_SERVICE_PORT",
+	},
+	ssiESI: {
+		requestID:    "HTTP_X_REQUEST_ID",
+		host:         "HTTP_HOST",
+		originalURI:  "HTTP_X_ORIGINAL_URI",
+		forwardedFor: "HTTP_X_FORWARDED_FOR",
+		namespace:    "HTTP_X_NAMESPACE",
+		ingressName:  "HTTP_X_INGRESS_NAME",
+		serviceName:  "HTTP_X_SERVICE_NAME",
+		servicePort:  "HTTP_X_SERVICE_PORT",
+	},
+}
+
+// ssiDirective returns the directive that outputs the given variable value (HTML-escaped, or URL-encoded for ESI) in
+// the given dialect.
+func ssiDirective(dialect, variable string) string {
+	switch dialect {
+	case ssiApache:
+		// the "echomsg" is set to avoid the "(none)" output for the unset variables
+		return fmt.Sprintf(`<!--#config echomsg="" --><!--#echo encoding="entity" var="%s" -->`, variable)
+	case ssiESI:
+		// ESI has no HTML-escaping function, so the (client-controlled) value is URL-encoded to keep the markup safe
+		return fmt.Sprintf(`<esi:vars>$url_encode($(%s))</esi:vars>`, variable)
+	default:
+		return fmt.Sprintf(`<!--# echo var="%s" default="" -->`, variable)
+	}
+}
+
+// ssiTimestamp returns the directive that outputs the current unix time in the given dialect (ESI has no such
+// variable, so the timestamp is left empty).
+func ssiTimestamp(dialect string) string {
+	switch dialect {
+	case ssiApache:
+		return `<!--#config timefmt="%s" --><!--#echo var="DATE_LOCAL" -->`
+	case ssiESI:
+		return ""
+	default:
+		return `<!--# config timefmt="%s" --><!--# echo var="date_local" -->`
+	}
+}
+
+// nowUnixAction matches the template actions calling the `nowUnix` function.
+var nowUnixAction = regexp.MustCompile(`\{\{[^}]*\bnowUnix\b[^}]*}}`) //nolint:gochecknoglobals
+
+// setSSIDetails sets the request details of the props to the placeholders and returns the function that replaces
+// them in the rendered (and minified) content with the server-side includes directives, so the web server fills them
+// in per request. The placeholders are needed because the templates may escape the values (e.g., the request ID).
+//
+// The `nowUnix` calls of the template are replaced with the placeholder too (the returned template should be
+// rendered instead of the original one), so the page shows the time of the request, not of the build.
+func setSSIDetails(props *appTemplate.Props, tpl, dialect string) (_ string, fill func(content string) string) {
+	var (
+		vars  = ssiVariables[dialect]
+		pairs = make([]string, 0, 16) //nolint:mnd
+	)
+
+	// placeholder returns a placeholder for the variable (containing only the characters that are never escaped)
+	var placeholder = func(variable string) string {
+		var p = "ssi-placeholder-" + strings.ToLower(strings.ReplaceAll(variable, "_", "-"))
+
+		pairs = append(pairs, p, ssiDirective(dialect, variable))
+
+		return p
+	}
+
+	props.ShowRequestDetails = true
+	props.RequestID = placeholder(vars.requestID)
+	props.Host = placeholder(vars.host)
+	props.OriginalURI = placeholder(vars.originalURI)
+	props.ForwardedFor = placeholder(vars.forwardedFor)
+	props.Namespace = placeholder(vars.namespace)
+	props.IngressName = placeholder(vars.ingressName)
+	props.ServiceName = placeholder(vars.serviceName)
+	props.ServicePort = placeholder(vars.servicePort)
+
+	const timestamp = "ssi-placeholder-timestamp"
+
+	pairs = append(pairs, timestamp, ssiTimestamp(dialect))
+
+	tpl = nowUnixAction.ReplaceAllStringFunc(tpl, func(action string) string {
+		return strings.ReplaceAll(action, "nowUnix", `"`+timestamp+`"`) // the function call becomes a string literal
+	})
+
+	return tpl, strings.NewReplacer(pairs...).Replace
+}
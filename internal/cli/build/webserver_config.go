@@ -0,0 +1,148 @@
+package build
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// webServerConfig describes a web server config snippet, generated for the built error pages.
+type webServerConfig struct {
+	fileName string             // the snippet file name
+	tpl      *template.Template // the snippet template (see [webServerConfigData] for the available data)
+	codes    []uint16           // the codes supported by the web server (nil means any)
+}
+
+// webServerConfigData is the data for the web server config snippet templates.
+type webServerConfigData struct {
+	Root     string   // the directory where the built error pages are located on the web server
+	Template string   // the template name (the error pages are located in the Root/Template directory)
+	Codes    []string // the HTTP codes (sorted)
+	SSI      bool     // the error pages contain the server-side includes directives
+}
+
+// the web server config snippet templates (see [webServerConfigData] for the available data)
+//
+//nolint:lll
+const (
+	// https://nginx.org/en/docs/http/ngx_http_core_module.html#error_page
+	nginxConfig = `# include this file into the "server" block
+{{- range .Codes }}
+error_page {{ . }} /_error-pages/{{ . }}.html;
+{{- end }}
+
+location ^~ /_error-pages/ {
+  internal;
+  alias {{ .Root }}/{{ .Template }}/;
+{{- if .SSI }}
+  ssi on;
+{{- end }}
+}
+`
+
+	// https://httpd.apache.org/docs/current/mod/core.html#errordocument
+	apacheConfig = `# include this file into the "VirtualHost" block
+Alias "/_error-pages/" "{{ .Root }}/{{ .Template }}/"
+
+<Directory "{{ .Root }}/{{ .Template }}">
+  Require all granted
+{{- if .SSI }}
+  Options +Includes
+  AddOutputFilter INCLUDES .html
+{{- end }}
+</Directory>
+{{ range .Codes }}
+ErrorDocument {{ . }} /_error-pages/{{ . }}.html
+{{- end }}
+`
+
+	// https://caddyserver.com/docs/caddyfile/directives/handle_errors
+	caddyConfig = `# include this file into the site block
+handle_errors{{ range .Codes }} {{ . }}{{ end }} {
+  root * {{ .Root }}
+  rewrite * /{{ .Template }}/{err.status_code}.html
+  file_server {
+    status {err.status_code}
+  }
+}
+`
+
+	// https://docs.haproxy.org/2.8/configuration.html#4.2-http-error
+	haproxyConfig = `# include these lines into the "defaults", "frontend" or "backend" section
+{{- range .Codes }}
+http-error status {{ . }} content-type "text/html; charset=utf-8" file {{ $.Root }}/{{ $.Template }}/{{ . }}.html
+{{- end }}
+`
+
+	// https://doc.traefik.io/traefik/middlewares/http/errorpages/
+	traefikConfig = `# dynamic configuration; the "error-pages" service should serve the files from the {{ .Root }} directory
+http:
+  middlewares:
+    error-pages:
+      errors:
+        status:
+{{- range .Codes }}
+          - "{{ . }}"
+{{- end }}
+        service: error-pages
+        query: "/{{ .Template }}/{status}.html"
+`
+
+	// https://redmine.lighttpd.net/projects/lighttpd/wiki/Server_errorfile-prefixDetails
+	lighttpdConfig = `# include this file into the main configuration
+# error pages for the codes:{{ range .Codes }} {{ . }}{{ end }}
+server.errorfile-prefix = "{{ .Root }}/{{ .Template }}/"
+`
+)
+
+// webServerConfigs maps the web server names to their config snippets.
+var webServerConfigs = map[string]webServerConfig{ //nolint:gochecknoglobals
+	"nginx":  {fileName: "nginx.conf", tpl: template.Must(template.New("nginx").Parse(nginxConfig))},
+	"apache": {fileName: "apache.conf", tpl: template.Must(template.New("apache").Parse(apacheConfig))},
+	"caddy":  {fileName: "Caddyfile", tpl: template.Must(template.New("caddy").Parse(caddyConfig))},
+	"haproxy": {
+		fileName: "haproxy.cfg",
+		tpl:      template.Must(template.New("haproxy").Parse(haproxyConfig)),
+		// the codes supported by HAProxy for the error messages
+		codes: []uint16{200, 400, 401, 403, 404, 405, 407, 408, 410, 413, 425, 429, 500, 501, 502, 503, 504}, //nolint:mnd
+	},
+	"traefik":  {fileName: "traefik.yml", tpl: template.Must(template.New("traefik").Parse(traefikConfig))},
+	"lighttpd": {fileName: "lighttpd.conf", tpl: template.Must(template.New("lighttpd").Parse(lighttpdConfig))},
+}
+
+// webServerNames returns the sorted list of the supported web servers.
+func webServerNames() []string {
+	var names = make([]string, 0, len(webServerConfigs))
+
+	for name := range webServerConfigs {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// render renders the config snippet for the given error pages codes (the codes that are not supported by the web
+// server are skipped). The ssi enables the server-side includes processing (for the web servers that support it).
+func (c webServerConfig) render(root, templateName string, codes []uint16, ssi bool) (string, error) {
+	var data = webServerConfigData{Root: strings.TrimRight(root, "/"), Template: templateName, SSI: ssi}
+
+	slices.Sort(codes)
+
+	for _, code := range slices.Compact(codes) {
+		if c.codes == nil || slices.Contains(c.codes, code) {
+			data.Codes = append(data.Codes, strconv.FormatUint(uint64(code), 10))
+		}
+	}
+
+	var buf strings.Builder
+
+	if err := c.tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("cannot render the %s config: %w", c.fileName, err)
+	}
+
+	return buf.String(), nil
+}
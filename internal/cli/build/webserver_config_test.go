@@ -0,0 +1,99 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebServerConfig_Render(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		giveServer string
+		giveCodes  []uint16
+		giveSSI    bool
+		wantConfig string
+	}{
+		"nginx": {
+			giveServer: "nginx",
+			giveCodes:  []uint16{503, 404, 503}, // sorted and deduplicated
+			wantConfig: `# include this file into the "server" block
+error_page 404 /_error-pages/404.html;
+error_page 503 /_error-pages/503.html;
+
+location ^~ /_error-pages/ {
+  internal;
+  alias /srv/errors/ghost/;
+}
+`,
+		},
+		"nginx with ssi": {
+			giveServer: "nginx",
+			giveCodes:  []uint16{503, 404, 503}, // sorted and deduplicated
+			giveSSI:    true,
+			wantConfig: `# include this file into the "server" block
+error_page 404 /_error-pages/404.html;
+error_page 503 /_error-pages/503.html;
+
+location ^~ /_error-pages/ {
+  internal;
+  alias /srv/errors/ghost/;
+  ssi on;
+}
+`,
+		},
+		"apache": {
+			giveServer: "apache",
+			giveCodes:  []uint16{503, 404, 503},
+			wantConfig: `# include this file into the "VirtualHost" block
+Alias "/_error-pages/" "/srv/errors/ghost/"
+
+<Directory "/srv/errors/ghost">
+  Require all granted
+</Directory>
+
+ErrorDocument 404 /_error-pages/404.html
+ErrorDocument 503 /_error-pages/503.html
+`,
+		},
+		"apache with ssi": {
+			giveServer: "apache",
+			giveCodes:  []uint16{503, 404, 503},
+			giveSSI:    true,
+			wantConfig: `# include this file into the "VirtualHost" block
+Alias "/_error-pages/" "/srv/errors/ghost/"
+
+<Directory "/srv/errors/ghost">
+  Require all granted
+  Options +Includes
+  AddOutputFilter INCLUDES .html
+</Directory>
+
+ErrorDocument 404 /_error-pages/404.html
+ErrorDocument 503 /_error-pages/503.html
+`,
+		},
+		"haproxy (unsupported codes are skipped)": {
+			giveServer: "haproxy",
+			giveCodes:  []uint16{599, 503, 404},
+			wantConfig: `# include these lines into the "defaults", "frontend" or "backend" section
+http-error status 404 content-type "text/html; charset=utf-8" file /srv/errors/ghost/404.html
+http-error status 503 content-type "text/html; charset=utf-8" file /srv/errors/ghost/503.html
+`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var server, ok = webServerConfigs[tt.giveServer]
+			require.True(t, ok)
+
+			var config, err = server.render("/srv/errors/", "ghost", tt.giveCodes, tt.giveSSI) // the slash is trimmed
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantConfig, config)
+		})
+	}
+}
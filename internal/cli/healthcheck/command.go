@@ -16,19 +16,26 @@ type checker interface {
 
 // NewCommand creates `healthcheck` command.
 func NewCommand(_ *logger.Logger, checker checker) *cli.Command {
-	var portFlag = shared.ListenPortFlag
+	var (
+		portFlag     = shared.ListenPortFlag
+		basePathFlag = shared.BasePathFlag
+	)
 
 	portFlag.Usage = "TCP port number with the HTTP server to check"
+	basePathFlag.Usage = "URL path prefix under which the HTTP server routes are served"
 
 	return &cli.Command{
 		Name:    "healthcheck",
 		Aliases: []string{"chk", "health", "check"},
 		Usage:   "Health checker for the HTTP server. The use case - docker health check",
 		Action: func(ctx context.Context, c *cli.Command) error {
-			return checker.Check(ctx, fmt.Sprintf("http://127.0.0.1:%d", c.Uint(portFlag.Name)))
+			return checker.Check(ctx, fmt.Sprintf(
+				"http://127.0.0.1:%d%s", c.Uint(portFlag.Name), shared.ParseBasePath(c.String(basePathFlag.Name)),
+			))
 		},
 		Flags: []cli.Flag{
 			&portFlag,
+			&basePathFlag,
 		},
 	}
 }
@@ -41,6 +41,15 @@ func TestCommand_RunSuccess(t *testing.T) {
 	require.NoError(t, cmd.Run(context.Background(), []string{"", "--port", "1234"}))
 }
 
+func TestCommand_RunWithBasePath(t *testing.T) {
+	var cmd = healthcheck.NewCommand(logger.NewNop(), &fakeHealthChecker{
+		t:           t,
+		wantAddress: "http://127.0.0.1:1234/_errors",
+	})
+
+	require.NoError(t, cmd.Run(context.Background(), []string{"", "--port", "1234", "--base-path", "_errors/"}))
+}
+
 func TestCommand_RunFail(t *testing.T) {
 	cmd := healthcheck.NewCommand(logger.NewNop(), &fakeHealthChecker{
 		t:           t,
@@ -5,7 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"os"
+	"slices"
 	"strings"
+	textTemplate "text/template"
 	"time"
 
 	"github.com/urfave/cli/v3"
@@ -14,6 +18,7 @@ import (
 	"gh.tarampamp.am/error-pages/internal/config"
 	appHttp "gh.tarampamp.am/error-pages/internal/http"
 	"gh.tarampamp.am/error-pages/internal/logger"
+	"gh.tarampamp.am/error-pages/internal/remote"
 )
 
 type command struct {
@@ -24,6 +29,7 @@ type command struct {
 			addr           string
 			port           uint16
 			readBufferSize uint
+			fastCGI        bool // serve the FastCGI protocol instead of HTTP
 		}
 	}
 }
@@ -39,6 +45,7 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit,gocy
 	var (
 		addrFlag                = shared.ListenAddrFlag
 		portFlag                = shared.ListenPortFlag
+		basePathFlag            = shared.BasePathFlag
 		addTplFlag              = shared.AddTemplatesFlag
 		disableTplFlag          = shared.DisableTemplateNamesFlag
 		addCodeFlag             = shared.AddHTTPCodesFlag
@@ -82,6 +89,44 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit,gocy
 			OnlyOnce: true,
 			Config:   trim,
 		}
+		assetsDirFlag = cli.StringFlag{
+			Name: "assets-dir",
+			Usage: "Path to the directory with static assets (images, fonts, etc.) for templates; the assets will be " +
+				"served under the '/_assets/' URL path prefix (use the '{{ asset \"logo.png\" }}' template function to get " +
+				"the URL), and the 'favicon.ico' and 'robots.txt' files from this directory will be served at the root",
+			Sources:  env("ASSETS_DIR"),
+			Category: shared.CategoryTemplates,
+			OnlyOnce: true,
+			Config:   trim,
+			Validator: func(dir string) error {
+				if stat, err := os.Stat(dir); err != nil {
+					return fmt.Errorf("cannot access the assets directory '%s': %w", dir, err)
+				} else if !stat.IsDir() {
+					return fmt.Errorf("'%s' is not a directory", dir)
+				}
+
+				return nil
+			},
+		}
+		namespacesDirFlag = cli.StringFlag{
+			Name: "namespaces-dir",
+			Usage: "Path to the directory (e.g., a mounted ConfigMap) with per-namespace overrides, selected by the " +
+				"'X-Namespace' request header: '<namespace>.html' template and '<namespace>.json' settings files " +
+				"(the changes are picked up automatically)",
+			Sources:  env("NAMESPACES_DIR"),
+			Category: shared.CategoryTemplates,
+			OnlyOnce: true,
+			Config:   trim,
+			Validator: func(dir string) error {
+				if stat, err := os.Stat(dir); err != nil {
+					return fmt.Errorf("cannot access the namespaces directory '%s': %w", dir, err)
+				} else if !stat.IsDir() {
+					return fmt.Errorf("'%s' is not a directory", dir)
+				}
+
+				return nil
+			},
+		}
 		defaultCodeToRenderFlag = cli.UintFlag{
 			Name:     "default-error-page",
 			Usage:    "The code of the default (index page, when a code is not specified) error page to render",
@@ -97,6 +142,25 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit,gocy
 			},
 			OnlyOnce: true,
 		}
+		codeSourcesFlag = cli.StringFlag{
+			Name: "code-sources",
+			Usage: "Places in the incoming request to look for the error code in, in priority order (comma-separated " +
+				"list of 'header:%name%' and 'query:%name%' items; the code in the URL path always has the highest priority)",
+			Value:    codeSourcesToString(cfg.CodeSources),
+			Sources:  env("CODE_SOURCES"),
+			Category: shared.CategoryCodes,
+			OnlyOnce: true,
+			Config:   trim,
+			Validator: func(s string) error {
+				for _, raw := range splitList(s) {
+					if _, err := config.ParseCodeSource(raw); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+		}
 		sendSameHTTPCodeFlag = cli.BoolFlag{
 			Name: "send-same-http-code",
 			Usage: "The HTTP response should have the same status code as the requested error page (by default, " +
@@ -114,6 +178,41 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit,gocy
 			Category: shared.CategoryOther,
 			OnlyOnce: true,
 		}
+		detailHeadersFlag = cli.StringFlag{
+			Name: "detail-headers",
+			Usage: "Additional request details taken from the HTTP headers (comma-separated list of 'name=header' " +
+				"items, e.g. cluster=X-Cluster-Name; available in templates as {{ detail \"name\" }})",
+			Sources:  env("DETAIL_HEADERS"),
+			Category: shared.CategoryOther,
+			OnlyOnce: true,
+			Config:   trim,
+			Validator: func(s string) error {
+				for _, raw := range splitList(s) {
+					if _, err := config.ParseDetailHeader(raw); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+		}
+		detailsMappingFlag = cli.StringFlag{
+			Name: "details-mapping",
+			Usage: "Which proxy headers are used for the request details (" +
+				strings.Join(config.DetailsMappingStrings(), "/") + "; auto tries all of them)",
+			Value:    cfg.DetailsMapping.String(),
+			Sources:  env("DETAILS_MAPPING"),
+			Category: shared.CategoryOther,
+			OnlyOnce: true,
+			Config:   trim,
+			Validator: func(s string) error {
+				if _, err := config.ParseDetailsMapping(s); err != nil {
+					return err
+				}
+
+				return nil
+			},
+		}
 		proxyHeadersListFlag = cli.StringFlag{
 			Name: "proxy-headers",
 			Usage: "HTTP headers listed here will be proxied from the original request to the error page response " +
@@ -121,7 +220,7 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit,gocy
 			Value:   strings.Join(cfg.ProxyHeaders, ","),
 			Sources: env("PROXY_HTTP_HEADERS"),
 			Validator: func(s string) error {
-				for _, raw := range strings.Split(s, ",") {
+				for _, raw := range splitList(s) {
 					if clean := strings.TrimSpace(raw); strings.ContainsRune(clean, ' ') {
 						return fmt.Errorf("whitespaces in the HTTP headers are not allowed: %s", clean)
 					}
@@ -133,6 +232,368 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit,gocy
 			OnlyOnce: true,
 			Config:   trim,
 		}
+		corsAllowedOriginsFlag = cli.StringFlag{
+			Name: "cors-allowed-origins",
+			Usage: "Origins allowed to fetch the error pages cross-origin (comma-separated list, '*' allows any " +
+				"origin; CORS is disabled if empty)",
+			Sources:  env("CORS_ALLOWED_ORIGINS"),
+			Category: shared.CategoryCORS,
+			OnlyOnce: true,
+			Config:   trim,
+		}
+		corsAllowedMethodsFlag = cli.StringFlag{
+			Name:     "cors-allowed-methods",
+			Usage:    "HTTP methods allowed for cross-origin requests (comma-separated list)",
+			Value:    strings.Join(cfg.CORS.AllowedMethods, ","),
+			Sources:  env("CORS_ALLOWED_METHODS"),
+			Category: shared.CategoryCORS,
+			OnlyOnce: true,
+			Config:   trim,
+		}
+		corsAllowedHeadersFlag = cli.StringFlag{
+			Name: "cors-allowed-headers",
+			Usage: "HTTP headers allowed for cross-origin requests (comma-separated list; if empty, the headers " +
+				"requested in the preflight request are allowed)",
+			Value:    strings.Join(cfg.CORS.AllowedHeaders, ","),
+			Sources:  env("CORS_ALLOWED_HEADERS"),
+			Category: shared.CategoryCORS,
+			OnlyOnce: true,
+			Config:   trim,
+		}
+		corsMaxAgeFlag = cli.DurationFlag{
+			Name:     "cors-max-age",
+			Usage:    "How long the results of a preflight request can be cached by the client (zero disables the header)",
+			Value:    cfg.CORS.MaxAge,
+			Sources:  env("CORS_MAX_AGE"),
+			Category: shared.CategoryCORS,
+			OnlyOnce: true,
+			Validator: func(d time.Duration) error {
+				if d < 0 {
+					return fmt.Errorf("wrong CORS max age [%s]: it should not be negative", d)
+				}
+
+				return nil
+			},
+		}
+		accessLogFormatFlag = cli.StringFlag{
+			Name:  "access-log-format",
+			Value: config.AccessLogFormatDefault.String(),
+			Usage: "HTTP access log format (" + strings.Join(config.AccessLogFormatStrings(), "/") + "; the default " +
+				"one uses the application logger, others are written to stdout or the log file, if set)",
+			Sources:  env("ACCESS_LOG_FORMAT"),
+			Category: shared.CategoryAccessLog,
+			OnlyOnce: true,
+			Config:   trim,
+			Validator: func(s string) error {
+				if _, err := config.ParseAccessLogFormat(s); err != nil {
+					return err
+				}
+
+				return nil
+			},
+		}
+		accessLogTemplateFlag = cli.StringFlag{
+			Name: "access-log-template",
+			Usage: "Go template for the access log line, used with the 'template' access log format (e.g., " +
+				"'{{ .RemoteAddr }} {{ .Method }} {{ .URI }} {{ .Status }} {{ .Duration }}')",
+			Sources:  env("ACCESS_LOG_TEMPLATE"),
+			Category: shared.CategoryAccessLog,
+			OnlyOnce: true,
+			Validator: func(s string) error {
+				if _, err := textTemplate.New("access-log").Parse(s); err != nil {
+					return fmt.Errorf("wrong access log template: %w", err)
+				}
+
+				return nil
+			},
+		}
+		accessLogFieldNamesFlag = cli.StringMapFlag{
+			Name: "access-log-field-name",
+			Usage: "Rename the access log field in the 'json' access log format (the format should be " +
+				"'%default_name%=%custom_name%', e.g., 'status=http_status')",
+			Sources:  env("ACCESS_LOG_FIELD_NAMES"),
+			Category: shared.CategoryAccessLog,
+			Config:   trim,
+		}
+		accessLogRequestHeadersFlag = cli.StringFlag{
+			Name:     "access-log-request-headers",
+			Usage:    "HTTP request headers that become the access log fields (comma-separated list)",
+			Sources:  env("ACCESS_LOG_REQUEST_HEADERS"),
+			Category: shared.CategoryAccessLog,
+			OnlyOnce: true,
+			Config:   trim,
+		}
+		accessLogResponseHeadersFlag = cli.StringFlag{
+			Name:     "access-log-response-headers",
+			Usage:    "HTTP response headers that become the access log fields (comma-separated list)",
+			Sources:  env("ACCESS_LOG_RESPONSE_HEADERS"),
+			Category: shared.CategoryAccessLog,
+			OnlyOnce: true,
+			Config:   trim,
+		}
+		accessLogSamplingFirstFlag = cli.UintFlag{
+			Name: "access-log-sampling-first",
+			Usage: "Log only the first N access log entries per second for every status code (zero disables the " +
+				"sampling; useful to protect the log pipeline from floods during upstream outages)",
+			Sources:  env("ACCESS_LOG_SAMPLING_FIRST"),
+			Category: shared.CategoryAccessLog,
+			OnlyOnce: true,
+		}
+		accessLogSamplingThereafterFlag = cli.UintFlag{
+			Name:     "access-log-sampling-thereafter",
+			Usage:    "After the first N access log entries, log only every Mth entry within the same second (zero means none)",
+			Sources:  env("ACCESS_LOG_SAMPLING_THEREAFTER"),
+			Category: shared.CategoryAccessLog,
+			OnlyOnce: true,
+		}
+		accessLogSamplingSummaryFlag = cli.DurationFlag{
+			Name:     "access-log-sampling-summary-interval",
+			Usage:    "How often to log the summary with the number of access log entries dropped by the sampling",
+			Value:    cfg.AccessLog.Sampling.SummaryInterval,
+			Sources:  env("ACCESS_LOG_SAMPLING_SUMMARY_INTERVAL"),
+			Category: shared.CategoryAccessLog,
+			OnlyOnce: true,
+			Validator: func(d time.Duration) error {
+				if d <= 0 {
+					return fmt.Errorf("wrong sampling summary interval [%s]: it should be positive", d)
+				}
+
+				return nil
+			},
+		}
+		redactHeadersFlag = cli.StringFlag{
+			Name: "redact-headers",
+			Usage: "Values of the HTTP headers listed here will be masked in the logs and the error page details " +
+				"(comma-separated list; set an empty string to disable the redaction)",
+			Value:    strings.Join(cfg.Redaction.Headers, ","),
+			Sources:  env("REDACT_HEADERS"),
+			Category: shared.CategoryOther,
+			OnlyOnce: true,
+			Config:   trim,
+		}
+		redactKeepCharsFlag = cli.UintFlag{
+			Name:     "redact-keep-chars",
+			Usage:    "Number of leading characters to keep unmasked in the redacted HTTP header values",
+			Value:    cfg.Redaction.KeepChars,
+			Sources:  env("REDACT_KEEP_CHARS"),
+			Category: shared.CategoryOther,
+			OnlyOnce: true,
+		}
+		proxyUpstreamFlag = cli.StringFlag{
+			Name: "proxy-upstream",
+			Usage: "Enable the reverse proxy mode: forward the requests to this upstream URL and replace its error " +
+				"responses with the error pages (e.g., http://127.0.0.1:8080)",
+			Sources:  env("PROXY_UPSTREAM"),
+			Category: shared.CategoryProxy,
+			OnlyOnce: true,
+			Config:   trim,
+			Validator: func(s string) error {
+				if s == "" {
+					return nil
+				}
+
+				if u, err := url.Parse(s); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return fmt.Errorf("wrong proxy upstream [%s]: it should be an HTTP(S) URL", s)
+				}
+
+				return nil
+			},
+		}
+		proxyInterceptCodesFlag = cli.StringFlag{
+			Name: "proxy-intercept-codes",
+			Usage: "Comma-separated list of the upstream response codes to replace with the error pages (wildcards " +
+				"like 5** are supported)",
+			Value:    strings.Join(cfg.Proxy.InterceptCodes, ","),
+			Sources:  env("PROXY_INTERCEPT_CODES"),
+			Category: shared.CategoryProxy,
+			OnlyOnce: true,
+			Config:   trim,
+		}
+		proxyTimeoutFlag = cli.DurationFlag{
+			Name: "proxy-timeout",
+			Usage: "Upstream request timeout, including the response body reading (504 is returned if the response " +
+				"headers don't arrive in time, a stalled body is cut off)",
+			Value:    cfg.Proxy.Timeout,
+			Sources:  env("PROXY_TIMEOUT"),
+			Category: shared.CategoryProxy,
+			OnlyOnce: true,
+			Validator: func(d time.Duration) error {
+				if d <= 0 {
+					return fmt.Errorf("wrong proxy timeout [%s]: it should be positive", d)
+				}
+
+				return nil
+			},
+		}
+		statusPageSourceFlag = cli.StringFlag{
+			Name: "status-page-source",
+			Usage: "Show the current incident from the status page on the error pages: a local file path or HTTP(S) " +
+				"URL (Statuspage or Cachet API JSON, Atom feed, or {\"title\", \"body\", \"url\"} JSON object)",
+			Sources:  env("STATUS_PAGE_SOURCE"),
+			Category: shared.CategoryStatusPage,
+			OnlyOnce: true,
+			Config:   trim,
+			Validator: func(s string) error {
+				if s == "" || !remote.IsURL(s) {
+					return nil // the local file may appear later
+				}
+
+				if u, err := url.Parse(s); err != nil || u.Host == "" {
+					return fmt.Errorf("wrong status page URL [%s]", s)
+				}
+
+				return nil
+			},
+		}
+		statusPageCodesFlag = cli.StringFlag{
+			Name: "status-page-codes",
+			Usage: "Comma-separated list of the codes to show the status page incident for (wildcards like 5** are " +
+				"supported)",
+			Value:    strings.Join(cfg.StatusPage.Codes, ","),
+			Sources:  env("STATUS_PAGE_CODES"),
+			Category: shared.CategoryStatusPage,
+			OnlyOnce: true,
+			Config:   trim,
+		}
+		statusPageIntervalFlag = cli.DurationFlag{
+			Name:     "status-page-interval",
+			Usage:    "Status page polling interval",
+			Value:    cfg.StatusPage.Interval,
+			Sources:  env("STATUS_PAGE_INTERVAL"),
+			Category: shared.CategoryStatusPage,
+			OnlyOnce: true,
+			Validator: func(d time.Duration) error {
+				if d <= 0 {
+					return fmt.Errorf("wrong status page interval [%s]: it should be positive", d)
+				}
+
+				return nil
+			},
+		}
+		suggestionsSourceFlag = cli.StringFlag{
+			Name: "suggestions-source",
+			Usage: "Show the \"did you mean\" suggestions on the 404 error pages, based on the known site URLs: a local " +
+				"file path or HTTP(S) URL of the sitemap XML or plain URLs list (one per line)",
+			Sources:  env("SUGGESTIONS_SOURCE"),
+			Category: shared.CategorySuggestions,
+			OnlyOnce: true,
+			Config:   trim,
+			Validator: func(s string) error {
+				if s == "" || !remote.IsURL(s) {
+					return nil // the local file may appear later
+				}
+
+				if u, err := url.Parse(s); err != nil || u.Host == "" {
+					return fmt.Errorf("wrong suggestions source URL [%s]", s)
+				}
+
+				return nil
+			},
+		}
+		suggestionsIntervalFlag = cli.DurationFlag{
+			Name:     "suggestions-interval",
+			Usage:    "Known site URLs refreshing interval",
+			Value:    cfg.Suggestions.Interval,
+			Sources:  env("SUGGESTIONS_INTERVAL"),
+			Category: shared.CategorySuggestions,
+			OnlyOnce: true,
+			Validator: func(d time.Duration) error {
+				if d <= 0 {
+					return fmt.Errorf("wrong suggestions interval [%s]: it should be positive", d)
+				}
+
+				return nil
+			},
+		}
+		suggestionsLimitFlag = cli.UintFlag{
+			Name:     "suggestions-limit",
+			Usage:    "Maximal number of the suggestions to show",
+			Value:    cfg.Suggestions.Limit,
+			Sources:  env("SUGGESTIONS_LIMIT"),
+			Category: shared.CategorySuggestions,
+			OnlyOnce: true,
+			Validator: func(n uint) error {
+				if n == 0 {
+					return errors.New("wrong suggestions limit: it should be positive")
+				}
+
+				return nil
+			},
+		}
+		maintenanceStartFlag = cli.StringFlag{
+			Name:      "maintenance-start",
+			Usage:     "Scheduled maintenance window start time in RFC 3339 format (e.g., 2024-01-01T10:00:00Z)",
+			Sources:   env("MAINTENANCE_START"),
+			Category:  shared.CategoryMaintenance,
+			OnlyOnce:  true,
+			Config:    trim,
+			Validator: validateMaintenanceTime,
+		}
+		maintenanceEndFlag = cli.StringFlag{
+			Name: "maintenance-end",
+			Usage: "Scheduled maintenance window end time in RFC 3339 format (used for the Retry-After header and " +
+				"the countdown in templates)",
+			Sources:   env("MAINTENANCE_END"),
+			Category:  shared.CategoryMaintenance,
+			OnlyOnce:  true,
+			Config:    trim,
+			Validator: validateMaintenanceTime,
+		}
+		maintenanceFlagFileFlag = cli.StringFlag{
+			Name: "maintenance-flag-file",
+			Usage: "The maintenance is active while this file exists (it may contain the maintenance end time in RFC " +
+				"3339 format)",
+			Sources:  env("MAINTENANCE_FLAG_FILE"),
+			Category: shared.CategoryMaintenance,
+			OnlyOnce: true,
+			Config:   trim,
+		}
+		maintenanceTemplateFlag = cli.StringFlag{
+			Name:     "maintenance-template",
+			Usage:    "Name of the template to use during the maintenance (the built-in maintenance page is used if not set)",
+			Sources:  env("MAINTENANCE_TEMPLATE"),
+			Category: shared.CategoryMaintenance,
+			OnlyOnce: true,
+			Config:   trim,
+		}
+		adminTokenFlag = cli.StringFlag{
+			Name: "admin-token",
+			Usage: "Bearer token for the administrative endpoints (e.g., /_admin/log-level to change the logging levels " +
+				"at runtime; the endpoints are disabled if not set)",
+			Sources:  env("ADMIN_TOKEN"),
+			Category: shared.CategoryOther,
+			OnlyOnce: true,
+			Config:   trim,
+		}
+		otlpEndpointFlag = cli.StringFlag{
+			Name: "otlp-endpoint",
+			Usage: "OpenTelemetry collector base URL to export the traces to using OTLP/HTTP (e.g., " +
+				"http://localhost:4318; tracing is disabled if not set)",
+			Sources:  env("OTEL_EXPORTER_OTLP_ENDPOINT"),
+			Category: shared.CategoryTracing,
+			OnlyOnce: true,
+			Config:   trim,
+			Validator: func(s string) error {
+				if s == "" {
+					return nil
+				}
+
+				if u, err := url.Parse(s); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					return fmt.Errorf("wrong OTLP endpoint [%s]: it should be an HTTP(S) URL", s)
+				}
+
+				return nil
+			},
+		}
+		otlpServiceNameFlag = cli.StringFlag{
+			Name:     "otlp-service-name",
+			Usage:    "Service name reported with the exported traces",
+			Value:    cfg.Tracing.ServiceName,
+			Sources:  env("OTEL_SERVICE_NAME"),
+			Category: shared.CategoryTracing,
+			OnlyOnce: true,
+			Config:   trim,
+		}
 		rotationModeFlag = cli.StringFlag{
 			Name:     "rotation-mode",
 			Value:    config.RotationModeDisabled.String(),
@@ -149,6 +610,15 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit,gocy
 				return nil
 			},
 		}
+		fastCGIFlag = cli.BoolFlag{
+			Name: "fastcgi",
+			Usage: "Serve the FastCGI protocol instead of HTTP on the same address and port (for the web servers that " +
+				"cannot proxy to an HTTP backend, e.g., Apache or lighttpd; the REDIRECT_STATUS and REQUEST_URI CGI " +
+				"variables of the error documents are used as the error code and original URI)",
+			Sources:  env("FASTCGI"),
+			Category: shared.CategoryHTTP,
+			OnlyOnce: true,
+		}
 		readBufferSizeFlag = cli.UintFlag{
 			Name: "read-buffer-size",
 			Usage: "Per-connection buffer size in bytes for reading requests, this also limits the maximum header size " +
@@ -177,12 +647,27 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit,gocy
 			cmd.opt.http.addr = c.String(addrFlag.Name)
 			cmd.opt.http.port = uint16(c.Uint(portFlag.Name)) //nolint:gosec
 			cmd.opt.http.readBufferSize = c.Uint(readBufferSizeFlag.Name)
+			cmd.opt.http.fastCGI = c.Bool(fastCGIFlag.Name)
 			cfg.L10n.Disable = c.Bool(disableL10nFlag.Name)
 			cfg.DefaultCodeToRender = uint16(c.Uint(defaultCodeToRenderFlag.Name)) //nolint:gosec
 			cfg.RespondWithSameHTTPCode = c.Bool(sendSameHTTPCodeFlag.Name)
 			cfg.RotationMode, _ = config.ParseRotationMode(c.String(rotationModeFlag.Name))
 			cfg.ShowDetails = c.Bool(showDetailsFlag.Name)
+			cfg.DetailsMapping, _ = config.ParseDetailsMapping(c.String(detailsMappingFlag.Name))
+
+			// set the additional details (the order matters, the names are unique)
+			for _, raw := range splitList(c.String(detailHeadersFlag.Name)) {
+				if detail, err := config.ParseDetailHeader(raw); err == nil && !slices.ContainsFunc(cfg.DetailHeaders,
+					func(d config.DetailHeader) bool { return d.Name == detail.Name },
+				) {
+					cfg.DetailHeaders = append(cfg.DetailHeaders, detail)
+				}
+			}
+
 			cfg.DisableMinification = c.Bool(disableMinificationFlag.Name)
+			cfg.BasePath = shared.ParseBasePath(c.String(basePathFlag.Name))
+			cfg.AssetsDir = c.String(assetsDirFlag.Name)
+			cfg.NamespacesDir = c.String(namespacesDirFlag.Name)
 
 			{ // override default JSON, XML, and PlainText formats
 				if c.IsSet(jsonFormatFlag.Name) {
@@ -227,6 +712,70 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit,gocy
 				}
 			}
 
+			// set the list of places to look for the error code in (the order matters)
+			if c.IsSet(codeSourcesFlag.Name) {
+				cfg.CodeSources = cfg.CodeSources[:0] // clear the list before adding new sources
+
+				for _, raw := range splitList(c.String(codeSourcesFlag.Name)) {
+					if source, err := config.ParseCodeSource(raw); err == nil && !slices.Contains(cfg.CodeSources, source) {
+						cfg.CodeSources = append(cfg.CodeSources, source)
+					}
+				}
+			}
+
+			// set the CORS settings
+			cfg.CORS.AllowedOrigins = splitList(c.String(corsAllowedOriginsFlag.Name))
+			cfg.CORS.AllowedMethods = splitList(strings.ToUpper(c.String(corsAllowedMethodsFlag.Name)))
+			cfg.CORS.AllowedHeaders = splitList(c.String(corsAllowedHeadersFlag.Name))
+			cfg.CORS.MaxAge = c.Duration(corsMaxAgeFlag.Name)
+
+			// set the access log settings
+			cfg.AccessLog.Format, _ = config.ParseAccessLogFormat(c.String(accessLogFormatFlag.Name))
+			cfg.AccessLog.Template = c.String(accessLogTemplateFlag.Name)
+			cfg.AccessLog.FieldNames = c.StringMap(accessLogFieldNamesFlag.Name)
+			cfg.AccessLog.RequestHeaders = canonicalHeaders(splitList(c.String(accessLogRequestHeadersFlag.Name)))
+			cfg.AccessLog.ResponseHeaders = canonicalHeaders(splitList(c.String(accessLogResponseHeadersFlag.Name)))
+
+			cfg.AccessLog.Sampling.First = uint64(c.Uint(accessLogSamplingFirstFlag.Name))
+			cfg.AccessLog.Sampling.Thereafter = uint64(c.Uint(accessLogSamplingThereafterFlag.Name))
+			cfg.AccessLog.Sampling.SummaryInterval = c.Duration(accessLogSamplingSummaryFlag.Name)
+
+			if cfg.AccessLog.Format == config.AccessLogFormatTemplate && strings.TrimSpace(cfg.AccessLog.Template) == "" {
+				return errors.New("the access log template is required for the 'template' access log format")
+			}
+
+			// set the list of HTTP headers with sensitive values
+			if c.IsSet(redactHeadersFlag.Name) {
+				cfg.Redaction.Headers = canonicalHeaders(splitList(c.String(redactHeadersFlag.Name)))
+			}
+
+			cfg.Redaction.KeepChars = c.Uint(redactKeepCharsFlag.Name)
+
+			cfg.AdminToken = c.String(adminTokenFlag.Name)
+
+			// set the reverse proxy mode settings
+			cfg.Proxy.Upstream = c.String(proxyUpstreamFlag.Name)
+			cfg.Proxy.InterceptCodes = splitList(c.String(proxyInterceptCodesFlag.Name))
+			cfg.Proxy.Timeout = c.Duration(proxyTimeoutFlag.Name)
+			cfg.StatusPage.Source = c.String(statusPageSourceFlag.Name)
+			cfg.StatusPage.Codes = splitList(c.String(statusPageCodesFlag.Name))
+			cfg.StatusPage.Interval = c.Duration(statusPageIntervalFlag.Name)
+			cfg.Suggestions.Source = c.String(suggestionsSourceFlag.Name)
+			cfg.Suggestions.Interval = c.Duration(suggestionsIntervalFlag.Name)
+			cfg.Suggestions.Limit = c.Uint(suggestionsLimitFlag.Name)
+			cfg.Maintenance.Start, _ = parseMaintenanceTime(c.String(maintenanceStartFlag.Name))
+			cfg.Maintenance.End, _ = parseMaintenanceTime(c.String(maintenanceEndFlag.Name))
+			cfg.Maintenance.FlagFile = c.String(maintenanceFlagFileFlag.Name)
+			cfg.Maintenance.Template = c.String(maintenanceTemplateFlag.Name)
+
+			if start, end := cfg.Maintenance.Start, cfg.Maintenance.End; !start.IsZero() && !end.IsZero() && !end.After(start) {
+				return fmt.Errorf("the maintenance end (%s) should be after its start (%s)", end, start)
+			}
+
+			// set the tracing settings
+			cfg.Tracing.Endpoint = c.String(otlpEndpointFlag.Name)
+			cfg.Tracing.ServiceName = c.String(otlpServiceNameFlag.Name)
+
 			// add custom HTTP codes to the configuration
 			if add := c.StringMap(addCodeFlag.Name); len(add) > 0 {
 				for code, desc := range shared.ParseHTTPCodes(add) {
@@ -279,10 +828,42 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit,gocy
 				logger.String("template name", cfg.TemplateName),
 				logger.Bool("disable localization", cfg.L10n.Disable),
 				logger.Uint16("default code to render", cfg.DefaultCodeToRender),
+				logger.String("code sources", codeSourcesToString(cfg.CodeSources)),
 				logger.Bool("respond with the same HTTP code", cfg.RespondWithSameHTTPCode),
 				logger.String("rotation mode", cfg.RotationMode.String()),
 				logger.Bool("show details", cfg.ShowDetails),
+				logger.String("details mapping", cfg.DetailsMapping.String()),
+				logger.String("detail headers", detailHeadersToString(cfg.DetailHeaders)),
 				logger.Strings("proxy HTTP headers", cfg.ProxyHeaders...),
+				logger.String("base path", cfg.BasePath),
+				logger.String("assets directory", cfg.AssetsDir),
+				logger.String("namespaces directory", cfg.NamespacesDir),
+				logger.Strings("CORS allowed origins", cfg.CORS.AllowedOrigins...),
+				logger.Strings("CORS allowed methods", cfg.CORS.AllowedMethods...),
+				logger.Strings("CORS allowed headers", cfg.CORS.AllowedHeaders...),
+				logger.Duration("CORS max age", cfg.CORS.MaxAge),
+				logger.String("access log format", cfg.AccessLog.Format.String()),
+				logger.Uint64("access log sampling (first)", cfg.AccessLog.Sampling.First),
+				logger.Uint64("access log sampling (thereafter)", cfg.AccessLog.Sampling.Thereafter),
+				logger.Strings("redacted HTTP headers", cfg.Redaction.Headers...),
+				logger.Strings("access log request headers", cfg.AccessLog.RequestHeaders...),
+				logger.Strings("access log response headers", cfg.AccessLog.ResponseHeaders...),
+				logger.Bool("admin endpoints enabled", cfg.AdminToken != ""),
+				logger.String("proxy upstream", cfg.Proxy.Upstream),
+				logger.Strings("proxy intercept codes", cfg.Proxy.InterceptCodes...),
+				logger.Duration("proxy timeout", cfg.Proxy.Timeout),
+				logger.String("status page source", cfg.StatusPage.Source),
+				logger.Strings("status page codes", cfg.StatusPage.Codes...),
+				logger.Duration("status page interval", cfg.StatusPage.Interval),
+				logger.String("suggestions source", cfg.Suggestions.Source),
+				logger.Duration("suggestions interval", cfg.Suggestions.Interval),
+				logger.Uint64("suggestions limit", uint64(cfg.Suggestions.Limit)),
+				logger.Time("maintenance start", cfg.Maintenance.Start),
+				logger.Time("maintenance end", cfg.Maintenance.End),
+				logger.String("maintenance flag file", cfg.Maintenance.FlagFile),
+				logger.String("maintenance template", cfg.Maintenance.Template),
+				logger.String("OTLP endpoint", cfg.Tracing.Endpoint),
+				logger.String("OTLP service name", cfg.Tracing.ServiceName),
 			)
 
 			return cmd.Run(ctx, log, &cfg)
@@ -290,6 +871,7 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit,gocy
 		Flags: []cli.Flag{
 			&addrFlag,
 			&portFlag,
+			&basePathFlag,
 			&addTplFlag,
 			&disableTplFlag,
 			&addCodeFlag,
@@ -297,12 +879,48 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit,gocy
 			&xmlFormatFlag,
 			&plainTextFormatFlag,
 			&templateNameFlag,
+			&assetsDirFlag,
+			&namespacesDirFlag,
 			&disableL10nFlag,
 			&defaultCodeToRenderFlag,
+			&codeSourcesFlag,
 			&sendSameHTTPCodeFlag,
 			&showDetailsFlag,
+			&detailHeadersFlag,
+			&detailsMappingFlag,
 			&proxyHeadersListFlag,
+			&redactHeadersFlag,
+			&redactKeepCharsFlag,
 			&rotationModeFlag,
+			&corsAllowedOriginsFlag,
+			&corsAllowedMethodsFlag,
+			&corsAllowedHeadersFlag,
+			&corsMaxAgeFlag,
+			&accessLogFormatFlag,
+			&accessLogTemplateFlag,
+			&accessLogFieldNamesFlag,
+			&accessLogRequestHeadersFlag,
+			&accessLogResponseHeadersFlag,
+			&accessLogSamplingFirstFlag,
+			&accessLogSamplingThereafterFlag,
+			&accessLogSamplingSummaryFlag,
+			&proxyUpstreamFlag,
+			&proxyInterceptCodesFlag,
+			&proxyTimeoutFlag,
+			&statusPageSourceFlag,
+			&statusPageCodesFlag,
+			&statusPageIntervalFlag,
+			&suggestionsSourceFlag,
+			&suggestionsIntervalFlag,
+			&suggestionsLimitFlag,
+			&maintenanceStartFlag,
+			&maintenanceEndFlag,
+			&maintenanceFlagFileFlag,
+			&maintenanceTemplateFlag,
+			&adminTokenFlag,
+			&otlpEndpointFlag,
+			&otlpServiceNameFlag,
+			&fastCGIFlag,
 			&readBufferSizeFlag,
 			&disableMinificationFlag,
 		},
@@ -311,6 +929,68 @@ func NewCommand(log *logger.Logger) *cli.Command { //nolint:funlen,gocognit,gocy
 	return cmd.c
 }
 
+// splitList splits the comma-separated list into a slice of trimmed non-empty strings.
+func splitList(s string) []string {
+	var result = make([]string, 0, strings.Count(s, ",")+1)
+
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// parseMaintenanceTime parses the maintenance window bound (an empty string means unbounded).
+func parseMaintenanceTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
+
+// validateMaintenanceTime validates the maintenance window bound flag value.
+func validateMaintenanceTime(s string) error {
+	if _, err := parseMaintenanceTime(s); err != nil {
+		return fmt.Errorf("wrong maintenance time [%s]: it should be in RFC 3339 format (e.g., 2024-01-01T10:00:00Z)", s)
+	}
+
+	return nil
+}
+
+// canonicalHeaders converts the HTTP header names into the canonical format.
+func canonicalHeaders(headers []string) []string {
+	for i := range headers {
+		headers[i] = http.CanonicalHeaderKey(headers[i])
+	}
+
+	return headers
+}
+
+// codeSourcesToString converts the list of code sources into a comma-separated string.
+func codeSourcesToString(sources []config.CodeSource) string {
+	var parts = make([]string, len(sources))
+
+	for i, source := range sources {
+		parts[i] = source.String()
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// detailHeadersToString converts the list of detail headers into a comma-separated string.
+func detailHeadersToString(details []config.DetailHeader) string {
+	var parts = make([]string, len(details))
+
+	for i, detail := range details {
+		parts[i] = detail.String()
+	}
+
+	return strings.Join(parts, ",")
+}
+
 // Run current command.
 func (cmd *command) Run(ctx context.Context, log *logger.Logger, cfg *config.Config) error {
 	var srv = appHttp.NewServer(log, cmd.opt.http.readBufferSize)
@@ -333,9 +1013,16 @@ func (cmd *command) Run(ctx context.Context, log *logger.Logger, cfg *config.Con
 		log.Info("HTTP server starting",
 			logger.String("addr", cmd.opt.http.addr),
 			logger.Uint16("port", cmd.opt.http.port),
+			logger.Bool("FastCGI", cmd.opt.http.fastCGI),
 		)
 
-		if err := srv.Start(cmd.opt.http.addr, cmd.opt.http.port); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		var start = srv.Start
+
+		if cmd.opt.http.fastCGI {
+			start = srv.StartFastCGI
+		}
+
+		if err := start(cmd.opt.http.addr, cmd.opt.http.port); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			errCh <- err
 		}
 	}(startingErrCh)
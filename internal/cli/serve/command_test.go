@@ -41,12 +41,30 @@ func TestCommand_Run(t *testing.T) {
 			"--xml-format", "xml format",
 			"--plaintext-format", "plaintext format",
 			"--template-name", "foo-template",
+			"--assets-dir", "./testdata",
 			"--disable-l10n",
 			"--default-error-page", "503",
+			"--code-sources", "query:status, header:X-Status-Code,X-Code,", // the empty items are skipped
 			"--send-same-http-code",
 			"--show-details",
 			"--proxy-headers", "X-Forwarded-For,X-Forwarded-Proto",
 			"--rotation-mode", "random-on-each-request",
+			"--redact-headers", "Authorization,cookie",
+			"--redact-keep-chars", "2",
+			"--cors-allowed-origins", "https://example.com,https://foo.example.com",
+			"--cors-allowed-methods", "get,head",
+			"--cors-allowed-headers", "Accept,X-Format",
+			"--cors-max-age", "1h",
+			"--access-log-format", "json",
+			"--access-log-field-name", "status=http_status",
+			"--access-log-request-headers", "x-request-id,X-Forwarded-For",
+			"--access-log-response-headers", "Content-Length",
+			"--access-log-sampling-first", "10",
+			"--access-log-sampling-thereafter", "100",
+			"--access-log-sampling-summary-interval", "30s",
+			"--otlp-endpoint", "http://127.0.0.1:4318",
+			"--otlp-service-name", "error-pages-test",
+			"--admin-token", "secret",
 		})
 	}()
 
@@ -12,12 +12,19 @@ import (
 )
 
 const (
-	CategoryHTTP      = "HTTP:"
-	CategoryTemplates = "TEMPLATES:"
-	CategoryCodes     = "HTTP CODES:"
-	CategoryFormats   = "FORMATS:"
-	CategoryBuild     = "BUILD:"
-	CategoryOther     = "OTHER:"
+	CategoryHTTP        = "HTTP:"
+	CategoryTemplates   = "TEMPLATES:"
+	CategoryCodes       = "HTTP CODES:"
+	CategoryFormats     = "FORMATS:"
+	CategoryCORS        = "CORS:"
+	CategoryAccessLog   = "ACCESS LOG:"
+	CategoryTracing     = "TRACING:"
+	CategoryProxy       = "PROXY:"
+	CategoryStatusPage  = "STATUS PAGE:"
+	CategorySuggestions = "SUGGESTIONS:"
+	CategoryMaintenance = "MAINTENANCE:"
+	CategoryBuild       = "BUILD:"
+	CategoryOther       = "OTHER:"
 )
 
 // Note: Don't use pointers for flags, because they have own state which is not thread-safe.
@@ -62,6 +69,34 @@ var ListenPortFlag = cli.UintFlag{
 	},
 }
 
+var BasePathFlag = cli.StringFlag{
+	Name: "base-path",
+	Usage: "URL path prefix under which all the routes (error pages, health, version, favicon) are served (e.g., " +
+		"'/_errors'; useful when the service is mounted behind a shared gateway)",
+	Sources:  cli.EnvVars("BASE_PATH"),
+	Category: CategoryHTTP,
+	OnlyOnce: true,
+	Config:   cli.StringConfig{TrimSpace: true},
+	Validator: func(path string) error {
+		if strings.ContainsAny(path, " ?#") {
+			return fmt.Errorf("wrong base path [%s]: whitespaces, '?' and '#' are not allowed", path)
+		}
+
+		return nil
+	},
+}
+
+// ParseBasePath normalizes the base path value - adds a leading slash and removes the trailing ones (so "_errors/"
+// becomes "/_errors"). An empty string or a single slash means the root, and an empty string is returned. Should be
+// used together with [BasePathFlag].
+func ParseBasePath(path string) string {
+	if path = strings.Trim(strings.TrimSpace(path), "/"); path == "" {
+		return ""
+	}
+
+	return "/" + path
+}
+
 var AddTemplatesFlag = cli.StringSliceFlag{
 	Name: "add-template",
 	Usage: "To add a new template, provide the path to the file using this flag (the filename without the extension " +
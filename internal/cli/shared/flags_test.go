@@ -85,6 +85,53 @@ func TestListenPortFlag(t *testing.T) {
 	}
 }
 
+func TestBasePathFlag(t *testing.T) {
+	t.Parallel()
+
+	var flag = shared.BasePathFlag
+
+	assert.Equal(t, "base-path", flag.Name)
+	assert.Contains(t, flag.Sources.String(), "BASE_PATH")
+
+	for giveValue, wantErrMsg := range map[string]string{
+		"":              "",
+		"/":             "",
+		"/_errors":      "",
+		"_errors/":      "",
+		"/foo/bar":      "",
+		"/foo bar":      "wrong base path [/foo bar]",
+		"/foo?bar=baz":  "wrong base path [/foo?bar=baz]",
+		"/foo#fragment": "wrong base path [/foo#fragment]",
+	} {
+		t.Run(fmt.Sprintf("%s: %s", giveValue, wantErrMsg), func(t *testing.T) {
+			if err := flag.Validator(giveValue); wantErrMsg != "" {
+				assert.ErrorContains(t, err, wantErrMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseBasePath(t *testing.T) {
+	t.Parallel()
+
+	for giveValue, wantResult := range map[string]string{
+		"":            "",
+		"/":           "",
+		"///":         "",
+		"_errors":     "/_errors",
+		"/_errors":    "/_errors",
+		"/_errors/":   "/_errors",
+		" /foo/bar/ ": "/foo/bar",
+		"//foo/bar//": "/foo/bar",
+	} {
+		t.Run(giveValue, func(t *testing.T) {
+			assert.Equal(t, wantResult, shared.ParseBasePath(giveValue))
+		})
+	}
+}
+
 func TestAddTemplatesFlag(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+// reopenLogFileSignals are the signals that make the application reopen the log file (for the external logrotate).
+var reopenLogFileSignals = []os.Signal{syscall.SIGUSR1} //nolint:gochecknoglobals
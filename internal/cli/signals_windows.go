@@ -0,0 +1,9 @@
+//go:build windows
+
+package cli
+
+import "os"
+
+// reopenLogFileSignals are the signals that make the application reopen the log file (there is no SIGUSR1 on
+// Windows, so the log file is never reopened).
+var reopenLogFileSignals []os.Signal //nolint:gochecknoglobals
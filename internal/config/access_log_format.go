@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AccessLogFormat represents the format of the HTTP access log.
+type AccessLogFormat byte
+
+const (
+	AccessLogFormatDefault  AccessLogFormat = iota // log using the application logger (and its format), default
+	AccessLogFormatCommon                          // Apache Common Log Format
+	AccessLogFormatCombined                        // Apache Combined Log Format
+	AccessLogFormatJSON                            // JSON with configurable field names
+	AccessLogFormatTemplate                        // custom line, rendered using the Go template
+)
+
+// String returns a human-readable representation of the access log format.
+func (f AccessLogFormat) String() string {
+	switch f {
+	case AccessLogFormatDefault:
+		return "default"
+	case AccessLogFormatCommon:
+		return "common"
+	case AccessLogFormatCombined:
+		return "combined"
+	case AccessLogFormatJSON:
+		return "json"
+	case AccessLogFormatTemplate:
+		return "template"
+	}
+
+	return fmt.Sprintf("AccessLogFormat(%d)", f)
+}
+
+// AccessLogFormats returns a slice of all access log formats.
+func AccessLogFormats() []AccessLogFormat {
+	return []AccessLogFormat{
+		AccessLogFormatDefault,
+		AccessLogFormatCommon,
+		AccessLogFormatCombined,
+		AccessLogFormatJSON,
+		AccessLogFormatTemplate,
+	}
+}
+
+// AccessLogFormatStrings returns a slice of all access log formats as strings.
+func AccessLogFormatStrings() []string {
+	var (
+		formats = AccessLogFormats()
+		result  = make([]string, len(formats))
+	)
+
+	for i := range formats {
+		result[i] = formats[i].String()
+	}
+
+	return result
+}
+
+// ParseAccessLogFormat parses an access log format (case is ignored) based on the ASCII representation of the
+// format. If the provided ASCII representation is invalid an error is returned.
+func ParseAccessLogFormat[T string | []byte](text T) (AccessLogFormat, error) {
+	var format string
+
+	if s, ok := any(text).(string); ok {
+		format = s
+	} else {
+		format = string(any(text).([]byte))
+	}
+
+	switch strings.ToLower(format) {
+	case AccessLogFormatDefault.String(), "":
+		return AccessLogFormatDefault, nil // the empty string makes sense
+	case AccessLogFormatCommon.String():
+		return AccessLogFormatCommon, nil
+	case AccessLogFormatCombined.String():
+		return AccessLogFormatCombined, nil
+	case AccessLogFormatJSON.String():
+		return AccessLogFormatJSON, nil
+	case AccessLogFormatTemplate.String():
+		return AccessLogFormatTemplate, nil
+	}
+
+	return AccessLogFormatDefault, fmt.Errorf("unrecognized access log format: %q", format)
+}
@@ -0,0 +1,68 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gh.tarampamp.am/error-pages/internal/config"
+)
+
+func TestAccessLogFormat_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "default", config.AccessLogFormatDefault.String())
+	assert.Equal(t, "common", config.AccessLogFormatCommon.String())
+	assert.Equal(t, "combined", config.AccessLogFormatCombined.String())
+	assert.Equal(t, "json", config.AccessLogFormatJSON.String())
+	assert.Equal(t, "template", config.AccessLogFormatTemplate.String())
+
+	assert.Equal(t, "AccessLogFormat(255)", config.AccessLogFormat(255).String())
+}
+
+func TestAccessLogFormatStrings(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"default", "common", "combined", "json", "template"}, config.AccessLogFormatStrings())
+}
+
+func TestParseAccessLogFormat(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		giveBytes    []byte
+		giveString   string
+		wantFormat   config.AccessLogFormat
+		wantErrorMsg string
+	}{
+		"<empty string>":   {giveString: "", wantFormat: config.AccessLogFormatDefault},
+		"<empty bytes>":    {giveBytes: []byte(""), wantFormat: config.AccessLogFormatDefault},
+		"default":          {giveString: "default", wantFormat: config.AccessLogFormatDefault},
+		"common":           {giveString: "common", wantFormat: config.AccessLogFormatCommon},
+		"combined (bytes)": {giveBytes: []byte("combined"), wantFormat: config.AccessLogFormatCombined},
+		"JSON":             {giveString: "JSON", wantFormat: config.AccessLogFormatJSON},
+		"template":         {giveString: "template", wantFormat: config.AccessLogFormatTemplate},
+
+		"foobar": {giveString: "foobar", wantErrorMsg: "unrecognized access log format: \"foobar\""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var (
+				format config.AccessLogFormat
+				err    error
+			)
+
+			if tt.giveString != "" || tt.giveBytes == nil {
+				format, err = config.ParseAccessLogFormat(tt.giveString)
+			} else {
+				format, err = config.ParseAccessLogFormat(tt.giveBytes)
+			}
+
+			if tt.wantErrorMsg == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantFormat, format)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErrorMsg)
+			}
+		})
+	}
+}
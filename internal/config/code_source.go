@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type (
+	// CodeSourceKind represents the kind of the place where the error code can be found in the incoming request.
+	CodeSourceKind byte
+
+	// CodeSource describes a single place where the error code can be found in the incoming request (e.g., the
+	// `X-Code` HTTP header or the `status` query parameter).
+	CodeSource struct {
+		Kind CodeSourceKind
+		Name string // the HTTP header name (canonical) or the query parameter name
+	}
+)
+
+const (
+	CodeSourceHeader CodeSourceKind = iota // the HTTP header, default
+	CodeSourceQuery                        // the URL query parameter
+)
+
+// String returns a human-readable representation of the code source kind.
+func (k CodeSourceKind) String() string {
+	switch k {
+	case CodeSourceHeader:
+		return "header"
+	case CodeSourceQuery:
+		return "query"
+	}
+
+	return fmt.Sprintf("CodeSourceKind(%d)", k)
+}
+
+// String returns the code source in the "kind:name" format (e.g., "header:X-Code" or "query:status").
+func (s CodeSource) String() string { return s.Kind.String() + ":" + s.Name }
+
+// ParseCodeSource parses a code source in the "kind:name" format (kind is case-insensitive). The kind may be omitted,
+// in this case the HTTP header is assumed (e.g., "X-Code" is the same as "header:X-Code").
+func ParseCodeSource(text string) (CodeSource, error) {
+	var kind, name, withKind = strings.Cut(strings.TrimSpace(text), ":")
+
+	if !withKind {
+		kind, name = CodeSourceHeader.String(), kind
+	}
+
+	if name = strings.TrimSpace(name); name == "" {
+		return CodeSource{}, fmt.Errorf("missing name in the code source: %q", text)
+	} else if strings.ContainsAny(name, " \t") {
+		return CodeSource{}, fmt.Errorf("whitespaces are not allowed in the code source: %q", text)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case CodeSourceHeader.String():
+		return CodeSource{Kind: CodeSourceHeader, Name: http.CanonicalHeaderKey(name)}, nil
+	case CodeSourceQuery.String():
+		return CodeSource{Kind: CodeSourceQuery, Name: name}, nil
+	}
+
+	return CodeSource{}, fmt.Errorf("unrecognized code source kind: %q", kind)
+}
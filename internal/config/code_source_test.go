@@ -0,0 +1,56 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gh.tarampamp.am/error-pages/internal/config"
+)
+
+func TestCodeSource_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "header:X-Code", config.CodeSource{Kind: config.CodeSourceHeader, Name: "X-Code"}.String())
+	assert.Equal(t, "query:status", config.CodeSource{Kind: config.CodeSourceQuery, Name: "status"}.String())
+
+	assert.Equal(t, "CodeSourceKind(255)", config.CodeSourceKind(255).String())
+}
+
+func TestParseCodeSource(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		giveText   string
+		wantSource config.CodeSource
+		wantErrMsg string
+	}{
+		"header":              {giveText: "header:X-Code", wantSource: config.CodeSource{Name: "X-Code"}},
+		"header (canonical)":  {giveText: "HEADER:x-status-code", wantSource: config.CodeSource{Name: "X-Status-Code"}},
+		"header (no kind)":    {giveText: "x-code", wantSource: config.CodeSource{Name: "X-Code"}},
+		"header (whitespace)": {giveText: " header : X-Code ", wantSource: config.CodeSource{Name: "X-Code"}},
+		"query": {
+			giveText:   "query:Status",
+			wantSource: config.CodeSource{Kind: config.CodeSourceQuery, Name: "Status"},
+		},
+
+		"empty":              {giveText: "", wantErrMsg: "missing name in the code source"},
+		"missing name":       {giveText: "query:", wantErrMsg: "missing name in the code source"},
+		"whitespace in name": {giveText: "query:foo bar", wantErrMsg: "whitespaces are not allowed"},
+		"unknown kind":       {giveText: "cookie:code", wantErrMsg: `unrecognized code source kind: "cookie"`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var source, err = config.ParseCodeSource(tt.giveText)
+
+			if tt.wantErrMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrMsg)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSource, source)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"maps"
 	"net/http"
 	"slices"
+	"time"
 
 	builtinTemplates "gh.tarampamp.am/error-pages/templates"
 )
@@ -38,6 +39,11 @@ type Config struct {
 		Disable bool
 	}
 
+	// CodeSources is a list of places (HTTP headers and URL query parameters) where the error code can be found in
+	// the incoming request, in priority order. The code in the URL path (e.g., `/404.html`) always has the highest
+	// priority.
+	CodeSources []CodeSource
+
 	// DefaultCodeToRender is the code for the default error page to be displayed. It is used when the requested
 	// code is not defined in the incoming request (i.e., the code to render as the index page).
 	DefaultCodeToRender uint16
@@ -57,15 +63,172 @@ type Config struct {
 	// incoming request (if supported by the template).
 	ShowDetails bool
 
+	// DetailHeaders is a list of the additional error page details taken from the incoming request HTTP headers
+	// (e.g., "cluster" from the `X-Cluster-Name` header), in the order they are shown.
+	DetailHeaders []DetailHeader
+
+	// DetailsMapping determines which HTTP headers (depending on the proxy in front of the server) are used to fill
+	// in the error page details.
+	DetailsMapping DetailsMapping
+
 	// DisableMinification determines whether to disable minification of the rendered content (e.g., HTML, CSS) or not.
 	DisableMinification bool
+
+	// AssetsDir is the path to the directory with static assets (images, fonts, styles, etc.) for templates. The
+	// assets are served under the reserved URL path prefix, and an empty string means the assets are not served.
+	AssetsDir string
+
+	// NamespacesDir is the path to the directory with per-namespace overrides (template, codes descriptions, support
+	// link), selected by the `X-Namespace` request header. An empty string means the overrides are disabled.
+	NamespacesDir string
+
+	// CORS contains Cross-Origin Resource Sharing settings (useful when the error pages in JSON/XML format are
+	// fetched by the browser from a different origin).
+	CORS struct {
+		// AllowedOrigins is a list of origins allowed to make cross-origin requests ("*" allows any origin). An
+		// empty list disables CORS.
+		AllowedOrigins []string
+
+		// AllowedMethods is a list of HTTP methods allowed for cross-origin requests.
+		AllowedMethods []string
+
+		// AllowedHeaders is a list of HTTP headers allowed for cross-origin requests. If empty, the headers
+		// requested in the preflight request are allowed.
+		AllowedHeaders []string
+
+		// MaxAge is how long the preflight request results can be cached by the client (zero means not set).
+		MaxAge time.Duration
+	}
+
+	// AccessLog contains the HTTP access log settings (independent of the application log format).
+	AccessLog struct {
+		// Format is the access log format.
+		Format AccessLogFormat
+
+		// Template is the Go template for the access log line (used with [AccessLogFormatTemplate] only).
+		Template string
+
+		// FieldNames allows renaming the JSON fields (used with [AccessLogFormatJSON] only), where the key is
+		// the default field name and the value is the custom one (e.g., "status" -> "http_status").
+		FieldNames map[string]string
+
+		// RequestHeaders and ResponseHeaders contain the names of HTTP headers that become the access log fields.
+		RequestHeaders, ResponseHeaders []string
+
+		// Sampling allows limiting the number of access log entries (e.g., during an upstream outage, when every
+		// request becomes an error page).
+		Sampling struct {
+			// First is the number of entries logged per second for every status code (zero disables sampling).
+			First uint64
+
+			// Thereafter means only every Nth entry is logged after the first ones (zero means none).
+			Thereafter uint64
+
+			// SummaryInterval is the interval for logging the number of dropped entries per status code.
+			SummaryInterval time.Duration
+		}
+	}
+
+	// Redaction contains settings for masking sensitive HTTP header values in the logs and the error page details.
+	Redaction Redaction
+
+	// BasePath is the URL path prefix under which all the routes are served (e.g., "/_errors"). It should start
+	// with a slash and must not end with one. An empty string means the routes are served from the root.
+	BasePath string
+
+	// Proxy contains the reverse proxy mode settings. In this mode, the requests are forwarded to the upstream,
+	// and its error responses are replaced with the error pages.
+	Proxy struct {
+		// Upstream is the upstream base URL (e.g., "http://127.0.0.1:8080"). An empty string disables the mode.
+		Upstream string
+
+		// InterceptCodes is a list of the upstream response codes to replace with the error pages. The codes may
+		// be written in a non-strict manner (e.g., "5xx" or "4**", the same as for the [Codes]).
+		InterceptCodes []string
+
+		// Timeout limits the upstream request duration (including the response body reading).
+		Timeout time.Duration
+	}
+
+	// StatusPage contains the status page integration settings. The current incident (if any) is shown on the
+	// error pages with the configured codes.
+	StatusPage struct {
+		// Source is the local file path or HTTP(S) URL of the status page (e.g., the Statuspage or Cachet API, or
+		// an Atom feed). An empty string disables the integration.
+		Source string
+
+		// Codes is a list of the codes to show the incident for (wildcards like "5xx" are supported, the same as
+		// for the [Codes]).
+		Codes []string
+
+		// Interval is the status page polling interval.
+		Interval time.Duration
+	}
+
+	// Maintenance contains the maintenance mode settings. While the maintenance is active, every error page is
+	// rendered with the 503 code, and the `Retry-After` header is set to its expected end. The maintenance can also
+	// be enabled at runtime using the admin HTTP endpoint (see [Config.AdminToken]).
+	Maintenance struct {
+		// Start and End are the scheduled maintenance window bounds (zero values mean unbounded; the window is not
+		// scheduled if both are zero).
+		Start, End time.Time
+
+		// FlagFile is the path to the file, which enables the maintenance while it exists (it may contain the
+		// maintenance end time in RFC 3339 format). An empty string disables this feature.
+		FlagFile string
+
+		// Template is the name of the template to use during the maintenance (empty means the built-in maintenance
+		// page).
+		Template string
+	}
+
+	// Suggestions contains the "did you mean" suggestions settings. The known site URLs closest to the missing one
+	// (taken from the original URI) are shown on the 404 error pages.
+	Suggestions struct {
+		// Source is the local file path or HTTP(S) URL of the sitemap XML or plain URLs list (one per line). An
+		// empty string disables the suggestions.
+		Source string
+
+		// Interval is the URLs list refreshing interval.
+		Interval time.Duration
+
+		// Limit is the maximal number of the suggestions.
+		Limit uint
+	}
+
+	// AdminToken is the bearer token protecting the administrative HTTP endpoints (e.g., for changing the logging
+	// levels at runtime). An empty string disables these endpoints.
+	AdminToken string
+
+	// Tracing contains the OpenTelemetry tracing settings.
+	Tracing struct {
+		// Endpoint is the OTLP/HTTP collector base URL (e.g., "http://localhost:4318"). An empty string disables
+		// tracing.
+		Endpoint string
+
+		// ServiceName is the value of the "service.name" resource attribute.
+		ServiceName string
+	}
 }
 
 const defaultJSONFormat string = `{
   "error": true,
   "code": {{ code | json }},
   "message": {{ message | json }},
-  "description": {{ description | json }}{{ if show_details }},
+  "description": {{ description | json }}{{ if request_id }},
+  "request_id": {{ request_id | json }}{{ end }}{{ if trace_id }},
+  "trace_id": {{ trace_id | json }}{{ end }}{{ if support_url }},
+  "support_url": {{ support_url | json }}{{ end }}{{ if incident_title }},
+  "incident": {
+    "title": {{ incident_title | json }},
+    "body": {{ incident_body | json }},
+    "url": {{ incident_url | json }}
+  }{{ end }}{{ if maintenance }},
+  "maintenance": {
+    "end": {{ maintenance_end | json }},
+    "countdown": {{ maintenance_countdown }}
+  }{{ end }}{{ if suggestions }},
+  "suggestions": {{ suggestions | json }}{{ end }}{{ if show_details }},
   "details": {
     "host": {{ host | json }},
     "original_uri": {{ original_uri | json }},
@@ -74,7 +237,9 @@ const defaultJSONFormat string = `{
     "ingress_name": {{ ingress_name | json }},
     "service_name": {{ service_name | json }},
     "service_port": {{ service_port | json }},
-    "request_id": {{ request_id | json }},
+    "upstream_service_time": {{ upstream_service_time | json }},
+    "request_id": {{ request_id | json }},{{ range details }}
+    {{ .Name | json }}: {{ .Value | json }},{{ end }}
     "timestamp": {{ nowUnix }}
   }{{ end }}
 }
@@ -84,7 +249,22 @@ const defaultXMLFormat string = `<?xml version="1.0" encoding="utf-8"?>
 <error>
   <code>{{ code }}</code>
   <message>{{ message }}</message>
-  <description>{{ description }}</description>{{ if show_details }}
+  <description>{{ description }}</description>{{ if request_id }}
+  <requestID>{{ request_id | escape }}</requestID>{{ end }}{{ if trace_id }}
+  <traceID>{{ trace_id }}</traceID>{{ end }}{{ if support_url }}
+  <supportURL>{{ support_url | escape }}</supportURL>{{ end }}{{ if incident_title }}
+  <incident>
+    <title>{{ incident_title | escape }}</title>
+    <body>{{ incident_body | escape }}</body>
+    <url>{{ incident_url | escape }}</url>
+  </incident>{{ end }}{{ if maintenance }}
+  <maintenance>
+    <end>{{ maintenance_end }}</end>
+    <countdown>{{ maintenance_countdown }}</countdown>
+  </maintenance>{{ end }}{{ if suggestions }}
+  <suggestions>{{ range suggestions }}
+    <url>{{ . | escape }}</url>{{ end }}
+  </suggestions>{{ end }}{{ if show_details }}
   <details>
     <host>{{ host }}</host>
     <originalURI>{{ original_uri }}</originalURI>
@@ -93,14 +273,28 @@ const defaultXMLFormat string = `<?xml version="1.0" encoding="utf-8"?>
     <ingressName>{{ ingress_name }}</ingressName>
     <serviceName>{{ service_name }}</serviceName>
     <servicePort>{{ service_port }}</servicePort>
-    <requestID>{{ request_id }}</requestID>
+    <upstreamServiceTime>{{ upstream_service_time | escape }}</upstreamServiceTime>
+    <requestID>{{ request_id | escape }}</requestID>{{ range details }}
+    <detail name="{{ .Name | escape }}">{{ .Value | escape }}</detail>{{ end }}
     <timestamp>{{ nowUnix }}</timestamp>
   </details>{{ end }}
 </error>
 ` // an empty line at the end is important for better UX
 
 const defaultPlainTextFormat string = `Error {{ code }}: {{ message }}{{ if description }}
-{{ description }}{{ end }}{{ if show_details }}
+{{ description }}{{ end }}{{ if request_id }}
+Request ID: {{ request_id }}{{ end }}{{ if trace_id }}
+Trace ID: {{ trace_id }}{{ end }}{{ if support_url }}
+Support: {{ support_url }}{{ end }}{{ if incident_title }}
+
+Incident: {{ incident_title }}{{ if incident_body }}
+{{ incident_body }}{{ end }}{{ if incident_url }}
+{{ incident_url }}{{ end }}{{ end }}{{ if maintenance }}
+
+Scheduled maintenance{{ if maintenance_end }} until {{ maintenance_end }}{{ end }}{{ end }}{{ if suggestions }}
+
+Maybe you were looking for:{{ range suggestions }}
+- {{ . }}{{ end }}{{ end }}{{ if show_details }}
 
 Host: {{ host }}
 Original URI: {{ original_uri }}
@@ -109,7 +303,8 @@ Namespace: {{ namespace }}
 Ingress Name: {{ ingress_name }}
 Service Name: {{ service_name }}
 Service Port: {{ service_port }}
-Request ID: {{ request_id }}
+Upstream Service Time: {{ upstream_service_time }}{{ range details }}
+{{ .Name }}: {{ .Value }}{{ end }}
 Timestamp: {{ nowUnix }}{{ end }}
 ` // an empty line at the end is important for better UX
 
@@ -137,6 +332,14 @@ var defaultCodes = Codes{ //nolint:gochecknoglobals
 	"505": {"HTTP Version Not Supported", "The server does not support the \"http protocol\" version"},
 }
 
+// defaultCodeSources contains the places where the proxies pass the error code. Only ingress-nginx uses a header for
+// that - Envoy/Istio and Traefik have no such header, they pass the code in the URL path instead (the `custom_response`
+// redirect policy URI in Envoy, and the `{status}` placeholder of the `errors` middleware query in Traefik), which is
+// always checked first.
+var defaultCodeSources = []CodeSource{ //nolint:gochecknoglobals
+	{Kind: CodeSourceHeader, Name: "X-Code"}, // ingress-nginx custom errors
+}
+
 var defaultProxyHeaders = []string{ //nolint:gochecknoglobals
 	// "Traceparent",  // W3C Trace Context
 	// "Tracestate",   // W3C Trace Context
@@ -145,6 +348,15 @@ var defaultProxyHeaders = []string{ //nolint:gochecknoglobals
 	"X-Amzn-Trace-Id", // to track HTTP requests from clients to targets or other AWS services
 }
 
+var defaultRedactedHeaders = []string{ //nolint:gochecknoglobals
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
 // New creates a new configuration with default values.
 func New() Config {
 	var cfg = Config{
@@ -168,11 +380,29 @@ func New() Config {
 		break
 	}
 
+	// set default places to look for the error code in
+	cfg.CodeSources = slices.Clone(defaultCodeSources)
+
 	// set default HTTP headers to proxy
 	cfg.ProxyHeaders = slices.Clone(defaultProxyHeaders)
 
 	// set defaults
 	cfg.DefaultCodeToRender = http.StatusNotFound
+	cfg.CORS.AllowedMethods = []string{http.MethodGet, http.MethodHead}
+	cfg.CORS.MaxAge = 10 * time.Minute //nolint:mnd
+
+	cfg.AccessLog.Sampling.SummaryInterval = time.Minute
+	cfg.Proxy.InterceptCodes = []string{"404", "5**"}
+	cfg.Proxy.Timeout = 30 * time.Second //nolint:mnd
+	cfg.StatusPage.Codes = []string{"502", "503"}
+	cfg.StatusPage.Interval = time.Minute
+	cfg.Suggestions.Interval = time.Hour
+	cfg.Suggestions.Limit = 3 //nolint:mnd
+	cfg.Tracing.ServiceName = "error-pages"
+
+	// mask the sensitive HTTP headers by default
+	cfg.Redaction.Headers = slices.Clone(defaultRedactedHeaders)
+	cfg.Redaction.KeepChars = 4 //nolint:mnd
 
 	return cfg
 }
@@ -25,6 +25,10 @@ func TestNew(t *testing.T) {
 		assert.True(t, cfg.Templates.Has(cfg.TemplateName))
 		assert.Equal(t, uint16(http.StatusNotFound), cfg.DefaultCodeToRender)
 		assert.False(t, cfg.DisableMinification)
+		assert.Equal(t, []config.CodeSource{{Kind: config.CodeSourceHeader, Name: "X-Code"}}, cfg.CodeSources)
+		assert.True(t, cfg.Redaction.Has("Authorization"))
+		assert.True(t, cfg.Redaction.Has("Cookie"))
+		assert.True(t, cfg.Redaction.Has("Set-Cookie"))
 	})
 
 	t.Run("changing cfg1 should not affect cfg2", func(t *testing.T) {
@@ -37,6 +41,10 @@ func TestNew(t *testing.T) {
 		cfg1.ProxyHeaders = append(cfg1.ProxyHeaders, "foo")
 
 		assert.NotEqual(t, cfg1.ProxyHeaders, cfg2.ProxyHeaders)
+
+		cfg1.CodeSources = append(cfg1.CodeSources, config.CodeSource{Kind: config.CodeSourceQuery, Name: "foo"})
+
+		assert.NotEqual(t, cfg1.CodeSources, cfg2.CodeSources)
 	})
 
 	t.Run("render default format templates", func(t *testing.T) {
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// reservedDetailNames contains the names of the built-in details, rendered next to the additional ones in the
+// default formats (so the additional details with these names would produce the duplicate JSON keys).
+var reservedDetailNames = []string{ //nolint:gochecknoglobals
+	"host",
+	"original_uri",
+	"forwarded_for",
+	"namespace",
+	"ingress_name",
+	"service_name",
+	"service_port",
+	"upstream_service_time",
+	"request_id",
+	"timestamp",
+}
+
+// DetailHeader describes an additional error page detail, taken from the incoming request HTTP header (e.g., the
+// "cluster" detail from the `X-Cluster-Name` header).
+type DetailHeader struct {
+	Name   string // the detail name, used in templates (e.g., `{{ detail "cluster" }}`)
+	Header string // the HTTP header name (canonical)
+}
+
+// String returns the detail header in the "name=header" format (e.g., "cluster=X-Cluster-Name").
+func (d DetailHeader) String() string { return d.Name + "=" + d.Header }
+
+// ParseDetailHeader parses a detail header in the "name=header" format. The name may contain letters, digits,
+// underscores and dashes only (so it's safe to use in any response format), and must not be one of the built-in
+// detail names (e.g., "host" or "request_id").
+func ParseDetailHeader(text string) (DetailHeader, error) {
+	var name, header, ok = strings.Cut(text, "=")
+	if !ok {
+		return DetailHeader{}, fmt.Errorf("the detail header should be in the name=header format: %q", text)
+	}
+
+	if name, header = strings.TrimSpace(name), strings.TrimSpace(header); name == "" || header == "" {
+		return DetailHeader{}, fmt.Errorf("missing name or header in the detail header: %q", text)
+	}
+
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' && r != '-' {
+			return DetailHeader{}, fmt.Errorf("wrong character %q in the detail name: %q", r, name)
+		}
+	}
+
+	if slices.Contains(reservedDetailNames, strings.ToLower(name)) {
+		return DetailHeader{}, fmt.Errorf("the detail name is reserved for the built-in detail: %q", name)
+	}
+
+	if strings.ContainsAny(header, " \t") {
+		return DetailHeader{}, fmt.Errorf("whitespaces are not allowed in the detail header name: %q", text)
+	}
+
+	return DetailHeader{Name: name, Header: http.CanonicalHeaderKey(header)}, nil
+}
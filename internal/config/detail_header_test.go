@@ -0,0 +1,56 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gh.tarampamp.am/error-pages/internal/config"
+)
+
+func TestDetailHeader_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "cluster=X-Cluster-Name", config.DetailHeader{Name: "cluster", Header: "X-Cluster-Name"}.String())
+}
+
+func TestParseDetailHeader(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		giveText   string
+		wantDetail config.DetailHeader
+		wantErrMsg string
+	}{
+		"common":     {giveText: "cluster=X-Cluster-Name", wantDetail: config.DetailHeader{Name: "cluster", Header: "X-Cluster-Name"}}, //nolint:lll
+		"canonical":  {giveText: "pod_name=x-pod", wantDetail: config.DetailHeader{Name: "pod_name", Header: "X-Pod"}},
+		"whitespace": {giveText: " my-pod = X-Pod ", wantDetail: config.DetailHeader{Name: "my-pod", Header: "X-Pod"}},
+
+		"empty":              {giveText: "", wantErrMsg: "should be in the name=header format"},
+		"missing separator":  {giveText: "X-Pod", wantErrMsg: "should be in the name=header format"},
+		"missing name":       {giveText: "=X-Pod", wantErrMsg: "missing name or header"},
+		"missing header":     {giveText: "pod=", wantErrMsg: "missing name or header"},
+		"wrong name":         {giveText: "pod name=X-Pod", wantErrMsg: "wrong character ' ' in the detail name"},
+		"whitespace in name": {giveText: "pod=X Pod", wantErrMsg: "whitespaces are not allowed"},
+		"reserved name":      {giveText: "host=X-Host", wantErrMsg: "the detail name is reserved"},
+		"reserved (case)":    {giveText: "Request_ID=X-Id", wantErrMsg: "the detail name is reserved"},
+		"reserved timestamp": {giveText: "timestamp=X-Time", wantErrMsg: "the detail name is reserved"},
+		"reserved envoy":     {giveText: "upstream_service_time=X-Time", wantErrMsg: "the detail name is reserved"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var detail, err = config.ParseDetailHeader(tt.giveText)
+
+			if tt.wantErrMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrMsg)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDetail, detail)
+		})
+	}
+}
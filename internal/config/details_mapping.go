@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DetailsMapping represents the set of HTTP headers used to fill in the error page details (original URI, service
+// name, etc.), depending on the proxy in front of the server. It doesn't affect the error code sources, since only
+// ingress-nginx passes the code in a header (see the [Config.CodeSources]).
+type DetailsMapping byte
+
+const (
+	DetailsMappingAuto         DetailsMapping = iota // try the headers of all the known proxies, default
+	DetailsMappingIngressNginx                       // ingress-nginx custom errors (X-Original-URI, X-Namespace, ...)
+	DetailsMappingEnvoy                              // Envoy/Istio (x-envoy-original-path, ...)
+	DetailsMappingTraefik                            // Traefik (X-Forwarded-Uri, X-Forwarded-Host, ...)
+)
+
+// String returns a human-readable representation of the details mapping.
+func (m DetailsMapping) String() string {
+	switch m {
+	case DetailsMappingAuto:
+		return "auto"
+	case DetailsMappingIngressNginx:
+		return "ingress-nginx"
+	case DetailsMappingEnvoy:
+		return "envoy"
+	case DetailsMappingTraefik:
+		return "traefik"
+	}
+
+	return fmt.Sprintf("DetailsMapping(%d)", m)
+}
+
+// DetailsMappings returns a slice of all details mappings.
+func DetailsMappings() []DetailsMapping {
+	return []DetailsMapping{
+		DetailsMappingAuto,
+		DetailsMappingIngressNginx,
+		DetailsMappingEnvoy,
+		DetailsMappingTraefik,
+	}
+}
+
+// DetailsMappingStrings returns a slice of all details mappings as strings.
+func DetailsMappingStrings() []string {
+	var (
+		mappings = DetailsMappings()
+		result   = make([]string, len(mappings))
+	)
+
+	for i := range mappings {
+		result[i] = mappings[i].String()
+	}
+
+	return result
+}
+
+// ParseDetailsMapping parses a details mapping (case is ignored) based on its ASCII representation. If the provided
+// ASCII representation is invalid an error is returned.
+func ParseDetailsMapping(text string) (DetailsMapping, error) {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case DetailsMappingAuto.String(), "":
+		return DetailsMappingAuto, nil // the empty string makes sense
+	case DetailsMappingIngressNginx.String(), "nginx":
+		return DetailsMappingIngressNginx, nil
+	case DetailsMappingEnvoy.String(), "istio":
+		return DetailsMappingEnvoy, nil
+	case DetailsMappingTraefik.String():
+		return DetailsMappingTraefik, nil
+	}
+
+	return DetailsMappingAuto, fmt.Errorf("unrecognized details mapping: %q", text)
+}
@@ -0,0 +1,58 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gh.tarampamp.am/error-pages/internal/config"
+)
+
+func TestDetailsMapping_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "auto", config.DetailsMappingAuto.String())
+	assert.Equal(t, "ingress-nginx", config.DetailsMappingIngressNginx.String())
+	assert.Equal(t, "envoy", config.DetailsMappingEnvoy.String())
+	assert.Equal(t, "traefik", config.DetailsMappingTraefik.String())
+
+	assert.Equal(t, "DetailsMapping(255)", config.DetailsMapping(255).String())
+}
+
+func TestDetailsMappingStrings(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"auto", "ingress-nginx", "envoy", "traefik"}, config.DetailsMappingStrings())
+}
+
+func TestParseDetailsMapping(t *testing.T) {
+	t.Parallel()
+
+	for give, want := range map[string]config.DetailsMapping{
+		"":              config.DetailsMappingAuto,
+		"auto":          config.DetailsMappingAuto,
+		"ingress-nginx": config.DetailsMappingIngressNginx,
+		"NGINX":         config.DetailsMappingIngressNginx,
+		"envoy":         config.DetailsMappingEnvoy,
+		" istio ":       config.DetailsMappingEnvoy,
+		"Traefik":       config.DetailsMappingTraefik,
+	} {
+		t.Run(give, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := config.ParseDetailsMapping(give)
+
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := config.ParseDetailsMapping("foobar")
+
+		assert.ErrorContains(t, err, `unrecognized details mapping: "foobar"`)
+	})
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Redaction contains settings for masking sensitive HTTP header values before they are written to the logs or
+// shown in the error page details.
+type Redaction struct {
+	// Headers is a list of HTTP header names (case-insensitive) whose values must be masked.
+	Headers []string
+
+	// KeepChars is the number of leading characters to keep unmasked (e.g., "Bear****" for 4). If the value is
+	// not longer than this number, it is masked entirely.
+	KeepChars uint
+}
+
+// redactionMask is appended to the kept part of the masked value.
+const redactionMask = "****"
+
+// Has checks if the header value must be masked.
+func (r Redaction) Has(header string) bool {
+	for _, h := range r.Headers {
+		if strings.EqualFold(h, header) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Mask masks the value, keeping the configured number of leading characters.
+func (r Redaction) Mask(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	if keep := int(r.KeepChars); keep > 0 && utf8.RuneCountInString(value) > keep { //nolint:gosec
+		return string([]rune(value)[:keep]) + redactionMask
+	}
+
+	return redactionMask
+}
+
+// Redact returns the masked value if the header must be masked, or the value as is otherwise.
+func (r Redaction) Redact(header, value string) string {
+	if r.Has(header) {
+		return r.Mask(value)
+	}
+
+	return value
+}
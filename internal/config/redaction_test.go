@@ -0,0 +1,39 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gh.tarampamp.am/error-pages/internal/config"
+)
+
+func TestRedaction_Redact(t *testing.T) {
+	t.Parallel()
+
+	var r = config.Redaction{Headers: []string{"Authorization", "Cookie"}, KeepChars: 4}
+
+	assert.True(t, r.Has("authorization"))
+	assert.True(t, r.Has("COOKIE"))
+	assert.False(t, r.Has("X-Request-Id"))
+
+	for name, tt := range map[string]struct {
+		giveHeader, giveValue, wantValue string
+	}{
+		"masked":            {giveHeader: "Authorization", giveValue: "Bearer secret-token", wantValue: "Bear****"},
+		"case-insensitive":  {giveHeader: "cookie", giveValue: "session=123456", wantValue: "sess****"},
+		"short value":       {giveHeader: "Cookie", giveValue: "abcd", wantValue: "****"},
+		"multibyte":         {giveHeader: "Cookie", giveValue: "ключ=значение", wantValue: "ключ****"},
+		"empty value":       {giveHeader: "Cookie", giveValue: "", wantValue: ""},
+		"not in the list":   {giveHeader: "X-Request-Id", giveValue: "req-id-123", wantValue: "req-id-123"},
+		"not in the list 2": {giveHeader: "User-Agent", giveValue: "curl/8.0", wantValue: "curl/8.0"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.wantValue, r.Redact(tt.giveHeader, tt.giveValue))
+		})
+	}
+
+	t.Run("keep nothing", func(t *testing.T) {
+		assert.Equal(t, "****", config.Redaction{}.Mask("Bearer secret-token"))
+	})
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	ep "gh.tarampamp.am/error-pages/internal/http/handlers/error_page"
+	"gh.tarampamp.am/error-pages/internal/logger"
+)
+
+// fastCGIServer holds the FastCGI listener and counts the requests being processed, so the server can be stopped
+// gracefully.
+type fastCGIServer struct {
+	mu     sync.Mutex
+	ln     net.Listener // nil if the FastCGI server is not started
+	active atomic.Int64 // the number of the requests being processed
+}
+
+// track wraps the handler to count the requests being processed.
+func (f *fastCGIServer) track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.active.Add(1)
+		defer f.active.Add(-1)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// shutdown closes the FastCGI listener and waits for the requests being processed to complete, until the context
+// is done. It returns false if the FastCGI server is not started.
+func (f *fastCGIServer) shutdown(ctx context.Context) (bool, error) {
+	f.mu.Lock()
+	var ln = f.ln
+	f.mu.Unlock()
+
+	if ln == nil {
+		return false, nil
+	}
+
+	if err := ln.Close(); err != nil {
+		return true, err
+	}
+
+	var ticker = time.NewTicker(10 * time.Millisecond) //nolint:mnd
+	defer ticker.Stop()
+
+	for f.active.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	return true, nil
+}
+
+// newFastCGIHandler converts the FastCGI requests into the fasthttp ones and passes them to the handler, so the
+// routing is the same as for the HTTP server. The CGI variables, set by the web servers for the error documents
+// (e.g., Apache `ErrorDocument` or lighttpd `server.error-handler`), are mapped: the `REDIRECT_STATUS` to the error
+// page code, and the `REQUEST_URI` (the original request URI in this case) to the `X-Original-URI` header. The
+// request body is not passed, since it's not used for the error pages rendering.
+func newFastCGIHandler(
+	handler fasthttp.RequestHandler,
+	basePath string,
+	env func(*http.Request) map[string]string, // the CGI variables (fcgi.ProcessEnv)
+	log *logger.Logger,
+) http.Handler {
+	var stdLog = logger.NewStdLog(log)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			req     fasthttp.Request
+			ctx     fasthttp.RequestCtx
+			uri     = r.URL.RequestURI()
+			code, _ = strconv.ParseUint(env(r)["REDIRECT_STATUS"], 10, 16)
+			forced  = code >= http.StatusBadRequest && code <= 999 // the success statuses (action handlers) are ignored
+		)
+
+		if forced {
+			if r.Header.Get("X-Original-URI") == "" {
+				req.Header.Set("X-Original-URI", uri)
+			}
+
+			uri = basePath + "/" + strconv.FormatUint(code, 10)
+
+			if r.URL.RawQuery != "" { // the query may contain the code sources, format, etc.
+				uri += "?" + r.URL.RawQuery
+			}
+		}
+
+		req.Header.SetMethod(r.Method)
+		req.SetRequestURI(uri)
+		req.Header.SetHost(r.Host)
+
+		for name, values := range r.Header {
+			for _, value := range values {
+				req.Header.Add(name, value)
+			}
+		}
+
+		var remoteAddr net.Addr
+
+		if addr, err := netip.ParseAddrPort(r.RemoteAddr); err == nil {
+			remoteAddr = net.TCPAddrFromAddrPort(addr)
+		}
+
+		ctx.Init(&req, remoteAddr, stdLog)
+
+		if forced {
+			ep.ForceCode(&ctx, uint16(code))
+		}
+
+		handler(&ctx)
+
+		for name, value := range ctx.Response.Header.All() {
+			if key := string(name); key != fasthttp.HeaderContentLength {
+				w.Header().Add(key, string(value))
+			}
+		}
+
+		w.WriteHeader(ctx.Response.StatusCode())
+
+		if r.Method != http.MethodHead {
+			_, _ = w.Write(ctx.Response.Body())
+		}
+	})
+}
@@ -0,0 +1,218 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"gh.tarampamp.am/error-pages/internal/config"
+	"gh.tarampamp.am/error-pages/internal/logger"
+)
+
+func TestFastCGIHandler(t *testing.T) {
+	t.Parallel()
+
+	var (
+		srv = NewServer(logger.NewNop(), 1025*5)
+		cfg = config.New()
+	)
+
+	cfg.BasePath = "/_errors"
+	cfg.ShowDetails = true
+	cfg.Formats.PlainText = "{{ code }}: {{ original_uri }}"
+
+	require.NoError(t, srv.Register(&cfg))
+
+	t.Cleanup(func() { srv.beforeStop(); srv.afterStop() })
+
+	for name, tt := range map[string]struct {
+		giveURL     string
+		giveEnv     map[string]string
+		giveHeaders map[string]string
+		giveMethod  string
+		wantCode    int
+		wantBody    string
+		wantHeaders map[string]string
+	}{
+		"error document": {
+			giveURL:  "/some/missing/page?foo=bar",
+			giveEnv:  map[string]string{"REDIRECT_STATUS": "404"},
+			wantCode: http.StatusNotFound,
+			wantBody: "404: /some/missing/page?foo=bar",
+			wantHeaders: map[string]string{
+				"Content-Type": "text/plain; charset=utf-8",
+				"X-Robots-Tag": "noindex",
+			},
+		},
+		"error document with the original URI header": {
+			giveURL:     "/foo",
+			giveEnv:     map[string]string{"REDIRECT_STATUS": "503"},
+			giveHeaders: map[string]string{"X-Original-URI": "/bar"},
+			wantCode:    http.StatusServiceUnavailable,
+			wantBody:    "503: /bar",
+			wantHeaders: map[string]string{"Retry-After": "120"},
+		},
+		"error document HEAD request": {
+			giveURL:    "/foo",
+			giveEnv:    map[string]string{"REDIRECT_STATUS": "500"},
+			giveMethod: http.MethodHead,
+			wantCode:   http.StatusInternalServerError,
+		},
+		"success status is ignored": {
+			giveURL:  "/_errors/502",
+			giveEnv:  map[string]string{"REDIRECT_STATUS": "200"},
+			wantCode: http.StatusOK,
+			wantBody: "502: ",
+		},
+		"regular routing": {
+			giveURL:  "/_errors/version",
+			wantCode: http.StatusOK,
+			wantBody: `"version"`,
+		},
+		"not found": {
+			giveURL:  "/foo",
+			wantCode: http.StatusNotFound,
+			wantBody: "Not Found\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				handler = newFastCGIHandler(srv.server.Handler, cfg.BasePath,
+					func(*http.Request) map[string]string { return tt.giveEnv }, logger.NewNop(),
+				)
+				method = tt.giveMethod
+			)
+
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			var (
+				req = httptest.NewRequest(method, tt.giveURL, http.NoBody)
+				rec = httptest.NewRecorder()
+			)
+
+			req.RemoteAddr = "10.0.0.1:12345"
+			req.RequestURI = "" // not set by the net/http/fcgi (the URL is parsed from the REQUEST_URI instead)
+
+			for name, value := range tt.giveHeaders {
+				req.Header.Set(name, value)
+			}
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+
+			if method == http.MethodHead {
+				assert.Empty(t, rec.Body.String())
+			}
+
+			for name, value := range tt.wantHeaders {
+				assert.Equal(t, value, rec.Header().Get(name))
+			}
+		})
+	}
+}
+
+func TestFastCGIHandler_RequestURI(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		giveURL    string
+		giveStatus string
+		wantURI    string
+	}{
+		"error document":        {giveURL: "/foo", giveStatus: "404", wantURI: "/_errors/404"},
+		"error document query":  {giveURL: "/foo?code=502&lang=fr", giveStatus: "404", wantURI: "/_errors/404?code=502&lang=fr"},
+		"max code":              {giveURL: "/foo", giveStatus: "999", wantURI: "/_errors/999"},
+		"too big code":          {giveURL: "/foo?bar", giveStatus: "1000", wantURI: "/foo?bar"},
+		"success status":        {giveURL: "/foo?bar", giveStatus: "200", wantURI: "/foo?bar"},
+		"not an error document": {giveURL: "/_errors/404?bar", wantURI: "/_errors/404?bar"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				gotURI  string
+				handler = newFastCGIHandler(func(ctx *fasthttp.RequestCtx) { gotURI = string(ctx.RequestURI()) },
+					"/_errors",
+					func(*http.Request) map[string]string { return map[string]string{"REDIRECT_STATUS": tt.giveStatus} },
+					logger.NewNop(),
+				)
+				req = httptest.NewRequest(http.MethodGet, tt.giveURL, http.NoBody)
+			)
+
+			req.RequestURI = "" // not set by the net/http/fcgi
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.wantURI, gotURI)
+		})
+	}
+}
+
+func TestFastCGIServer_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	var f fastCGIServer
+
+	started, err := f.shutdown(context.Background())
+	assert.False(t, started)
+	require.NoError(t, err)
+
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	f.ln = ln
+
+	var (
+		entered, release = make(chan struct{}), make(chan struct{})
+		handler          = f.track(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			close(entered)
+			<-release
+		}))
+		done = make(chan struct{})
+	)
+
+	go func() {
+		defer close(done)
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	}()
+
+	<-entered
+
+	{ // the request is still being processed - the timeout is exceeded
+		var ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+
+		started, err = f.shutdown(ctx)
+
+		cancel()
+
+		assert.True(t, started)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+
+	f.ln, err = net.Listen("tcp4", "127.0.0.1:0") // the previous one is closed
+	require.NoError(t, err)
+
+	go func() { time.Sleep(50 * time.Millisecond); close(release) }()
+
+	started, err = f.shutdown(context.Background()) // waits for the request to complete
+
+	assert.True(t, started)
+	require.NoError(t, err)
+	assert.Zero(t, f.active.Load())
+
+	<-done
+}
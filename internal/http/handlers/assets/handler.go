@@ -0,0 +1,115 @@
+package assets
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// New creates a new handler that serves the files from the provided file system for GET and HEAD requests. The
+// prefix (without the trailing slash) is stripped from the request path to get the file name (e.g.,
+// "/_assets/logo.png" -> "logo.png").
+//
+// Only valid [fs.FS] paths are accepted, so the requests like "/_assets/../secret" are rejected. To protect from
+// symlinks pointing outside the directory, use the [os.Root.FS] as the file system.
+func New(fsys fs.FS, prefix string) fasthttp.RequestHandler {
+	const cacheControl = "public, max-age=86400" // 1 day
+
+	var (
+		notFound   = http.StatusText(http.StatusNotFound) + "\n"
+		notAllowed = http.StatusText(http.StatusMethodNotAllowed) + "\n"
+	)
+
+	return func(ctx *fasthttp.RequestCtx) {
+		var method = string(ctx.Method())
+
+		if method != fasthttp.MethodGet && method != fasthttp.MethodHead {
+			ctx.Error(notAllowed, http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		// the path is already normalized by fasthttp (dot segments are resolved), so after that it must still
+		// be under the prefix
+		var name, underPrefix = strings.CutPrefix(string(ctx.Path()), prefix+"/")
+
+		if !underPrefix || name == "" || !fs.ValidPath(name) {
+			ctx.Error(notFound, http.StatusNotFound)
+
+			return
+		}
+
+		var stat, statErr = fs.Stat(fsys, name)
+		if statErr != nil || stat.IsDir() {
+			ctx.Error(notFound, http.StatusNotFound)
+
+			return
+		}
+
+		var (
+			modTime = stat.ModTime().UTC().Truncate(time.Second)
+			eTag    = fmt.Sprintf(`W/"%x-%x"`, modTime.Unix(), stat.Size())
+		)
+
+		ctx.Response.Header.Set("Cache-Control", cacheControl)
+		ctx.Response.Header.Set("ETag", eTag)
+		ctx.Response.Header.Set("Last-Modified", modTime.Format(http.TimeFormat))
+
+		if notModified(&ctx.Request.Header, eTag, modTime) {
+			ctx.SetStatusCode(http.StatusNotModified)
+
+			return
+		}
+
+		var content, readErr = fs.ReadFile(fsys, name)
+		if readErr != nil {
+			if errors.Is(readErr, fs.ErrNotExist) {
+				ctx.Error(notFound, http.StatusNotFound)
+			} else {
+				ctx.Error(http.StatusText(http.StatusInternalServerError)+"\n", http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+			ctx.SetContentType(contentType)
+		} else {
+			ctx.SetContentType(http.DetectContentType(content))
+		}
+
+		ctx.SetStatusCode(http.StatusOK)
+
+		if method == fasthttp.MethodGet {
+			_, _ = ctx.Write(content)
+		}
+	}
+}
+
+// notModified checks the conditional request headers (If-None-Match has priority over If-Modified-Since).
+func notModified(headers *fasthttp.RequestHeader, eTag string, modTime time.Time) bool {
+	if ifNoneMatch := string(headers.Peek("If-None-Match")); ifNoneMatch != "" {
+		for _, tag := range strings.Split(ifNoneMatch, ",") {
+			if tag = strings.TrimSpace(tag); tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(eTag, "W/") {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if ifModifiedSince := string(headers.Peek("If-Modified-Since")); ifModifiedSince != "" {
+		if since, err := http.ParseTime(ifModifiedSince); err == nil && !modTime.After(since) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,114 @@
+package assets_test
+
+import (
+	"net/http"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gh.tarampamp.am/error-pages/internal/http/handlers/assets"
+	"gh.tarampamp.am/error-pages/internal/http/httptest"
+)
+
+func TestServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	var (
+		modTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		handler = assets.New(fstest.MapFS{
+			"logo.svg":       {Data: []byte("<svg></svg>"), ModTime: modTime},
+			"fonts/font.bin": {Data: []byte{0, 1, 2}, ModTime: modTime},
+			"robots.txt":     {Data: []byte("User-agent: *\n"), ModTime: modTime},
+		}, "/_assets")
+		body = http.NoBody
+	)
+
+	t.Run("get", func(t *testing.T) {
+		httptest.HandleFast(t, handler, http.MethodGet, "http://testing/_assets/logo.svg", body,
+			func(status int, body string, headers http.Header) {
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, "image/svg+xml", headers.Get("Content-Type"))
+				assert.Equal(t, "public, max-age=86400", headers.Get("Cache-Control"))
+				assert.Equal(t, "Tue, 02 Jan 2024 03:04:05 GMT", headers.Get("Last-Modified"))
+				assert.NotEmpty(t, headers.Get("ETag"))
+				assert.Equal(t, "<svg></svg>", body)
+			},
+		)
+	})
+
+	t.Run("get (nested, unknown extension)", func(t *testing.T) {
+		httptest.HandleFast(t, handler, http.MethodGet, "http://testing/_assets/fonts/font.bin", body,
+			func(status int, body string, headers http.Header) {
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, "application/octet-stream", headers.Get("Content-Type"))
+				assert.Equal(t, []byte{0, 1, 2}, []byte(body))
+			},
+		)
+	})
+
+	t.Run("head", func(t *testing.T) {
+		httptest.HandleFast(t, handler, http.MethodHead, "http://testing/_assets/robots.txt", body,
+			func(status int, body string, _ http.Header) {
+				assert.Equal(t, http.StatusOK, status)
+				assert.Empty(t, body)
+			},
+		)
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		var eTag string
+
+		httptest.HandleFast(t, handler, http.MethodGet, "http://testing/_assets/logo.svg", body,
+			func(_ int, _ string, headers http.Header) { eTag = headers.Get("ETag") },
+		)
+
+		for name, giveHeaders := range map[string]map[string]string{
+			"if-none-match":     {"If-None-Match": eTag},
+			"if-modified-since": {"If-Modified-Since": "Tue, 02 Jan 2024 03:04:05 GMT"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				req, err := http.NewRequest(http.MethodGet, "http://testing/_assets/logo.svg", http.NoBody)
+				require.NoError(t, err)
+
+				for k, v := range giveHeaders {
+					req.Header.Set(k, v)
+				}
+
+				httptest.HandleFastRequest(t, handler, req, func(status int, body string, _ http.Header) {
+					assert.Equal(t, http.StatusNotModified, status)
+					assert.Empty(t, body)
+				})
+			})
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		for _, url := range []string{
+			"http://testing/_assets/",
+			"http://testing/_assets/fonts",
+			"http://testing/_assets/missing.png",
+			"http://testing/_assets/../logo.svg",
+			"http://testing/_assets/fonts/../../logo.svg",
+			"http://testing/_assets/%2e%2e/logo.svg",
+			"http://testing/logo.svg",
+		} {
+			httptest.HandleFast(t, handler, http.MethodGet, url, body, func(status int, _ string, _ http.Header) {
+				assert.Equal(t, http.StatusNotFound, status, url)
+			})
+		}
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		for _, method := range []string{http.MethodDelete, http.MethodPatch, http.MethodPost, http.MethodPut} {
+			httptest.HandleFast(t, handler, method, "http://testing/_assets/logo.svg", body,
+				func(status int, body string, _ http.Header) {
+					assert.Equal(t, http.StatusMethodNotAllowed, status)
+					assert.Equal(t, "Method Not Allowed\n", body)
+				},
+			)
+		}
+	})
+}
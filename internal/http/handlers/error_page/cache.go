@@ -35,6 +35,11 @@ func NewRenderedCache(ttl time.Duration) *RenderedCache {
 
 // genKey generates a key for the cache item by hashing the template and props.
 func (rc *RenderedCache) genKey(template string, props template.Props) [32]byte {
+	// the maintenance countdown changes every second, but it's derived from the maintenance end (which is a part of
+	// the key), and the cached content is not returned after the ttl (less than a second) - so it's excluded to keep
+	// the cache hits
+	props.MaintenanceLeft = 0
+
 	var (
 		key    [32]byte
 		th, ph = hash(template), hash(props) // template hash, props hash
@@ -66,7 +71,7 @@ func (rc *RenderedCache) Put(template string, props template.Props, content []by
 	rc.mu.Unlock()
 }
 
-// Get returns the content of the item with the specified template and props.
+// Get returns the content of the item with the specified template and props. The expired items are not returned.
 func (rc *RenderedCache) Get(template string, props template.Props) ([]byte, bool) {
 	var key = rc.genKey(template, props)
 
@@ -74,6 +79,10 @@ func (rc *RenderedCache) Get(template string, props template.Props) ([]byte, boo
 	item, ok := rc.items[key]
 	rc.mu.RUnlock()
 
+	if ok && time.Now().UnixNano()-item.addedAtNano > rc.ttl.Nanoseconds() {
+		return nil, false // expired, but not removed yet
+	}
+
 	return item.content, ok
 }
 
@@ -15,7 +15,7 @@ import (
 func TestRenderedCache_CRUD(t *testing.T) {
 	t.Parallel()
 
-	var cache = error_page.NewRenderedCache(time.Millisecond)
+	var cache = error_page.NewRenderedCache(time.Minute)
 
 	t.Run("has", func(t *testing.T) {
 		assert.False(t, cache.Has("template", template.Props{}))
@@ -69,6 +69,25 @@ func TestRenderedCache_CRUD(t *testing.T) {
 	})
 }
 
+func TestRenderedCache_MaintenanceCountdown(t *testing.T) {
+	t.Parallel()
+
+	var (
+		cache = error_page.NewRenderedCache(time.Minute)
+		props = template.Props{Maintenance: true, MaintenanceEnd: "2024-01-01T12:00:00Z", MaintenanceLeft: 60}
+	)
+
+	cache.Put("template", props, []byte("content"))
+
+	props.MaintenanceLeft = 59 // a second later - the same content
+
+	assert.True(t, cache.Has("template", props))
+
+	props.MaintenanceEnd = "2024-01-01T13:00:00Z" // another maintenance end
+
+	assert.False(t, cache.Has("template", props))
+}
+
 func TestRenderedCache_Expiring(t *testing.T) {
 	t.Parallel()
 
@@ -81,6 +100,10 @@ func TestRenderedCache_Expiring(t *testing.T) {
 	<-time.After(10 * time.Millisecond)
 
 	assert.True(t, cache.Has("template", template.Props{})) // expired, but not cleared yet
+
+	_, ok := cache.Get("template", template.Props{})
+	assert.False(t, ok) // but not returned
+
 	cache.ClearExpired()
 	assert.False(t, cache.Has("template", template.Props{})) // cleared
 }
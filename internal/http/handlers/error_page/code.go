@@ -1,62 +1,121 @@
 package error_page
 
 import (
+	"net/http"
 	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/valyala/fasthttp"
+
+	"gh.tarampamp.am/error-pages/internal/config"
 )
 
-// extractCodeFromURL extracts the error code from the given URL.
-func extractCodeFromURL(url string) (uint16, bool) {
+// extractCodeFromURL extracts the error code from the given URL. The format is forced by the file extension
+// (e.g., `/404.json`, see the extensions map) and will be unknownFormat if the extension does not force any format.
+func extractCodeFromURL(url string, extensions map[string]preferredFormat) (uint16, preferredFormat, bool) {
 	var parts = strings.SplitN(strings.TrimLeft(url, "/"), "/", 1)
 
 	if len(parts) == 0 {
-		return 0, false
+		return 0, unknownFormat, false
 	}
 
 	var (
 		fileName = strings.ToLower(parts[0])
-		ext      = filepath.Ext(fileName) // ".html", ".htm", ".%something%" or an empty string
+		ext      = filepath.Ext(fileName) // ".html", ".json", ".%something%" or an empty string
+		format   = unknownFormat
 	)
 
-	if ext != "" && ext != ".html" && ext != ".htm" {
-		return 0, false
-	} else if ext != "" {
-		fileName = strings.TrimSuffix(fileName, ext)
+	if ext != "" {
+		if f, known := extensions[ext]; known {
+			fileName, format = strings.TrimSuffix(fileName, ext), f
+		} else {
+			return 0, unknownFormat, false
+		}
 	}
 
-	if code, err := strconv.ParseUint(fileName, 10, 16); err == nil && code > 0 && code < 999 {
-		return uint16(code), true
+	if code, err := strconv.ParseUint(fileName, 10, 16); err == nil && code > 0 && code <= 999 {
+		return uint16(code), format, true
 	}
 
-	return 0, false
+	return 0, unknownFormat, false
+}
+
+// CodeFromURL extracts the error code from the URL path (e.g., `/404` or `/404.json`), along with the name of the
+// format forced by the file extension (empty if the format is not forced).
+func CodeFromURL(url string) (code uint16, format string, ok bool) {
+	var f preferredFormat
+
+	if code, f, ok = extractCodeFromURL(url, formatsByExtension); ok && f != unknownFormat {
+		format = formatName(f)
+	}
+
+	return
 }
 
 // URLContainsCode checks if the given URL contains an error code.
-func URLContainsCode(url string) (ok bool) { _, ok = extractCodeFromURL(url); return } //nolint:nlreturn
+func URLContainsCode(url string) (ok bool) {
+	_, _, ok = extractCodeFromURL(url, formatsByExtension)
+
+	return
+}
 
-// extractCodeFromHeaders extracts the error code from the given headers.
-func extractCodeFromHeaders(headers *fasthttp.RequestHeader) (uint16, bool) {
-	if headers == nil {
+// extractCodeFromRequest extracts the error code from the given request, using the sources (HTTP headers, query
+// parameters) in the order they are listed. The first valid code wins.
+func extractCodeFromRequest(req *fasthttp.Request, sources []config.CodeSource) (uint16, bool) {
+	if req == nil {
 		return 0, false
 	}
 
-	// https://kubernetes.github.io/ingress-nginx/user-guide/custom-errors/
-	// HTTP status code returned by the request
-	if value := headers.Peek("X-Code"); len(value) > 0 && len(value) <= 3 {
-		if code, err := strconv.ParseUint(string(value), 10, 16); err == nil && code > 0 && code < 999 {
-			return uint16(code), true
+	return CodeFromSources(sources,
+		// e.g. https://kubernetes.github.io/ingress-nginx/user-guide/custom-errors/ (X-Code)
+		func(name string) string { return string(req.Header.Peek(name)) },
+		func(name string) string { return string(req.URI().QueryArgs().Peek(name)) },
+	)
+}
+
+// CodeFromSources extracts the error code using the sources (HTTP headers, query parameters) in the order they are
+// listed. The header and query functions return the value of the request HTTP header and URL query parameter by
+// name, so it can be used with any HTTP server implementation. The first valid code wins.
+func CodeFromSources(sources []config.CodeSource, header, query func(name string) string) (uint16, bool) {
+	for _, source := range sources {
+		var value string
+
+		switch source.Kind {
+		case config.CodeSourceHeader:
+			value = header(source.Name)
+		case config.CodeSourceQuery:
+			value = query(source.Name)
+		}
+
+		if len(value) > 0 && len(value) <= 3 {
+			if code, err := strconv.ParseUint(value, 10, 16); err == nil && code > 0 && code <= 999 {
+				return uint16(code), true
+			}
 		}
 	}
 
 	return 0, false
 }
 
-// HeadersContainCode checks if the given headers contain an error code.
-func HeadersContainCode(headers *fasthttp.RequestHeader) (ok bool) {
-	_, ok = extractCodeFromHeaders(headers)
+// RetryAfter returns the value of the `Retry-After` response header for the given error code, or an empty string if
+// the client should not retry the request (e.g., for the client errors).
+//
+// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Retry-After
+func RetryAfter(code uint16) string {
+	switch code {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return "120" // tell the client (search crawler) to retry the request after 120 seconds
+	}
+
+	return ""
+}
+
+// RequestContainsCode checks if the given request contains an error code in any of the given sources.
+func RequestContainsCode(req *fasthttp.Request, sources []config.CodeSource) (ok bool) {
+	_, ok = extractCodeFromRequest(req, sources)
 
 	return
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/valyala/fasthttp"
 
+	"gh.tarampamp.am/error-pages/internal/config"
 	"gh.tarampamp.am/error-pages/internal/http/handlers/error_page"
 )
 
@@ -18,7 +19,14 @@ func TestURLContainsCode(t *testing.T) {
 		"/404.HTM":      true,
 		"/404.html":     true,
 		"/404.HtmL":     true,
+		"/404.json":     true,
+		"/404.JSON":     true,
+		"/404.xml":      true,
+		"/404.txt":      true,
+		"/999":          true,
+		"/1000":         false,
 		"/404.css":      false,
+		"/404.yaml":     false,
 		"/foo/404":      false,
 		"/foo/404.html": false,
 		"/error":        false,
@@ -33,34 +41,104 @@ func TestURLContainsCode(t *testing.T) {
 	}
 }
 
-func TestHeadersContainCode(t *testing.T) {
+func TestRequestContainsCode(t *testing.T) {
 	t.Parallel()
 
-	var mkHeaders = func(key, value string) *fasthttp.RequestHeader {
-		var out = new(fasthttp.RequestHeader)
+	var (
+		mkRequest = func(uri, key, value string) *fasthttp.Request {
+			var out = new(fasthttp.Request)
 
-		out.Set(key, value)
+			out.SetRequestURI(uri)
 
-		return out
-	}
+			if key != "" {
+				out.Header.Set(key, value)
+			}
+
+			return out
+		}
+
+		xCode       = []config.CodeSource{{Kind: config.CodeSourceHeader, Name: "X-Code"}}
+		queryStatus = []config.CodeSource{{Kind: config.CodeSourceQuery, Name: "status"}}
+	)
 
 	for name, _tt := range map[string]struct {
-		giveHeaders *fasthttp.RequestHeader
+		giveRequest *fasthttp.Request
+		giveSources []config.CodeSource
 		wantOk      bool
 	}{
-		"with code": {giveHeaders: mkHeaders("X-Code", "404"), wantOk: true},
-
-		"empty":     {giveHeaders: nil},
-		"no code":   {giveHeaders: mkHeaders("X-Code", "")},
-		"wrong":     {giveHeaders: mkHeaders("X-Code", "foo")},
-		"too big":   {giveHeaders: mkHeaders("X-Code", "1000")},
-		"too small": {giveHeaders: mkHeaders("X-Code", "0")},
-		"negative":  {giveHeaders: mkHeaders("X-Code", "-1")},
+		"with code":          {giveRequest: mkRequest("/", "X-Code", "404"), giveSources: xCode, wantOk: true},
+		"with code in query": {giveRequest: mkRequest("/?status=502", "", ""), giveSources: queryStatus, wantOk: true},
+
+		"empty":                   {giveRequest: nil, giveSources: xCode},
+		"no sources":              {giveRequest: mkRequest("/", "X-Code", "404")},
+		"no code":                 {giveRequest: mkRequest("/", "X-Code", ""), giveSources: xCode},
+		"wrong":                   {giveRequest: mkRequest("/", "X-Code", "foo"), giveSources: xCode},
+		"max":                     {giveRequest: mkRequest("/", "X-Code", "999"), giveSources: xCode, wantOk: true},
+		"too big":                 {giveRequest: mkRequest("/", "X-Code", "1000"), giveSources: xCode},
+		"too small":               {giveRequest: mkRequest("/", "X-Code", "0"), giveSources: xCode},
+		"negative":                {giveRequest: mkRequest("/", "X-Code", "-1"), giveSources: xCode},
+		"header is not in source": {giveRequest: mkRequest("/", "X-Status", "404"), giveSources: xCode},
+		"query is not in source":  {giveRequest: mkRequest("/?code=404", "", ""), giveSources: queryStatus},
+		"wrong code in query":     {giveRequest: mkRequest("/?status=foo", "", ""), giveSources: queryStatus},
 	} {
 		tt := _tt
 
 		t.Run(name, func(t *testing.T) {
-			assert.Equal(t, tt.wantOk, error_page.HeadersContainCode(tt.giveHeaders))
+			assert.Equal(t, tt.wantOk, error_page.RequestContainsCode(tt.giveRequest, tt.giveSources))
+		})
+	}
+}
+
+func TestCodeFromSources(t *testing.T) {
+	t.Parallel()
+
+	var (
+		sources = []config.CodeSource{
+			{Kind: config.CodeSourceQuery, Name: "status"},
+			{Kind: config.CodeSourceHeader, Name: "X-Code"},
+		}
+		values = func(m map[string]string) func(string) string {
+			return func(name string) string { return m[name] }
+		}
+	)
+
+	for name, tt := range map[string]struct {
+		giveHeaders, giveQuery map[string]string
+		wantCode               uint16
+		wantOk                 bool
+	}{
+		"header":          {giveHeaders: map[string]string{"X-Code": "404"}, wantCode: 404, wantOk: true},
+		"query":           {giveQuery: map[string]string{"status": "502"}, wantCode: 502, wantOk: true},
+		"query first":     {giveHeaders: map[string]string{"X-Code": "404"}, giveQuery: map[string]string{"status": "502"}, wantCode: 502, wantOk: true}, //nolint:lll
+		"invalid skipped": {giveHeaders: map[string]string{"X-Code": "404"}, giveQuery: map[string]string{"status": "abc"}, wantCode: 404, wantOk: true}, //nolint:lll
+		"too long":        {giveHeaders: map[string]string{"X-Code": "4040"}},
+		"zero":            {giveHeaders: map[string]string{"X-Code": "0"}},
+		"missing":         {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var code, ok = error_page.CodeFromSources(sources, values(tt.giveHeaders), values(tt.giveQuery))
+
+			assert.Equal(t, tt.wantCode, code)
+			assert.Equal(t, tt.wantOk, ok)
 		})
 	}
 }
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	for code, want := range map[uint16]string{
+		400: "",
+		404: "",
+		408: "120",
+		429: "120",
+		500: "120",
+		501: "",
+		503: "120",
+		504: "120",
+	} {
+		assert.Equal(t, want, error_page.RetryAfter(code), code)
+	}
+}
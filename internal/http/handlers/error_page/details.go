@@ -0,0 +1,106 @@
+package error_page
+
+import (
+	"slices"
+
+	"gh.tarampamp.am/error-pages/internal/config"
+	"gh.tarampamp.am/error-pages/internal/template"
+)
+
+// detailsHeaders contains the HTTP header names (in priority order) used to fill in the error page details.
+type detailsHeaders struct {
+	originalURI, namespace, ingressName, serviceName, servicePort, forwardedFor, host, upstreamServiceTime []string
+}
+
+//nolint:gochecknoglobals
+var (
+	// https://kubernetes.github.io/ingress-nginx/user-guide/custom-errors/
+	ingressNginxHeaders = detailsHeaders{
+		originalURI:  []string{"X-Original-URI"},  // URI that caused the error
+		namespace:    []string{"X-Namespace"},     // namespace where the backend Service is located
+		ingressName:  []string{"X-Ingress-Name"},  // name of the Ingress where the backend is defined
+		serviceName:  []string{"X-Service-Name"},  // name of the Service backing the backend
+		servicePort:  []string{"X-Service-Port"},  // port number of the Service backing the backend
+		forwardedFor: []string{"X-Forwarded-For"}, // the value of the `X-Forwarded-For` header
+		host:         []string{"Host"},            // the value of the `Host` header
+	}
+
+	// https://www.envoyproxy.io/docs/envoy/latest/configuration/http/http_conn_man/headers
+	envoyHeaders = detailsHeaders{
+		originalURI:         []string{"X-Envoy-Original-Path"},                       // path before the rewrite
+		forwardedFor:        []string{"X-Forwarded-For", "X-Envoy-External-Address"}, // the client address
+		host:                []string{"Host"},                                        // the `:authority` value
+		upstreamServiceTime: []string{"X-Envoy-Upstream-Service-Time"},               // upstream processing time
+	}
+
+	// https://doc.traefik.io/traefik/getting-started/faq/#what-are-the-forwarded-headers-when-proxying-http-requests
+	traefikHeaders = detailsHeaders{
+		originalURI:  []string{"X-Forwarded-Uri", "X-Replaced-Path"}, // URI before the errors middleware rewrite
+		forwardedFor: []string{"X-Forwarded-For", "X-Real-Ip"},       // the client address
+		host:         []string{"X-Forwarded-Host", "Host"},           // the requested host
+	}
+
+	// the "auto" mapping tries the headers of all the proxies (the ingress-nginx ones have the highest priority)
+	autoHeaders = mergeDetailsHeaders(ingressNginxHeaders, envoyHeaders, traefikHeaders)
+)
+
+// detailsHeadersFor returns the HTTP header names used to fill in the error page details for the given mapping.
+func detailsHeadersFor(mapping config.DetailsMapping) detailsHeaders {
+	switch mapping {
+	case config.DetailsMappingIngressNginx:
+		return ingressNginxHeaders
+	case config.DetailsMappingEnvoy:
+		return envoyHeaders
+	case config.DetailsMappingTraefik:
+		return traefikHeaders
+	default:
+		return autoHeaders
+	}
+}
+
+// fill sets the error page details using the first non-empty header value for every detail.
+func (h detailsHeaders) fill(props *template.Props, peek func(name string) string) {
+	props.OriginalURI = firstValue(h.originalURI, peek)
+	props.Namespace = firstValue(h.namespace, peek)
+	props.IngressName = firstValue(h.ingressName, peek)
+	props.ServiceName = firstValue(h.serviceName, peek)
+	props.ServicePort = firstValue(h.servicePort, peek)
+	props.ForwardedFor = firstValue(h.forwardedFor, peek)
+	props.Host = firstValue(h.host, peek)
+	props.UpstreamServiceTime = firstValue(h.upstreamServiceTime, peek)
+}
+
+// firstValue returns the first non-empty value of the given headers.
+func firstValue(names []string, peek func(name string) string) string {
+	for _, name := range names {
+		if value := peek(name); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// mergeDetailsHeaders merges the header names of the given mappings, keeping the order and skipping duplicates.
+func mergeDetailsHeaders(list ...detailsHeaders) (result detailsHeaders) {
+	var merge = func(dst *[]string, src []string) {
+		for _, name := range src {
+			if !slices.Contains(*dst, name) {
+				*dst = append(*dst, name)
+			}
+		}
+	}
+
+	for _, h := range list {
+		merge(&result.originalURI, h.originalURI)
+		merge(&result.namespace, h.namespace)
+		merge(&result.ingressName, h.ingressName)
+		merge(&result.serviceName, h.serviceName)
+		merge(&result.servicePort, h.servicePort)
+		merge(&result.forwardedFor, h.forwardedFor)
+		merge(&result.host, h.host)
+		merge(&result.upstreamServiceTime, h.upstreamServiceTime)
+	}
+
+	return result
+}
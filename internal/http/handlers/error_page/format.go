@@ -1,12 +1,15 @@
 package error_page
 
 import (
+	"maps"
 	"math"
 	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/valyala/fasthttp"
+
+	"gh.tarampamp.am/error-pages/internal/config"
 )
 
 type preferredFormat = byte
@@ -19,6 +22,59 @@ const (
 	plainTextFormat                        // plain text
 )
 
+// formatsByExtension maps the URL file extensions to the response formats they force (e.g., `/404.json` will always
+// be rendered as JSON, regardless of the request headers). The `.html` and `.htm` extensions are accepted in the URL,
+// but do not force the format - the client headers are still used in that case (for backward compatibility). The
+// list is fixed, as well as the set of the response formats (custom formats can't be registered).
+var formatsByExtension = map[string]preferredFormat{ //nolint:gochecknoglobals
+	".html": unknownFormat,
+	".htm":  unknownFormat,
+	".json": jsonFormat,
+	".xml":  xmlFormat,
+	".txt":  plainTextFormat,
+}
+
+// configuredFormatsByExtension returns the [formatsByExtension] limited to the formats with the configured templates.
+// The extensions of the other formats are still accepted in the URL, but do not force the format (like `.html`).
+func configuredFormatsByExtension(cfg *config.Config) map[string]preferredFormat {
+	var (
+		result     = maps.Clone(formatsByExtension)
+		configured = map[preferredFormat]bool{
+			jsonFormat:      cfg.Formats.JSON != "",
+			xmlFormat:       cfg.Formats.XML != "",
+			plainTextFormat: cfg.Formats.PlainText != "",
+		}
+	)
+
+	for ext, f := range result {
+		if f != unknownFormat && !configured[f] {
+			result[ext] = unknownFormat
+		}
+	}
+
+	return result
+}
+
+// formatName returns a human-readable name of the format (used for tracing).
+func formatName(f preferredFormat) string {
+	switch f {
+	case jsonFormat:
+		return "json"
+	case xmlFormat:
+		return "xml"
+	case htmlFormat:
+		return "html"
+	}
+
+	return "plaintext"
+}
+
+// PreferredFormatName returns the name ("json", "xml", "html" or "plaintext") of the response format preferred by the
+// client, based on the request headers (the plain text is used if the format is not recognized).
+func PreferredFormatName(headers *fasthttp.RequestHeader) string {
+	return formatName(detectPreferredFormatForClient(headers))
+}
+
 // detectPreferredFormatForClient detects the preferred format for the client based on the headers.
 // It supports the following headers: Content-Type, Accept, X-Format.
 // If the headers are not set or the format is not recognized, it returns unknownFormat.
@@ -3,7 +3,10 @@ package error_page
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -11,20 +14,95 @@ import (
 	"github.com/valyala/fasthttp"
 
 	"gh.tarampamp.am/error-pages/internal/config"
+	"gh.tarampamp.am/error-pages/internal/http/middleware/requestid"
 	"gh.tarampamp.am/error-pages/internal/logger"
+	"gh.tarampamp.am/error-pages/internal/maintenance"
+	"gh.tarampamp.am/error-pages/internal/overrides"
+	"gh.tarampamp.am/error-pages/internal/statuspage"
+	"gh.tarampamp.am/error-pages/internal/suggest"
 	"gh.tarampamp.am/error-pages/internal/template"
+	"gh.tarampamp.am/error-pages/internal/tracing"
+	"gh.tarampamp.am/error-pages/templates"
 )
 
+type (
+	// Option allows to customize the handler.
+	Option func(*options)
+
+	options struct {
+		tracer    *tracing.Tracer
+		overrides *overrides.Dir
+
+		statusPage      *statuspage.Source
+		statusPageCodes config.Codes // used for the wildcards matching only
+
+		maintenance         *maintenance.Mode
+		maintenanceTemplate string // the name of the template to use during the maintenance (empty means the built-in)
+
+		suggestions      *suggest.Index
+		suggestionsLimit int
+	}
+)
+
+// WithTracer enables tracing - a span is created for each error page rendering, and the trace ID is exposed to the
+// templates.
+func WithTracer(t *tracing.Tracer) Option { return func(o *options) { o.tracer = t } }
+
+// WithOverrides enables the per-namespace customizations (template, codes descriptions, support link), selected by
+// the `X-Namespace` request header.
+func WithOverrides(d *overrides.Dir) Option { return func(o *options) { o.overrides = d } }
+
+// WithStatusPage exposes the current status page incident (if any) to the error pages with the given codes
+// (wildcards like "5xx" are supported).
+func WithStatusPage(s *statuspage.Source, codes []string) Option {
+	return func(o *options) {
+		o.statusPage, o.statusPageCodes = s, make(config.Codes, len(codes))
+
+		for _, code := range codes {
+			o.statusPageCodes[code] = config.CodeDescription{}
+		}
+	}
+}
+
+// WithMaintenance enables the maintenance mode support: while it's active, every error page is rendered with the
+// 503 code (and the same HTTP status), using the given template (empty means the built-in maintenance page, see
+// [templates.Maintenance]).
+func WithMaintenance(m *maintenance.Mode, templateName string) Option {
+	return func(o *options) { o.maintenance, o.maintenanceTemplate = m, templateName }
+}
+
+// WithSuggestions enables the "did you mean" suggestions: up to the limit of the known site URLs closest to the
+// original URI are exposed to the 404 error pages.
+func WithSuggestions(idx *suggest.Index, limit int) Option {
+	return func(o *options) { o.suggestions, o.suggestionsLimit = idx, limit }
+}
+
+// forcedCodeKey is the request context user value key for the forced error code (see [ForceCode]).
+type forcedCodeKey struct{}
+
+// ForceCode makes the handler render the error page for the given code instead of detecting it from the request,
+// and respond with the same HTTP status code. It's used when the error page is rendered on behalf of another
+// handler (e.g., the reverse proxy replacing the upstream error response).
+func ForceCode(ctx *fasthttp.RequestCtx, code uint16) { ctx.SetUserValue(forcedCodeKey{}, code) }
+
 // New creates a new handler that returns an error page with the specified status code and format.
-func New(cfg *config.Config, log *logger.Logger) (_ fasthttp.RequestHandler, closeCache func()) { //nolint:funlen,gocognit,gocyclo,lll
+func New(cfg *config.Config, log *logger.Logger, opts ...Option) (_ fasthttp.RequestHandler, closeCache func()) { //nolint:funlen,gocognit,gocyclo,lll
 	// if the ttl will be bigger than 1 second, the template functions like `nowUnix` will not work as expected
 	const cacheTtl = 900 * time.Millisecond // the cache TTL
 
 	var (
 		cache, stopCh = NewRenderedCache(cacheTtl), make(chan struct{})
 		stopOnce      sync.Once
+		opt           options
+
+		detailsHeaders = detailsHeadersFor(cfg.DetailsMapping)
+		extensions     = configuredFormatsByExtension(cfg)
 	)
 
+	for _, o := range opts {
+		o(&opt)
+	}
+
 	// run a goroutine that will clear the cache from expired items. to stop the goroutine - close the stop channel
 	// or call the closeCache
 	go func() {
@@ -46,26 +124,70 @@ func New(cfg *config.Config, log *logger.Logger) (_ fasthttp.RequestHandler, clo
 	return func(ctx *fasthttp.RequestCtx) {
 		var (
 			reqHeaders = &ctx.Request.Header
+			path       = strings.TrimPrefix(string(ctx.Path()), cfg.BasePath)
 			code       uint16
+			format     preferredFormat
+			cacheHit   bool   // is the content taken from the cache?
+			usedTpl    string // the name of the template used for rendering
+			traceID    string
 		)
 
-		if fromUrl, okUrl := extractCodeFromURL(string(ctx.Path())); okUrl {
-			code = fromUrl
-		} else if fromHeader, okHeaders := extractCodeFromHeaders(reqHeaders); okHeaders {
-			code = fromHeader
+		if opt.tracer != nil {
+			// continue the incoming W3C trace context (if any)
+			var (
+				parent, _ = tracing.ParseTraceparent(
+					string(reqHeaders.Peek("Traceparent")),
+					string(reqHeaders.Peek("Tracestate")),
+				)
+				span = opt.tracer.Start("error_page.render", parent)
+			)
+
+			traceID = span.Context().TraceID.String()
+
+			defer func() {
+				span.SetAttributes(
+					tracing.Int("http.response.status_code", ctx.Response.StatusCode()),
+					tracing.Int("error_page.code", int(code)),
+					tracing.String("error_page.format", formatName(format)),
+					tracing.String("error_page.template", usedTpl),
+					tracing.Bool("error_page.cache_hit", cacheHit),
+				)
+				span.End()
+			}()
+		}
+
+		var forced, isForced = ctx.UserValue(forcedCodeKey{}).(uint16)
+
+		if isForced {
+			code = forced
+		} else if fromUrl, formatFromUrl, okUrl := extractCodeFromURL(path, extensions); okUrl {
+			code, format = fromUrl, formatFromUrl
+		} else if fromRequest, okRequest := extractCodeFromRequest(&ctx.Request, cfg.CodeSources); okRequest {
+			code = fromRequest
 		} else {
 			code = cfg.DefaultCodeToRender
 		}
 
+		var maintenanceEnd, inMaintenance = time.Time{}, false
+
+		if opt.maintenance != nil {
+			if maintenanceEnd, inMaintenance = opt.maintenance.Active(); inMaintenance {
+				code = http.StatusServiceUnavailable // every error page is the maintenance one
+			}
+		}
+
 		var httpCode int
 
-		if cfg.RespondWithSameHTTPCode {
+		if cfg.RespondWithSameHTTPCode || isForced || inMaintenance {
 			httpCode = int(code)
 		} else {
 			httpCode = http.StatusOK
 		}
 
-		var format = detectPreferredFormatForClient(reqHeaders)
+		// the format forced by the URL extension (e.g., `/404.json`) takes precedence over the client headers
+		if format == unknownFormat {
+			format = detectPreferredFormatForClient(reqHeaders)
+		}
 
 		{ // deal with the headers
 			switch format {
@@ -83,13 +205,13 @@ func New(cfg *config.Config, log *logger.Logger) (_ fasthttp.RequestHandler, clo
 			// disallow indexing of the error pages
 			ctx.Response.Header.Set("X-Robots-Tag", "noindex")
 
-			switch code {
-			case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
-				http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable,
-				http.StatusGatewayTimeout:
-				// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Retry-After
-				// tell the client (search crawler) to retry the request after 120 seconds
-				ctx.Response.Header.Set("Retry-After", "120")
+			if retryAfter := RetryAfter(code); retryAfter != "" {
+				ctx.Response.Header.Set("Retry-After", retryAfter)
+			}
+
+			// during the maintenance, the client should retry after its expected end
+			if inMaintenance && !maintenanceEnd.IsZero() {
+				ctx.Response.Header.Set("Retry-After", strconv.FormatInt(secondsUntil(maintenanceEnd), 10))
 			}
 
 			// proxy the headers from the incoming request to the error page response if they are defined in the config
@@ -102,27 +224,79 @@ func New(cfg *config.Config, log *logger.Logger) (_ fasthttp.RequestHandler, clo
 
 		ctx.SetStatusCode(httpCode)
 
+		// the namespace overrides (if configured), selected by the ingress-nginx namespace header
+		var (
+			namespace = string(reqHeaders.Peek("X-Namespace"))
+			override  overrides.Override
+		)
+
+		if opt.overrides != nil {
+			override, _ = opt.overrides.Get(namespace)
+		}
+
 		// prepare the template properties for rendering
 		var tplProps = template.Props{
-			Code:               code,             // http status code
-			ShowRequestDetails: cfg.ShowDetails,  // status message
-			L10nDisabled:       cfg.L10n.Disable, // status description
+			Code:               code,                // http status code
+			ShowRequestDetails: cfg.ShowDetails,     // status message
+			L10nDisabled:       cfg.L10n.Disable,    // status description
+			BasePath:           cfg.BasePath,        // URL path prefix for the links generation
+			TraceID:            traceID,             // empty if tracing is disabled
+			SupportURL:         override.SupportURL, // empty if not overridden for the namespace
+			Maintenance:        inMaintenance,       // the maintenance mode is active
+			// unique ID that identifies the request (provided by the proxy or generated by the request ID middleware)
+			RequestID: cfg.Redaction.Redact(requestid.Header, string(reqHeaders.Peek(requestid.Header))),
 		}
 
-		//nolint:lll
-		if cfg.ShowDetails { // https://kubernetes.github.io/ingress-nginx/user-guide/custom-errors/
-			tplProps.OriginalURI = string(reqHeaders.Peek("X-Original-URI"))   // (ingress-nginx) URI that caused the error
-			tplProps.Namespace = string(reqHeaders.Peek("X-Namespace"))        // (ingress-nginx) namespace where the backend Service is located
-			tplProps.IngressName = string(reqHeaders.Peek("X-Ingress-Name"))   // (ingress-nginx) name of the Ingress where the backend is defined
-			tplProps.ServiceName = string(reqHeaders.Peek("X-Service-Name"))   // (ingress-nginx) name of the Service backing the backend
-			tplProps.ServicePort = string(reqHeaders.Peek("X-Service-Port"))   // (ingress-nginx) port number of the Service backing the backend
-			tplProps.RequestID = string(reqHeaders.Peek("X-Request-Id"))       // (ingress-nginx) unique ID that identifies the request - same as for backend service
-			tplProps.ForwardedFor = string(reqHeaders.Peek("X-Forwarded-For")) // the value of the `X-Forwarded-For` header
-			tplProps.Host = string(reqHeaders.Peek("Host"))                    // the value of the `Host` header
+		if inMaintenance && !maintenanceEnd.IsZero() {
+			tplProps.MaintenanceEnd = maintenanceEnd.UTC().Format(time.RFC3339)
+			tplProps.MaintenanceLeft = secondsUntil(maintenanceEnd)
 		}
 
-		// try to find the code message and description in the config and if not - use the standard status text or fallback
-		if desc, found := cfg.Codes.Find(code); found {
+		// the current status page incident (if any) for the configured codes
+		if opt.statusPage != nil {
+			if _, match := opt.statusPageCodes.Find(code); match {
+				if incident, found := opt.statusPage.Current(); found {
+					tplProps.IncidentTitle = incident.Title
+					tplProps.IncidentBody = incident.Body
+					tplProps.IncidentURL = incident.URL
+				}
+			}
+		}
+
+		// the known site URLs closest to the missing one (regardless of the details showing, since the original URI
+		// itself is not exposed)
+		if opt.suggestions != nil && code == http.StatusNotFound {
+			var uri = firstValue(detailsHeaders.originalURI, func(name string) string {
+				return string(reqHeaders.Peek(name))
+			})
+
+			if uri != "" {
+				tplProps.Suggestions = opt.suggestions.Suggest(uri, opt.suggestionsLimit)
+			}
+		}
+
+		if cfg.ShowDetails { // the headers depend on the proxy in front of the server (ingress-nginx, envoy, etc.)
+			// the sensitive values (if configured) are masked the same way as in the logs
+			var peek = func(name string) string { return cfg.Redaction.Redact(name, string(reqHeaders.Peek(name))) }
+
+			detailsHeaders.fill(&tplProps, peek)
+
+			// the additional details (in the configured order, so the cache key is stable)
+			if len(cfg.DetailHeaders) > 0 {
+				tplProps.Details = make([]template.Detail, len(cfg.DetailHeaders))
+
+				for i, d := range cfg.DetailHeaders {
+					tplProps.Details[i] = template.Detail{Name: d.Name, Value: peek(d.Header)}
+				}
+			}
+		}
+
+		// try to find the code message and description in the namespace override and config, and if not - use the
+		// standard status text or fallback
+		if desc, found := override.Codes.Find(code); found {
+			tplProps.Message = desc.Message
+			tplProps.Description = desc.Description
+		} else if desc, found = cfg.Codes.Find(code); found {
 			tplProps.Message = desc.Message
 			tplProps.Description = desc.Description
 		} else if stdlibStatusText := http.StatusText(int(code)); stdlibStatusText != "" {
@@ -131,44 +305,81 @@ func New(cfg *config.Config, log *logger.Logger) (_ fasthttp.RequestHandler, clo
 			tplProps.Message = "Unknown Status Code" // fallback
 		}
 
+		// the per-request values are rendered as the placeholders (and filled in afterward), so they don't affect the
+		// cache key, and the rendered content is reused for other requests
+		var renderProps, fill, cacheable = withPlaceholders(tplProps)
+
+		// store puts the rendered content into the cache (unless it contains the per-request values)
+		var store = func(tpl, content string) {
+			if cacheable {
+				cache.Put(tpl, renderProps, []byte(content))
+			}
+		}
+
 		switch {
 		case format == jsonFormat && cfg.Formats.JSON != "":
-			if cached, ok := cache.Get(cfg.Formats.JSON, tplProps); ok { // cache hit
-				write(ctx, log, cached)
+			usedTpl = "json"
+
+			if cached, ok := cache.Get(cfg.Formats.JSON, renderProps); ok { // cache hit
+				cacheHit = true
+
+				write(ctx, log, fill(cached))
 			} else { // cache miss
-				if content, err := template.Render(cfg.Formats.JSON, tplProps); err != nil {
+				if content, err := template.Render(cfg.Formats.JSON, renderProps); err != nil {
 					errAsJson, _ := json.Marshal(fmt.Sprintf("Failed to render the JSON template: %s", err.Error()))
 					write(ctx, log, errAsJson) // error during rendering
 				} else {
-					cache.Put(cfg.Formats.JSON, tplProps, []byte(content))
+					store(cfg.Formats.JSON, content)
 
-					write(ctx, log, content) // rendered successfully
+					write(ctx, log, fill([]byte(content))) // rendered successfully
 				}
 			}
 
 		case format == xmlFormat && cfg.Formats.XML != "":
-			if cached, ok := cache.Get(cfg.Formats.XML, tplProps); ok { // cache hit
-				write(ctx, log, cached)
+			usedTpl = "xml"
+
+			if cached, ok := cache.Get(cfg.Formats.XML, renderProps); ok { // cache hit
+				cacheHit = true
+
+				write(ctx, log, fill(cached))
 			} else { // cache miss
-				if content, err := template.Render(cfg.Formats.XML, tplProps); err != nil {
+				if content, err := template.Render(cfg.Formats.XML, renderProps); err != nil {
 					write(ctx, log, fmt.Sprintf(
 						"<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<error>Failed to render the XML template: %s</error>\n", err.Error(),
 					))
 				} else {
-					cache.Put(cfg.Formats.XML, tplProps, []byte(content))
+					store(cfg.Formats.XML, content)
 
-					write(ctx, log, content)
+					write(ctx, log, fill([]byte(content)))
 				}
 			}
 
 		case format == htmlFormat:
 			var templateName = templateToUse(cfg)
 
-			if tpl, found := cfg.Templates.Get(templateName); found { //nolint:nestif
-				if cached, ok := cache.Get(tpl, tplProps); ok { // cache hit
-					write(ctx, log, cached)
+			tpl, found := cfg.Templates.Get(templateName)
+
+			if override.Template != "" { // the namespace template takes precedence
+				templateName, tpl, found = namespace+" namespace", override.Template, true
+			}
+
+			if inMaintenance { // and the maintenance template - over everything
+				if templateName = opt.maintenanceTemplate; templateName != "" {
+					tpl, found = cfg.Templates.Get(templateName)
+				} else {
+					templateName, tpl, found = "maintenance", templates.Maintenance(), true
+				}
+			}
+
+			usedTpl = templateName
+
+			if found { //nolint:nestif
+				if cached, ok := cache.Get(tpl, renderProps); ok { // cache hit
+					cacheHit = true
+
+					write(ctx, log, fill(cached))
 				} else { // cache miss
-					if content, err := template.Render(tpl, tplProps); err != nil {
+					if content, err := template.Render(tpl, renderProps); err != nil {
 						// TODO: add GZIP compression for the HTML content support
 						write(ctx, log, fmt.Sprintf(
 							"<!DOCTYPE html>\n<html><body>Failed to render the HTML template %s: %s</body></html>\n",
@@ -184,9 +395,9 @@ func New(cfg *config.Config, log *logger.Logger) (_ fasthttp.RequestHandler, clo
 							}
 						}
 
-						cache.Put(tpl, tplProps, []byte(content))
+						store(tpl, content)
 
-						write(ctx, log, content)
+						write(ctx, log, fill([]byte(content)))
 					}
 				}
 			} else {
@@ -197,15 +408,19 @@ func New(cfg *config.Config, log *logger.Logger) (_ fasthttp.RequestHandler, clo
 
 		default: // plainTextFormat as default
 			if cfg.Formats.PlainText != "" { //nolint:nestif
-				if cached, ok := cache.Get(cfg.Formats.PlainText, tplProps); ok { // cache hit
-					write(ctx, log, cached)
+				usedTpl = "plaintext"
+
+				if cached, ok := cache.Get(cfg.Formats.PlainText, renderProps); ok { // cache hit
+					cacheHit = true
+
+					write(ctx, log, fill(cached))
 				} else { // cache miss
-					if content, err := template.Render(cfg.Formats.PlainText, tplProps); err != nil {
+					if content, err := template.Render(cfg.Formats.PlainText, renderProps); err != nil {
 						write(ctx, log, fmt.Sprintf("Failed to render the PlainText template: %s", err.Error()))
 					} else {
-						cache.Put(cfg.Formats.PlainText, tplProps, []byte(content))
+						store(cfg.Formats.PlainText, content)
 
-						write(ctx, log, content)
+						write(ctx, log, fill([]byte(content)))
 					}
 				}
 			} else {
@@ -219,6 +434,11 @@ Supported formats: JSON, XML, HTML, Plain Text
 	}, func() { stopOnce.Do(func() { close(stopCh) }) }
 }
 
+// secondsUntil returns the number of seconds (rounded up, at least 1) until the given time.
+func secondsUntil(t time.Time) int64 {
+	return max(int64(math.Ceil(time.Until(t).Seconds())), 1)
+}
+
 var (
 	templateChangedAt atomic.Pointer[time.Time] //nolint:gochecknoglobals // the time when the theme was changed last time
 	pickedTemplate    atomic.Pointer[string]    //nolint:gochecknoglobals // the name of the randomly picked template
@@ -1,16 +1,28 @@
 package error_page_test
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
+	stdHttpTest "net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
 
 	"gh.tarampamp.am/error-pages/internal/config"
 	"gh.tarampamp.am/error-pages/internal/http/handlers/error_page"
 	"gh.tarampamp.am/error-pages/internal/http/httptest"
 	"gh.tarampamp.am/error-pages/internal/logger"
+	"gh.tarampamp.am/error-pages/internal/maintenance"
+	"gh.tarampamp.am/error-pages/internal/overrides"
+	"gh.tarampamp.am/error-pages/internal/statuspage"
+	"gh.tarampamp.am/error-pages/internal/suggest"
+	"gh.tarampamp.am/error-pages/internal/tracing"
 )
 
 func TestHandler(t *testing.T) {
@@ -89,6 +101,76 @@ func TestHandler(t *testing.T) {
 			},
 			wantBodyIncludes: []string{"500", "Internal Server Error"},
 		},
+		"format forced by the URL extension, json": {
+			giveConfig:  func() *config.Config { cfg := config.New(); return &cfg },
+			giveUrl:     "http://testing/502.json",
+			giveHeaders: map[string]string{"Accept": "text/html", "X-Code": "404"},
+
+			wantStatusCode:   http.StatusOK,
+			wantHeaders:      map[string]string{"Content-Type": "application/json; charset=utf-8"},
+			wantBodyIncludes: []string{`"code": 502`, "Bad Gateway"},
+		},
+		"format forced by the URL extension, xml": {
+			giveConfig:  func() *config.Config { cfg := config.New(); return &cfg },
+			giveUrl:     "http://testing/403.XML",
+			giveHeaders: map[string]string{"Content-Type": "application/json"},
+
+			wantStatusCode:   http.StatusOK,
+			wantHeaders:      map[string]string{"Content-Type": "application/xml; charset=utf-8"},
+			wantBodyIncludes: []string{"<code>403</code>", "Forbidden"},
+		},
+		"format forced by the URL extension, plain text": {
+			giveConfig:  func() *config.Config { cfg := config.New(); return &cfg },
+			giveUrl:     "http://testing/500.txt",
+			giveHeaders: map[string]string{"Accept": "application/json"},
+
+			wantStatusCode:   http.StatusOK,
+			wantHeaders:      map[string]string{"Content-Type": "text/plain; charset=utf-8"},
+			wantBodyIncludes: []string{"Error 500", "Internal Server Error"},
+		},
+		"format not forced by the URL extension without the template": {
+			giveConfig:  func() *config.Config { cfg := config.New(); cfg.Formats.JSON = ""; return &cfg },
+			giveUrl:     "http://testing/502.json",
+			giveHeaders: map[string]string{"Accept": "text/plain"},
+
+			wantStatusCode:   http.StatusOK,
+			wantHeaders:      map[string]string{"Content-Type": "text/plain; charset=utf-8"},
+			wantBodyIncludes: []string{"Error 502", "Bad Gateway"},
+		},
+		"code sources in priority order": {
+			giveConfig: func() *config.Config {
+				cfg := config.New()
+
+				cfg.CodeSources = []config.CodeSource{
+					{Kind: config.CodeSourceQuery, Name: "status"},
+					{Kind: config.CodeSourceHeader, Name: "X-Status-Code"},
+					{Kind: config.CodeSourceHeader, Name: "X-Code"},
+				}
+
+				return &cfg
+			},
+			giveUrl:     "http://testing/?status=foo",
+			giveHeaders: map[string]string{"Accept": "application/json", "X-Status-Code": "502", "X-Code": "404"},
+
+			wantStatusCode:   http.StatusOK,
+			wantHeaders:      map[string]string{"Content-Type": "application/json; charset=utf-8"},
+			wantBodyIncludes: []string{`"code": 502`, "Bad Gateway"},
+		},
+		"code in the query parameter": {
+			giveConfig: func() *config.Config {
+				cfg := config.New()
+
+				cfg.CodeSources = []config.CodeSource{{Kind: config.CodeSourceQuery, Name: "status"}}
+
+				return &cfg
+			},
+			giveUrl:     "http://testing/?status=503",
+			giveHeaders: map[string]string{"Accept": "application/json", "X-Code": "404"},
+
+			wantStatusCode:   http.StatusOK,
+			wantHeaders:      map[string]string{"Content-Type": "application/json; charset=utf-8"},
+			wantBodyIncludes: []string{`"code": 503`, "Service Unavailable"},
+		},
 		"show details": {
 			giveConfig: func() *config.Config {
 				cfg := config.New()
@@ -125,6 +207,175 @@ func TestHandler(t *testing.T) {
 				"example.com",
 			},
 		},
+		"show details with redaction": {
+			giveConfig: func() *config.Config {
+				cfg := config.New()
+
+				cfg.ShowDetails = true
+				cfg.Redaction = config.Redaction{Headers: []string{"X-Forwarded-For", "x-original-uri"}, KeepChars: 3}
+
+				return &cfg
+			},
+			giveUrl: "http://example.com/503",
+			giveHeaders: map[string]string{
+				"Accept":          "application/json",
+				"X-Original-URI":  "/foo/bar?token=secret",
+				"X-Request-ID":    "req-id-777",
+				"X-Forwarded-For": "123.123.123.123",
+			},
+
+			wantStatusCode: http.StatusOK,
+			wantHeaders:    map[string]string{"Content-Type": "application/json; charset=utf-8"},
+			wantBodyIncludes: []string{
+				`"original_uri": "/fo****"`,
+				`"forwarded_for": "123****"`,
+				`"request_id": "req-id-777"`,
+			},
+		},
+		"show details, envoy headers (auto mapping)": {
+			giveConfig: func() *config.Config {
+				cfg := config.New()
+
+				cfg.ShowDetails = true
+				cfg.Formats.PlainText = "{{ original_uri }}|{{ forwarded_for }}|{{ host }}|{{ upstream_service_time }}"
+
+				return &cfg
+			},
+			giveUrl: "http://example.com/503",
+			giveHeaders: map[string]string{
+				"Accept":                        "text/plain",
+				"X-Envoy-Original-Path":         "/foo/bar",
+				"X-Envoy-External-Address":      "10.0.0.1",
+				"X-Envoy-Upstream-Service-Time": "42",
+			},
+
+			wantStatusCode:   http.StatusOK,
+			wantHeaders:      map[string]string{"Content-Type": "text/plain; charset=utf-8"},
+			wantBodyIncludes: []string{"/foo/bar|10.0.0.1|example.com|42"},
+		},
+		"show details, envoy headers, default format": {
+			giveConfig: func() *config.Config {
+				cfg := config.New()
+
+				cfg.ShowDetails = true
+
+				return &cfg
+			},
+			giveUrl: "http://example.com/503",
+			giveHeaders: map[string]string{
+				"Accept":                        "application/json",
+				"X-Envoy-Upstream-Service-Time": "42",
+			},
+
+			wantStatusCode:   http.StatusOK,
+			wantHeaders:      map[string]string{"Content-Type": "application/json; charset=utf-8"},
+			wantBodyIncludes: []string{`"upstream_service_time": "42"`},
+		},
+		"envoy mapping, the code is taken from the URL path": {
+			giveConfig: func() *config.Config {
+				cfg := config.New()
+
+				cfg.DetailsMapping = config.DetailsMappingEnvoy
+				cfg.Formats.PlainText = "{{ code }}"
+
+				return &cfg
+			},
+			giveUrl: "http://example.com/502", // the `custom_response` redirect policy URI
+			giveHeaders: map[string]string{
+				"Accept":                "text/plain",
+				"X-Envoy-Original-Path": "/foo/bar",
+			},
+
+			wantStatusCode:   http.StatusOK,
+			wantHeaders:      map[string]string{"Content-Type": "text/plain; charset=utf-8"},
+			wantBodyIncludes: []string{"502"},
+		},
+		"show details, traefik headers": {
+			giveConfig: func() *config.Config {
+				cfg := config.New()
+
+				cfg.ShowDetails = true
+				cfg.DetailsMapping = config.DetailsMappingTraefik
+
+				return &cfg
+			},
+			giveUrl: "http://error-pages/503",
+			giveHeaders: map[string]string{
+				"Accept":           "application/json",
+				"X-Original-URI":   "/ignored", // ingress-nginx header is not used
+				"X-Forwarded-Uri":  "/foo/bar",
+				"X-Forwarded-Host": "example.com",
+				"X-Real-Ip":        "10.0.0.2",
+			},
+
+			wantStatusCode: http.StatusOK,
+			wantHeaders:    map[string]string{"Content-Type": "application/json; charset=utf-8"},
+			wantBodyIncludes: []string{
+				`"host": "example.com"`,
+				`"original_uri": "/foo/bar"`,
+				`"forwarded_for": "10.0.0.2"`,
+			},
+		},
+		"show details, custom detail headers (json)": {
+			giveConfig: func() *config.Config {
+				cfg := config.New()
+
+				cfg.ShowDetails = true
+				cfg.DetailHeaders = []config.DetailHeader{
+					{Name: "cluster", Header: "X-Cluster-Name"},
+					{Name: "pod", Header: "X-Pod"},
+				}
+
+				return &cfg
+			},
+			giveUrl:     "http://testing/503",
+			giveHeaders: map[string]string{"Accept": "application/json", "X-Cluster-Name": "eu-west-1"},
+
+			wantStatusCode:   http.StatusOK,
+			wantHeaders:      map[string]string{"Content-Type": "application/json; charset=utf-8"},
+			wantBodyIncludes: []string{`"cluster": "eu-west-1",`, `"pod": "",`},
+		},
+		"show details, custom detail headers (xml)": {
+			giveConfig: func() *config.Config {
+				cfg := config.New()
+
+				cfg.ShowDetails = true
+				cfg.DetailHeaders = []config.DetailHeader{{Name: "cluster", Header: "X-Cluster-Name"}}
+
+				return &cfg
+			},
+			giveUrl:     "http://testing/503.xml",
+			giveHeaders: map[string]string{"X-Cluster-Name": "eu-west-1 <b>&</b>"},
+
+			wantStatusCode:   http.StatusOK,
+			wantHeaders:      map[string]string{"Content-Type": "application/xml; charset=utf-8"},
+			wantBodyIncludes: []string{`<detail name="cluster">eu-west-1 &lt;b&gt;&amp;&lt;/b&gt;</detail>`},
+		},
+		"show details, custom detail headers (plain text)": {
+			giveConfig: func() *config.Config {
+				cfg := config.New()
+
+				cfg.ShowDetails = true
+				cfg.DetailHeaders = []config.DetailHeader{{Name: "cluster", Header: "X-Cluster-Name"}}
+
+				return &cfg
+			},
+			giveUrl:     "http://testing/503.txt",
+			giveHeaders: map[string]string{"X-Cluster-Name": "eu-west-1"},
+
+			wantStatusCode:   http.StatusOK,
+			wantHeaders:      map[string]string{"Content-Type": "text/plain; charset=utf-8"},
+			wantBodyIncludes: []string{"\ncluster: eu-west-1\nTimestamp: "},
+		},
+		"request id without details": {
+			giveConfig:  func() *config.Config { cfg := config.New(); return &cfg },
+			giveUrl:     "http://testing/502.xml",
+			giveHeaders: map[string]string{"X-Request-Id": "req-id-888"},
+
+			wantStatusCode:   http.StatusOK,
+			wantHeaders:      map[string]string{"Content-Type": "application/xml; charset=utf-8"},
+			wantBodyIncludes: []string{"<requestID>req-id-888</requestID>"},
+		},
 		"fallback to StatusText if code is not found": {
 			giveConfig: func() *config.Config {
 				cfg := config.New()
@@ -224,3 +475,460 @@ func TestRotationModeOnEachRequest(t *testing.T) {
 
 	assert.True(t, changedTimes > 30, "the template should be changed at least 30 times")
 }
+
+func TestForceCode(t *testing.T) {
+	t.Parallel()
+
+	var (
+		cfg                 = config.New()
+		handler, closeCache = error_page.New(&cfg, logger.NewNop())
+	)
+
+	defer closeCache()
+
+	req, reqErr := http.NewRequest(http.MethodGet, "http://testing/404.html", http.NoBody) // the URL code is ignored
+	require.NoError(t, reqErr)
+
+	req.Header.Set("Accept", "text/plain")
+	req.Header.Set("X-Code", "401") // ignored too
+
+	httptest.HandleFastRequest(t, func(ctx *fasthttp.RequestCtx) {
+		error_page.ForceCode(ctx, http.StatusBadGateway)
+		handler(ctx)
+	}, req, func(status int, body string, _ http.Header) {
+		assert.Equal(t, http.StatusBadGateway, status) // even if the same HTTP code responding is disabled
+		assert.Contains(t, body, "Error 502: Bad Gateway")
+	})
+}
+
+func TestHandler_Overrides(t *testing.T) {
+	t.Parallel()
+
+	var dir = t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team-a.html"), []byte("team-a {{ code }}: {{ message }}"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team-a.json"), []byte(`{
+		"codes": {"5xx": {"message": "Our service is down"}},
+		"support_url": "https://example.com/support?team=a&lang=en"
+	}`), 0o600))
+
+	var cfg = config.New()
+
+	cfg.TemplateName = "ghost"
+
+	d, err := overrides.Load(dir, cfg.Templates, logger.NewNop())
+	require.NoError(t, err)
+
+	var handler, closeCache = error_page.New(&cfg, logger.NewNop(), error_page.WithOverrides(d))
+
+	defer closeCache()
+
+	for name, tt := range map[string]struct {
+		giveUrl, giveNamespace, giveAccept string
+		wantBody                           []string
+		wantNotBody                        []string
+	}{
+		"html": {
+			giveUrl: "http://testing/503", giveNamespace: "team-a", giveAccept: "text/html",
+			wantBody: []string{"team-a 503: Our service is down"},
+		},
+		"json": {
+			giveUrl: "http://testing/502", giveNamespace: "team-a", giveAccept: "application/json",
+			wantBody: []string{`"message": "Our service is down"`, `"support_url": "https://example.com/support?team=a\u0026lang=en"`},
+		},
+		"xml": {
+			giveUrl: "http://testing/502", giveNamespace: "team-a", giveAccept: "application/xml",
+			wantBody: []string{`<supportURL>https://example.com/support?team=a&amp;lang=en</supportURL>`},
+		},
+		"global codes fallback": {
+			giveUrl: "http://testing/404", giveNamespace: "team-a", giveAccept: "text/plain",
+			wantBody: []string{"Error 404: Not Found", "Support: https://example.com/support?team=a&lang=en"},
+		},
+		"another namespace": {
+			giveUrl: "http://testing/503", giveNamespace: "team-b", giveAccept: "text/html",
+			wantBody:    []string{"Service Unavailable"},
+			wantNotBody: []string{"team-a", "Our service is down"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req, reqErr := http.NewRequest(http.MethodGet, tt.giveUrl, http.NoBody)
+			require.NoError(t, reqErr)
+
+			req.Header.Set("Accept", tt.giveAccept)
+			req.Header.Set("X-Namespace", tt.giveNamespace)
+
+			httptest.HandleFastRequest(t, handler, req, func(_ int, body string, _ http.Header) {
+				for _, want := range tt.wantBody {
+					assert.Contains(t, body, want)
+				}
+
+				for _, notWant := range tt.wantNotBody {
+					assert.NotContains(t, body, notWant)
+				}
+			})
+		})
+	}
+}
+
+func TestHandler_StatusPage(t *testing.T) {
+	t.Parallel()
+
+	var statusSrv = stdHttpTest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"incidents": [{"name": "Database <outage>", "status": "identified",
+			"shortlink": "https://stspg.io/db", "incident_updates": [{"body": "We are failing over"}]}]}`))
+	}))
+
+	t.Cleanup(statusSrv.Close)
+
+	var (
+		cfg    = config.New()
+		source = statuspage.New(statusSrv.URL, logger.NewNop())
+	)
+
+	require.NoError(t, source.Refresh(context.Background()))
+
+	var handler, closeCache = error_page.New(&cfg, logger.NewNop(),
+		error_page.WithStatusPage(source, []string{"502", "503"}),
+	)
+
+	defer closeCache()
+
+	for name, tt := range map[string]struct {
+		giveUrl, giveAccept string
+		wantBody            []string
+		wantNotBody         []string
+	}{
+		"json": {
+			giveUrl: "http://testing/503", giveAccept: "application/json",
+			wantBody: []string{
+				`"title": "Database \u003coutage\u003e"`,
+				`"body": "We are failing over"`,
+				`"url": "https://stspg.io/db"`,
+			},
+		},
+		"xml": {
+			giveUrl: "http://testing/502", giveAccept: "application/xml",
+			wantBody: []string{"<title>Database &lt;outage&gt;</title>", "<url>https://stspg.io/db</url>"},
+		},
+		"plain text": {
+			giveUrl: "http://testing/502", giveAccept: "text/plain",
+			wantBody: []string{"Incident: Database <outage>\nWe are failing over\nhttps://stspg.io/db"},
+		},
+		"not configured code": {
+			giveUrl: "http://testing/500", giveAccept: "application/json",
+			wantNotBody: []string{"incident", "Database"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req, reqErr := http.NewRequest(http.MethodGet, tt.giveUrl, http.NoBody)
+			require.NoError(t, reqErr)
+
+			req.Header.Set("Accept", tt.giveAccept)
+
+			httptest.HandleFastRequest(t, handler, req, func(_ int, body string, _ http.Header) {
+				for _, want := range tt.wantBody {
+					assert.Contains(t, body, want)
+				}
+
+				for _, notWant := range tt.wantNotBody {
+					assert.NotContains(t, body, notWant)
+				}
+			})
+		})
+	}
+
+	// the status page failures do not break the rendering
+	statusSrv.Close()
+
+	require.Error(t, source.Refresh(context.Background()))
+
+	httptest.HandleFast(t, handler, http.MethodGet, "http://testing/503", nil, func(code int, body string, _ http.Header) {
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, body, "Database") // the last known incident is kept
+	})
+}
+
+func TestHandler_Maintenance(t *testing.T) {
+	t.Parallel()
+
+	var cfg = config.New()
+
+	require.NoError(t, cfg.Templates.Add("maintenance", "{{ code }}: back in {{ maintenance_countdown }}s"))
+
+	var (
+		now  = time.Now()
+		mode = maintenance.New(maintenance.WithWindow(now.Add(-time.Hour), now.Add(time.Hour)))
+	)
+
+	var handler, closeCache = error_page.New(&cfg, logger.NewNop(), error_page.WithMaintenance(mode, "maintenance"))
+
+	defer closeCache()
+
+	req, err := http.NewRequest(http.MethodGet, "http://testing/404", http.NoBody)
+	require.NoError(t, err)
+
+	req.Header.Set("Accept", "text/html")
+
+	httptest.HandleFastRequest(t, handler, req, func(code int, body string, headers http.Header) {
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Regexp(t, `^503: back in 3[56]\d\ds$`, body)
+		assert.Regexp(t, `^3[56]\d\d$`, headers.Get("Retry-After"))
+	})
+
+	mode.Enable(time.Time{}) // the end is unknown
+
+	req.Header.Set("Accept", "text/plain")
+
+	httptest.HandleFastRequest(t, handler, req, func(code int, body string, headers http.Header) {
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Contains(t, body, "Error 503: Service Unavailable")
+		assert.Contains(t, body, "Scheduled maintenance\n")
+		assert.Equal(t, "120", headers.Get("Retry-After")) // the default one
+	})
+
+	t.Run("built-in template", func(t *testing.T) {
+		var builtIn, closeBuiltIn = error_page.New(&cfg, logger.NewNop(), error_page.WithMaintenance(mode, ""))
+
+		defer closeBuiltIn()
+
+		mode.Enable(now.Add(time.Hour))
+
+		req.Header.Set("Accept", "text/html")
+
+		httptest.HandleFastRequest(t, builtIn, req, func(code int, body string, _ http.Header) {
+			assert.Equal(t, http.StatusServiceUnavailable, code)
+			assert.Contains(t, body, "We are performing the scheduled maintenance")
+			assert.Regexp(t, `data-left="?3[56]\d\d"?`, body)
+			assert.NotContains(t, body, "back in") // not the configured one
+		})
+	})
+}
+
+func TestHandler_Suggestions(t *testing.T) {
+	t.Parallel()
+
+	var (
+		cfg  = config.New()
+		file = filepath.Join(t.TempDir(), "urls.txt")
+	)
+
+	require.NoError(t, os.WriteFile(file, []byte("/products/shoes\n/products/shirts\n/about-us\n"), 0o600))
+
+	var idx = suggest.New(file, logger.NewNop())
+
+	require.NoError(t, idx.Refresh(context.Background()))
+
+	var handler, closeCache = error_page.New(&cfg, logger.NewNop(), error_page.WithSuggestions(idx, 1))
+
+	defer closeCache()
+
+	for name, tt := range map[string]struct {
+		giveUrl, giveAccept string
+		giveHeaders         map[string]string
+		wantBody            []string
+		wantNotBody         []string
+	}{
+		"json": {
+			giveUrl: "http://testing/404", giveAccept: "application/json",
+			giveHeaders: map[string]string{"X-Original-URI": "/prodcuts/shoes"},
+			wantBody:    []string{`"suggestions": ["/products/shoes"]`},
+		},
+		"xml": {
+			giveUrl: "http://testing/404", giveAccept: "application/xml",
+			giveHeaders: map[string]string{"X-Forwarded-Uri": "/About_Us"}, // traefik
+			wantBody:    []string{"<suggestions>\n    <url>/about-us</url>\n  </suggestions>"},
+		},
+		"plain text": {
+			giveUrl: "http://testing/404", giveAccept: "text/plain",
+			giveHeaders: map[string]string{"X-Original-URI": "/product/shoes"},
+			wantBody:    []string{"Maybe you were looking for:\n- /products/shoes"},
+		},
+		"not 404": {
+			giveUrl: "http://testing/500", giveAccept: "application/json",
+			giveHeaders: map[string]string{"X-Original-URI": "/prodcuts/shoes"},
+			wantNotBody: []string{"suggestions"},
+		},
+		"no original URI": {
+			giveUrl: "http://testing/404", giveAccept: "application/json",
+			wantNotBody: []string{"suggestions"},
+		},
+		"nothing similar": {
+			giveUrl: "http://testing/404", giveAccept: "application/json",
+			giveHeaders: map[string]string{"X-Original-URI": "/wp-login.php"},
+			wantNotBody: []string{"suggestions"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req, reqErr := http.NewRequest(http.MethodGet, tt.giveUrl, http.NoBody)
+			require.NoError(t, reqErr)
+
+			req.Header.Set("Accept", tt.giveAccept)
+
+			for k, v := range tt.giveHeaders {
+				req.Header.Set(k, v)
+			}
+
+			httptest.HandleFastRequest(t, handler, req, func(_ int, body string, _ http.Header) {
+				for _, want := range tt.wantBody {
+					assert.Contains(t, body, want)
+				}
+
+				for _, notWant := range tt.wantNotBody {
+					assert.NotContains(t, body, notWant)
+				}
+			})
+		})
+	}
+}
+
+// newTestTracer creates a tracer exporting every span immediately to the local OTLP receiver stand-in, and returns
+// the channel with the exported spans.
+func newTestTracer(t *testing.T) (*tracing.Tracer, <-chan map[string]any) {
+	t.Helper()
+
+	var (
+		spans = make(chan map[string]any, 10)
+		otlp  = stdHttpTest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload struct {
+				ResourceSpans []struct {
+					ScopeSpans []struct {
+						Spans []map[string]any `json:"spans"`
+					} `json:"scopeSpans"`
+				} `json:"resourceSpans"`
+			}
+
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+			for _, rs := range payload.ResourceSpans {
+				for _, ss := range rs.ScopeSpans {
+					for _, s := range ss.Spans {
+						spans <- s
+					}
+				}
+			}
+		}))
+		tracer = tracing.New(logger.NewNop(), otlp.URL, "test", tracing.WithBatch(1, time.Hour)) // export immediately
+	)
+
+	t.Cleanup(func() { _ = tracer.Close(context.Background()); otlp.Close() })
+
+	return tracer, spans
+}
+
+// nextSpan waits for the next exported span.
+func nextSpan(t *testing.T, spans <-chan map[string]any) map[string]any {
+	t.Helper()
+
+	select {
+	case span := <-spans:
+		return span
+	case <-time.After(5 * time.Second):
+		t.Fatal("the span was not exported")
+	}
+
+	return nil
+}
+
+func TestHandler_Tracing(t *testing.T) {
+	t.Parallel()
+
+	var (
+		cfg           = config.New()
+		tracer, spans = newTestTracer(t)
+
+		handler, closeCache = error_page.New(&cfg, logger.NewNop(), error_page.WithTracer(tracer))
+	)
+
+	defer closeCache()
+
+	// the trace ID is not a part of the cache key, so the second request (with another trace ID) is cached too
+	for i, traceID := range []string{"4bf92f3577b34da6a3ce929d0e0e4736", "0af7651916cd43dd8448eb211c80319c"} {
+		var wantCacheHit = i > 0
+
+		req, reqErr := http.NewRequest(http.MethodGet, "http://testing/503.json", http.NoBody)
+		require.NoError(t, reqErr)
+
+		req.Header.Set("Traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+
+		httptest.HandleFastRequest(t, handler, req, func(status int, body string, _ http.Header) {
+			assert.Equal(t, http.StatusOK, status)
+			assert.Contains(t, body, `"trace_id": "`+traceID+`"`)
+		})
+
+		var span = nextSpan(t, spans)
+
+		assert.Equal(t, "error_page.render", span["name"])
+		assert.Equal(t, traceID, span["traceId"])
+		assert.Equal(t, "00f067aa0ba902b7", span["parentSpanId"])
+		assert.ElementsMatch(t, []any{
+			map[string]any{"key": "http.response.status_code", "value": map[string]any{"intValue": "200"}},
+			map[string]any{"key": "error_page.code", "value": map[string]any{"intValue": "503"}},
+			map[string]any{"key": "error_page.format", "value": map[string]any{"stringValue": "json"}},
+			map[string]any{"key": "error_page.template", "value": map[string]any{"stringValue": "json"}},
+			map[string]any{"key": "error_page.cache_hit", "value": map[string]any{"boolValue": wantCacheHit}},
+		}, span["attributes"])
+	}
+}
+
+func TestHandler_RequestIDCache(t *testing.T) {
+	t.Parallel()
+
+	var (
+		cfg           = config.New()
+		tracer, spans = newTestTracer(t)
+
+		handler, closeCache = error_page.New(&cfg, logger.NewNop(), error_page.WithTracer(tracer))
+	)
+
+	defer closeCache()
+
+	var cacheHit = func(span map[string]any) any {
+		for _, attr := range span["attributes"].([]any) {
+			if attr := attr.(map[string]any); attr["key"] == "error_page.cache_hit" {
+				return attr["value"].(map[string]any)["boolValue"]
+			}
+		}
+
+		return nil
+	}
+
+	for _, accept := range []string{"application/json", "application/xml", "text/html", "text/plain"} {
+		t.Run(accept, func(t *testing.T) {
+			// the request ID is not a part of the cache key, so the second request (with another ID) is cached too
+			for i, requestID := range []string{"req-id-1", "req-id-2"} {
+				req, reqErr := http.NewRequest(http.MethodGet, "http://testing/410", http.NoBody)
+				require.NoError(t, reqErr)
+
+				req.Header.Set("Accept", accept)
+				req.Header.Set("X-Request-Id", requestID)
+
+				httptest.HandleFastRequest(t, handler, req, func(_ int, body string, _ http.Header) {
+					assert.Contains(t, body, requestID)
+				})
+
+				assert.Equal(t, i > 0, cacheHit(nextSpan(t, spans)))
+			}
+		})
+	}
+
+	// the request IDs that may need escaping are rendered as usual, but not cached
+	for range 2 {
+		req, reqErr := http.NewRequest(http.MethodGet, "http://testing/410", http.NoBody)
+		require.NoError(t, reqErr)
+
+		req.Header.Set("Accept", "text/html")
+		req.Header.Set("X-Request-Id", `"><script>alert(1)</script>`)
+
+		httptest.HandleFastRequest(t, handler, req, func(_ int, body string, _ http.Header) {
+			assert.NotContains(t, body, "<script>alert(1)</script>")
+		})
+
+		assert.Equal(t, false, cacheHit(nextSpan(t, spans)))
+	}
+}
@@ -0,0 +1,80 @@
+package error_page
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+
+	"gh.tarampamp.am/error-pages/internal/template"
+)
+
+//nolint:gochecknoglobals
+var (
+	// the placeholders are random (per process), so they can't be guessed and injected by the clients
+	requestIDPlaceholder = newPlaceholder()
+	traceIDPlaceholder   = newPlaceholder()
+)
+
+// newPlaceholder returns a random string containing only the characters that are never escaped in any format.
+func newPlaceholder() string {
+	var b = make([]byte, 16) //nolint:mnd
+
+	_, _ = rand.Read(b) // never returns an error
+
+	return "ep" + hex.EncodeToString(b)
+}
+
+// withPlaceholders replaces the per-request values of the properties (the request and trace IDs) with the
+// placeholders, so the rendered content doesn't depend on them and can be cached and reused for other requests.
+// The returned fill function replaces the placeholders in the rendered content with the actual values.
+//
+// The values that may need escaping (the request ID is provided by the client) can't be substituted into the
+// rendered content, so they are kept as is - in this case the content must not be cached (cacheable is false).
+func withPlaceholders(props template.Props) (_ template.Props, fill func([]byte) []byte, cacheable bool) {
+	var requestID, traceID = props.RequestID, props.TraceID
+
+	if !isSafeValue(requestID) || !isSafeValue(traceID) {
+		return props, func(b []byte) []byte { return b }, false
+	}
+
+	if requestID != "" {
+		props.RequestID = requestIDPlaceholder
+	}
+
+	if traceID != "" {
+		props.TraceID = traceIDPlaceholder
+	}
+
+	return props, func(b []byte) []byte {
+		if requestID != "" {
+			b = bytes.ReplaceAll(b, []byte(requestIDPlaceholder), []byte(requestID))
+		}
+
+		if traceID != "" {
+			b = bytes.ReplaceAll(b, []byte(traceIDPlaceholder), []byte(traceID))
+		}
+
+		return b
+	}, true
+}
+
+// isSafeValue reports whether the value contains only the characters that are never escaped in the HTML, JSON,
+// XML, and plain text formats (the generated IDs, UUIDs, trace IDs, and the masked values match it).
+func isSafeValue(s string) bool {
+	const maxLength = 128
+
+	if len(s) > maxLength {
+		return false
+	}
+
+	for i := range len(s) {
+		switch c := s[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '*':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
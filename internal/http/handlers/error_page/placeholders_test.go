@@ -0,0 +1,41 @@
+package error_page
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gh.tarampamp.am/error-pages/internal/template"
+)
+
+func TestWithPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	var props, fill, cacheable = withPlaceholders(template.Props{
+		Code:      404,
+		RequestID: "01a14f90-1ff0-79cc-b2ba-e0bf3d7ef497",
+		TraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+	})
+
+	assert.True(t, cacheable)
+	assert.Equal(t, uint16(404), props.Code)
+	assert.Equal(t, requestIDPlaceholder, props.RequestID)
+	assert.Equal(t, traceIDPlaceholder, props.TraceID)
+	assert.Equal(t,
+		"<p>01a14f90-1ff0-79cc-b2ba-e0bf3d7ef497 / 4bf92f3577b34da6a3ce929d0e0e4736</p>",
+		string(fill([]byte("<p>"+requestIDPlaceholder+" / "+traceIDPlaceholder+"</p>"))),
+	)
+
+	props, _, cacheable = withPlaceholders(template.Props{})
+
+	assert.True(t, cacheable)
+	assert.Empty(t, props.RequestID)
+	assert.Empty(t, props.TraceID) // the empty values are kept, so the `{{ if trace_id }}` conditions work
+
+	props, fill, cacheable = withPlaceholders(template.Props{RequestID: `"><script>`, TraceID: "4bf92f35"})
+
+	assert.False(t, cacheable) // the value may need escaping
+	assert.Equal(t, `"><script>`, props.RequestID)
+	assert.Equal(t, "4bf92f35", props.TraceID)
+	assert.Equal(t, "foo", string(fill([]byte("foo"))))
+}
@@ -0,0 +1,40 @@
+package loglevel
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+
+	"gh.tarampamp.am/error-pages/internal/logger"
+)
+
+// New creates a handler for viewing (GET) and changing (PUT or POST) the logging levels at runtime. The request
+// body for changing should contain the levels in the same format as the `--log-level` flag (e.g., "http=debug").
+// The current levels are returned in the response body in the same format.
+//
+// The authorization and allowed methods are checked by the admin middleware (see [Methods]).
+func New(levels *logger.ComponentLevels) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		switch string(ctx.Method()) {
+		case fasthttp.MethodPut, fasthttp.MethodPost:
+			parsed, err := logger.ParseLevelSpec(strings.TrimSpace(string(ctx.PostBody())))
+			if err != nil {
+				ctx.Error(err.Error()+"\n", http.StatusBadRequest)
+
+				return
+			}
+
+			levels.Apply(parsed)
+		}
+
+		ctx.SetContentType("text/plain; charset=utf-8")
+		ctx.SetStatusCode(http.StatusOK)
+		_, _ = ctx.WriteString(levels.String() + "\n")
+	}
+}
+
+// Methods returns the HTTP methods supported by the handler (GET just returns the current levels).
+func Methods() []string {
+	return []string{fasthttp.MethodGet, fasthttp.MethodPut, fasthttp.MethodPost}
+}
@@ -0,0 +1,69 @@
+package loglevel_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gh.tarampamp.am/error-pages/internal/http/handlers/loglevel"
+	"gh.tarampamp.am/error-pages/internal/http/httptest"
+	"gh.tarampamp.am/error-pages/internal/logger"
+)
+
+func TestServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	var (
+		log, _  = logger.New(logger.InfoLevel, logger.JSONFormat, &strings.Builder{})
+		handler = loglevel.New(log.ComponentLevels())
+	)
+
+	var do = func(t *testing.T, method, body string) (int, string) {
+		t.Helper()
+
+		req, err := http.NewRequest(method, "http://testing/_admin/log-level", strings.NewReader(body))
+		require.NoError(t, err)
+
+		var (
+			gotStatus int
+			gotBody   string
+		)
+
+		httptest.HandleFastRequest(t, handler, req, func(status int, body string, _ http.Header) {
+			gotStatus, gotBody = status, body
+		})
+
+		return gotStatus, gotBody
+	}
+
+	t.Run("get", func(t *testing.T) {
+		status, body := do(t, http.MethodGet, "")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "info\n", body)
+	})
+
+	t.Run("change", func(t *testing.T) {
+		status, body := do(t, http.MethodPut, "http=debug,render=warn\n")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "info,http=debug,render=warn\n", body)
+		assert.Equal(t, logger.DebugLevel, log.Named("http").Level())
+
+		status, body = do(t, http.MethodPost, "error")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "error,http=debug,render=warn\n", body)
+		assert.Equal(t, logger.ErrorLevel, log.Level())
+	})
+
+	t.Run("wrong levels", func(t *testing.T) {
+		status, body := do(t, http.MethodPut, "http=loud")
+
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Contains(t, body, `unrecognized logging level: "loud"`)
+	})
+}
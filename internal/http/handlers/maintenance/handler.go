@@ -0,0 +1,71 @@
+package maintenance
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"gh.tarampamp.am/error-pages/internal/maintenance"
+)
+
+// New creates a handler for viewing (GET), enabling (PUT or POST) and disabling (DELETE) the maintenance mode at
+// runtime. The request body for enabling may contain the maintenance end time in RFC 3339 format (e.g.,
+// "2024-01-01T10:00:00Z") or its duration (e.g., "2h"); the maintenance lasts until it's disabled if the body is
+// empty. The current state is returned in the response body.
+//
+// The authorization and allowed methods are checked by the admin middleware (see [Methods]).
+func New(mode *maintenance.Mode) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		switch string(ctx.Method()) {
+		case fasthttp.MethodPut, fasthttp.MethodPost:
+			until, err := parseUntil(strings.TrimSpace(string(ctx.PostBody())), time.Now())
+			if err != nil {
+				ctx.Error(err.Error()+"\n", http.StatusBadRequest)
+
+				return
+			}
+
+			mode.Enable(until)
+
+		case fasthttp.MethodDelete:
+			mode.Disable()
+		}
+
+		ctx.SetContentType("text/plain; charset=utf-8")
+		ctx.SetStatusCode(http.StatusOK)
+
+		switch end, active := mode.Active(); {
+		case !active:
+			_, _ = ctx.WriteString("inactive\n")
+		case end.IsZero():
+			_, _ = ctx.WriteString("active\n")
+		default:
+			_, _ = ctx.WriteString("active until " + end.UTC().Format(time.RFC3339) + "\n")
+		}
+	}
+}
+
+// Methods returns the HTTP methods supported by the handler (GET just returns the current state).
+func Methods() []string {
+	return []string{fasthttp.MethodGet, fasthttp.MethodPut, fasthttp.MethodPost, fasthttp.MethodDelete}
+}
+
+// parseUntil parses the maintenance end time (RFC 3339) or duration. An empty string means the end is unknown.
+func parseUntil(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("wrong maintenance end [%s]: it should be an RFC 3339 time or a positive duration", s)
+}
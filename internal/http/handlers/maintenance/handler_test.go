@@ -0,0 +1,83 @@
+package maintenance_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	handler "gh.tarampamp.am/error-pages/internal/http/handlers/maintenance"
+	"gh.tarampamp.am/error-pages/internal/http/httptest"
+	"gh.tarampamp.am/error-pages/internal/maintenance"
+)
+
+func TestServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mode = maintenance.New()
+		h    = handler.New(mode)
+	)
+
+	var do = func(t *testing.T, method, body string) (int, string) {
+		t.Helper()
+
+		req, err := http.NewRequest(method, "http://testing/_admin/maintenance", strings.NewReader(body))
+		require.NoError(t, err)
+
+		var (
+			gotStatus int
+			gotBody   string
+		)
+
+		httptest.HandleFastRequest(t, h, req, func(status int, body string, _ http.Header) {
+			gotStatus, gotBody = status, body
+		})
+
+		return gotStatus, gotBody
+	}
+
+	t.Run("get", func(t *testing.T) {
+		status, body := do(t, http.MethodGet, "")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "inactive\n", body)
+	})
+
+	t.Run("enable and disable", func(t *testing.T) {
+		status, body := do(t, http.MethodPut, "")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "active\n", body)
+
+		status, body = do(t, http.MethodPost, "2099-01-01T10:00:00+02:00\n")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "active until 2099-01-01T08:00:00Z\n", body)
+
+		status, _ = do(t, http.MethodPut, "1h")
+
+		assert.Equal(t, http.StatusOK, status)
+
+		end, active := mode.Active()
+		assert.True(t, active)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), end, time.Minute)
+
+		status, body = do(t, http.MethodDelete, "")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "inactive\n", body)
+	})
+
+	t.Run("wrong end", func(t *testing.T) {
+		for _, giveBody := range []string{"tomorrow", "-1h", "0s"} {
+			status, body := do(t, http.MethodPut, giveBody)
+
+			assert.Equal(t, http.StatusBadRequest, status)
+			assert.Contains(t, body, "wrong maintenance end")
+		}
+	})
+}
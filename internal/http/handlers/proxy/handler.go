@@ -0,0 +1,177 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Options are the reverse proxy settings.
+type Options struct {
+	// Upstream is the base URL of the upstream service (e.g., "http://127.0.0.1:8080"). The URL path (if any) is
+	// used as a prefix for the proxied requests paths.
+	Upstream string
+
+	// Timeout limits the upstream request duration, including the response body reading. The error page (504) is
+	// returned only if the response headers don't arrive in time - the body is streamed after that, so a stalled
+	// transfer is cut off instead.
+	Timeout time.Duration
+
+	// Intercept should return true if the upstream response with the given status code should be replaced with the
+	// error page.
+	Intercept func(statusCode int) bool
+
+	// OnError is called to respond with the error page for the given status code - when the upstream response is
+	// intercepted, or the upstream is unavailable (502) or does not respond with the headers in time (504).
+	OnError func(ctx *fasthttp.RequestCtx, statusCode uint16)
+}
+
+// hopHeaders are the hop-by-hop headers, which must not be forwarded by proxies (RFC 9110, section 7.6.1).
+var hopHeaders = []string{ //nolint:gochecknoglobals
+	"Connection", "Proxy-Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization", "Te", "Trailer",
+	"Transfer-Encoding", "Upgrade",
+}
+
+// New creates a reverse proxy handler: requests are forwarded to the upstream, the successful responses are
+// streamed back untouched, and the intercepted ones are replaced using the OnError function.
+func New(opt Options) (fasthttp.RequestHandler, error) {
+	var u, err = url.Parse(opt.Upstream)
+	if err != nil {
+		return nil, fmt.Errorf("wrong upstream URL: %w", err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("wrong upstream URL [%s]: it should be an absolute HTTP(S) URL", opt.Upstream)
+	}
+
+	if opt.Intercept == nil || opt.OnError == nil {
+		return nil, errors.New("the intercept and error functions are required")
+	}
+
+	var (
+		isTLS      = u.Scheme == "https"
+		pathPrefix = strings.TrimRight(u.Path, "/")
+		client     = &fasthttp.HostClient{
+			Addr:                     addMissingPort(u.Host, isTLS),
+			IsTLS:                    isTLS,
+			StreamResponseBody:       true, // the successful responses are not buffered
+			NoDefaultUserAgentHeader: true,
+			DisablePathNormalizing:   true,
+		}
+	)
+
+	return func(ctx *fasthttp.RequestCtx) {
+		var req = fasthttp.AcquireRequest()
+
+		ctx.Request.CopyTo(req)
+
+		req.SetRequestURI(pathPrefix + string(ctx.RequestURI()))
+
+		for _, h := range hopHeaders {
+			req.Header.Del(h)
+		}
+
+		{ // https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers#proxies
+			if prior := string(ctx.Request.Header.Peek("X-Forwarded-For")); prior != "" {
+				req.Header.Set("X-Forwarded-For", prior+", "+ctx.RemoteIP().String())
+			} else {
+				req.Header.Set("X-Forwarded-For", ctx.RemoteIP().String())
+			}
+
+			if len(ctx.Request.Header.Peek("X-Forwarded-Host")) == 0 {
+				req.Header.SetBytesV("X-Forwarded-Host", ctx.Host())
+			}
+
+			if len(ctx.Request.Header.Peek("X-Forwarded-Proto")) == 0 {
+				if ctx.IsTLS() {
+					req.Header.Set("X-Forwarded-Proto", "https")
+				} else {
+					req.Header.Set("X-Forwarded-Proto", "http")
+				}
+			}
+		}
+
+		var resp = fasthttp.AcquireResponse()
+
+		// the connection read deadline set here stays for the streamed body reading too, so the whole transfer is
+		// bounded by the timeout
+		err := client.DoTimeout(req, resp, opt.Timeout)
+
+		fasthttp.ReleaseRequest(req)
+
+		if err != nil {
+			fasthttp.ReleaseResponse(resp)
+
+			if errors.Is(err, fasthttp.ErrTimeout) {
+				opt.OnError(ctx, http.StatusGatewayTimeout)
+			} else {
+				opt.OnError(ctx, http.StatusBadGateway)
+			}
+
+			return
+		}
+
+		if code := resp.StatusCode(); opt.Intercept(code) {
+			release(resp)
+			opt.OnError(ctx, uint16(code)) //nolint:gosec
+
+			return
+		}
+
+		resp.Header.CopyTo(&ctx.Response.Header)
+
+		for _, h := range hopHeaders {
+			ctx.Response.Header.Del(h)
+		}
+
+		if stream := resp.BodyStream(); stream != nil {
+			var size = resp.Header.ContentLength()
+
+			if size < 0 {
+				size = -1 // chunked
+			}
+
+			// the response is released by the server after the body is written
+			ctx.Response.SetBodyStream(&releaser{Reader: stream, resp: resp}, size)
+		} else {
+			ctx.Response.SetBody(resp.Body())
+			release(resp)
+		}
+	}, nil
+}
+
+// addMissingPort adds the default port to the address (if missing).
+func addMissingPort(addr string, isTLS bool) string {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr
+	}
+
+	if isTLS {
+		return net.JoinHostPort(strings.Trim(addr, "[]"), "443")
+	}
+
+	return net.JoinHostPort(strings.Trim(addr, "[]"), "80")
+}
+
+// release closes the response body stream (if any) and releases the response.
+func release(resp *fasthttp.Response) {
+	_ = resp.CloseBodyStream()
+
+	fasthttp.ReleaseResponse(resp)
+}
+
+// releaser releases the upstream response when the body stream is closed.
+type releaser struct {
+	io.Reader
+
+	resp *fasthttp.Response
+}
+
+func (r *releaser) Close() error { release(r.resp); return nil } //nolint:nlreturn
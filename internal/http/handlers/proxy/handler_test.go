@@ -0,0 +1,205 @@
+package proxy_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	stdHttpTest "net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+
+	"gh.tarampamp.am/error-pages/internal/http/handlers/proxy"
+	"gh.tarampamp.am/error-pages/internal/http/httptest"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	var upstream = stdHttpTest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Path", r.URL.RequestURI())
+		w.Header().Set("X-Forwarded-For-Got", r.Header.Get("X-Forwarded-For"))
+		w.Header().Set("Connection", "keep-alive") // hop-by-hop, must not be forwarded back
+
+		switch r.URL.Path {
+		case "/api/ok":
+			_, _ = fmt.Fprint(w, "upstream content")
+		case "/api/not-found":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, "upstream not found")
+		case "/api/teapot":
+			w.WriteHeader(http.StatusTeapot)
+			_, _ = fmt.Fprint(w, "upstream teapot")
+		case "/api/slow":
+			time.Sleep(300 * time.Millisecond)
+		}
+	}))
+
+	t.Cleanup(upstream.Close)
+
+	var handler, err = proxy.New(proxy.Options{
+		Upstream:  upstream.URL + "/api/",
+		Timeout:   100 * time.Millisecond,
+		Intercept: func(statusCode int) bool { return statusCode == http.StatusNotFound },
+		OnError: func(ctx *fasthttp.RequestCtx, statusCode uint16) {
+			ctx.SetStatusCode(int(statusCode))
+			ctx.SetBodyString(fmt.Sprintf("error page %d", statusCode))
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("pass through", func(t *testing.T) {
+		t.Parallel()
+
+		httptest.HandleFast(t, handler, http.MethodGet, "http://testing/ok?foo=bar", nil,
+			func(status int, body string, headers http.Header) {
+				assert.Equal(t, http.StatusOK, status)
+				assert.Equal(t, "upstream content", body)
+				assert.Equal(t, "/api/ok?foo=bar", headers.Get("X-Path"))
+				assert.NotEmpty(t, headers.Get("X-Forwarded-For-Got"))
+				assert.Empty(t, headers.Get("Connection"))
+			},
+		)
+	})
+
+	t.Run("not intercepted error", func(t *testing.T) {
+		t.Parallel()
+
+		httptest.HandleFast(t, handler, http.MethodGet, "http://testing/teapot", nil,
+			func(status int, body string, _ http.Header) {
+				assert.Equal(t, http.StatusTeapot, status)
+				assert.Equal(t, "upstream teapot", body)
+			},
+		)
+	})
+
+	t.Run("intercepted error", func(t *testing.T) {
+		t.Parallel()
+
+		httptest.HandleFast(t, handler, http.MethodGet, "http://testing/not-found", nil,
+			func(status int, body string, headers http.Header) {
+				assert.Equal(t, http.StatusNotFound, status)
+				assert.Equal(t, "error page 404", body)
+				assert.Empty(t, headers.Get("X-Path")) // upstream headers are not forwarded
+			},
+		)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+
+		httptest.HandleFast(t, handler, http.MethodGet, "http://testing/slow", nil,
+			func(status int, body string, _ http.Header) {
+				assert.Equal(t, http.StatusGatewayTimeout, status)
+				assert.Equal(t, "error page 504", body)
+			},
+		)
+	})
+}
+
+func TestNew_StalledBody(t *testing.T) {
+	t.Parallel()
+
+	var (
+		done     = make(chan struct{})
+		upstream = stdHttpTest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = fmt.Fprint(w, "partial")
+			w.(http.Flusher).Flush() //nolint:forcetypeassert // the headers are sent, but the body never ends
+
+			<-done
+		}))
+	)
+
+	t.Cleanup(upstream.Close)
+	t.Cleanup(func() { close(done) }) // called before the upstream closing
+
+	handler, err := proxy.New(proxy.Options{
+		Upstream:  upstream.URL,
+		Timeout:   200 * time.Millisecond,
+		Intercept: func(int) bool { return false },
+		OnError:   func(ctx *fasthttp.RequestCtx, statusCode uint16) { ctx.SetStatusCode(int(statusCode)) },
+	})
+	require.NoError(t, err)
+
+	var ln = fasthttputil.NewInmemoryListener()
+
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() { _ = fasthttp.Serve(ln, handler) }()
+
+	var client = http.Client{Transport: &http.Transport{
+		DialContext: func(context.Context, string, string) (net.Conn, error) { return ln.Dial() },
+	}}
+
+	var start = time.Now()
+
+	resp, err := client.Get("http://testing/")
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode) // the headers are already sent, so no 504 is possible
+
+	body, err := io.ReadAll(resp.Body)
+
+	require.Error(t, err) // the transfer is cut off
+	assert.Equal(t, "partial", string(body))
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestNew_UpstreamDown(t *testing.T) {
+	t.Parallel()
+
+	// reserve a free port and release it, so nobody listens on it
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var addr = ln.Addr().String()
+
+	require.NoError(t, ln.Close())
+
+	handler, err := proxy.New(proxy.Options{
+		Upstream:  "http://" + addr,
+		Timeout:   time.Second,
+		Intercept: func(int) bool { return false },
+		OnError:   func(ctx *fasthttp.RequestCtx, statusCode uint16) { ctx.SetStatusCode(int(statusCode)) },
+	})
+	require.NoError(t, err)
+
+	httptest.HandleFast(t, handler, http.MethodGet, "http://testing/", nil,
+		func(status int, _ string, _ http.Header) {
+			assert.Equal(t, http.StatusBadGateway, status)
+		},
+	)
+}
+
+func TestNew_Errors(t *testing.T) {
+	t.Parallel()
+
+	var (
+		intercept = func(int) bool { return false }
+		onError   = func(*fasthttp.RequestCtx, uint16) {}
+	)
+
+	for name, opt := range map[string]proxy.Options{
+		"wrong url":      {Upstream: "://", Intercept: intercept, OnError: onError},
+		"wrong scheme":   {Upstream: "ftp://example.com", Intercept: intercept, OnError: onError},
+		"missing host":   {Upstream: "http://", Intercept: intercept, OnError: onError},
+		"missing funcs":  {Upstream: "http://example.com"},
+		"missing errors": {Upstream: "http://example.com", Intercept: intercept},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := proxy.New(opt)
+
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package adminauth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"slices"
+
+	"github.com/valyala/fasthttp"
+)
+
+// New creates a middleware for the admin endpoints: every request must be authorized using the bearer token (the
+// `Authorization: Bearer <token>` header), and only the given HTTP methods are allowed. The token is checked first,
+// so the endpoint details are not revealed to the unauthorized clients.
+func New(token string, methods ...string) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	var (
+		wantAuth     = []byte("Bearer " + token)
+		unauthorized = http.StatusText(http.StatusUnauthorized) + "\n"
+		notAllowed   = http.StatusText(http.StatusMethodNotAllowed) + "\n"
+	)
+
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			if subtle.ConstantTimeCompare(ctx.Request.Header.Peek(fasthttp.HeaderAuthorization), wantAuth) != 1 {
+				ctx.Error(unauthorized, http.StatusUnauthorized)
+				ctx.Response.Header.Set(fasthttp.HeaderWWWAuthenticate, "Bearer") // after the error, since it resets headers
+
+				return
+			}
+
+			if !slices.Contains(methods, string(ctx.Method())) {
+				ctx.Error(notAllowed, http.StatusMethodNotAllowed)
+
+				return
+			}
+
+			next(ctx)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package adminauth_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"gh.tarampamp.am/error-pages/internal/http/httptest"
+	"gh.tarampamp.am/error-pages/internal/http/middleware/adminauth"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	var handler = adminauth.New("secret", http.MethodGet, http.MethodPut)(func(ctx *fasthttp.RequestCtx) {
+		_, _ = ctx.WriteString("ok")
+	})
+
+	for name, tt := range map[string]struct {
+		giveMethod string
+		giveAuth   string
+
+		wantStatusCode int
+		wantBody       string
+		wantChallenge  string
+	}{
+		"authorized": {
+			giveMethod:     http.MethodGet,
+			giveAuth:       "Bearer secret",
+			wantStatusCode: http.StatusOK,
+			wantBody:       "ok",
+		},
+		"authorized, another method": {
+			giveMethod:     http.MethodPut,
+			giveAuth:       "Bearer secret",
+			wantStatusCode: http.StatusOK,
+			wantBody:       "ok",
+		},
+		"no token": {
+			giveMethod:     http.MethodGet,
+			wantStatusCode: http.StatusUnauthorized,
+			wantBody:       "Unauthorized\n",
+			wantChallenge:  "Bearer",
+		},
+		"wrong token": {
+			giveMethod:     http.MethodGet,
+			giveAuth:       "Bearer wrong",
+			wantStatusCode: http.StatusUnauthorized,
+			wantBody:       "Unauthorized\n",
+			wantChallenge:  "Bearer",
+		},
+		"no scheme": {
+			giveMethod:     http.MethodGet,
+			giveAuth:       "secret",
+			wantStatusCode: http.StatusUnauthorized,
+			wantBody:       "Unauthorized\n",
+			wantChallenge:  "Bearer",
+		},
+		"wrong scheme": {
+			giveMethod:     http.MethodGet,
+			giveAuth:       "Basic secret",
+			wantStatusCode: http.StatusUnauthorized,
+			wantBody:       "Unauthorized\n",
+			wantChallenge:  "Bearer",
+		},
+		"method not allowed": {
+			giveMethod:     http.MethodDelete,
+			giveAuth:       "Bearer secret",
+			wantStatusCode: http.StatusMethodNotAllowed,
+			wantBody:       "Method Not Allowed\n",
+		},
+		"method not allowed, unauthorized": {
+			giveMethod:     http.MethodDelete,
+			wantStatusCode: http.StatusUnauthorized,
+			wantBody:       "Unauthorized\n",
+			wantChallenge:  "Bearer",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest(tt.giveMethod, "http://testing/_admin/test", http.NoBody)
+			require.NoError(t, err)
+
+			if tt.giveAuth != "" {
+				req.Header.Set("Authorization", tt.giveAuth)
+			}
+
+			httptest.HandleFastRequest(t, handler, req, func(status int, body string, headers http.Header) {
+				assert.Equal(t, tt.wantStatusCode, status)
+				assert.Equal(t, tt.wantBody, body)
+				assert.Equal(t, tt.wantChallenge, headers.Get("WWW-Authenticate"))
+			})
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package cors
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Options are the CORS (Cross-Origin Resource Sharing) settings.
+type Options struct {
+	// AllowedOrigins is a list of origins a cross-domain request can be executed from. If the list contains "*",
+	// all origins are allowed. An empty list means CORS is disabled.
+	AllowedOrigins []string
+
+	// AllowedMethods is a list of methods the client is allowed to use with cross-domain requests.
+	AllowedMethods []string
+
+	// AllowedHeaders is a list of non-simple headers the client is allowed to use with cross-domain requests. If
+	// the list is empty, the headers requested in the preflight request are allowed.
+	AllowedHeaders []string
+
+	// MaxAge indicates how long the results of a preflight request can be cached by the client. Zero means the
+	// header will not be sent.
+	MaxAge time.Duration
+
+	// PassPreflight reports whether the preflight request should be passed to the next handler as is, even if the
+	// origin is allowed (e.g., the upstream application in the reverse proxy mode handles them itself). Optional.
+	PassPreflight func(*fasthttp.RequestCtx) bool
+}
+
+// New creates a middleware that adds CORS headers to the responses and handles preflight (OPTIONS) requests from
+// the allowed origins, so they will not be passed to the next handler. The other preflight requests are passed to
+// the next handler (e.g., the upstream application in the reverse proxy mode may handle them itself). Unless all
+// origins are allowed, the "Vary: Origin" header is added to all the responses, since they depend on the origin.
+//
+// https://developer.mozilla.org/en-US/docs/Web/HTTP/CORS
+func New(opt Options) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	var (
+		allowAny       = slices.Contains(opt.AllowedOrigins, "*")
+		allowedMethods = strings.Join(opt.AllowedMethods, ", ")
+		allowedHeaders = strings.Join(opt.AllowedHeaders, ", ")
+		maxAge         string
+	)
+
+	if opt.MaxAge > 0 {
+		maxAge = strconv.Itoa(int(opt.MaxAge.Seconds()))
+	}
+
+	var isAllowed = func(origin string) bool {
+		if allowAny {
+			return true
+		}
+
+		for _, allowed := range opt.AllowedOrigins {
+			if strings.EqualFold(allowed, origin) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			var (
+				origin      = string(ctx.Request.Header.Peek("Origin"))
+				isPreflight = ctx.IsOptions() && len(ctx.Request.Header.Peek("Access-Control-Request-Method")) > 0
+			)
+
+			if isPreflight && opt.PassPreflight != nil && opt.PassPreflight(ctx) {
+				next(ctx)
+
+				return
+			}
+
+			if origin == "" || !isAllowed(origin) {
+				next(ctx)
+
+				if !allowAny {
+					ctx.Response.Header.Add("Vary", "Origin") // a shared cache must not reuse it for the allowed ones
+				}
+
+				return
+			}
+
+			if !isPreflight {
+				next(ctx)
+
+				// the headers are set after the next handler, since it may reset the response (e.g., using ctx.Error)
+				setAllowOrigin(&ctx.Response.Header, origin, allowAny)
+
+				return
+			}
+
+			var headers = &ctx.Response.Header
+
+			setAllowOrigin(headers, origin, allowAny)
+
+			headers.Set("Access-Control-Allow-Methods", allowedMethods)
+
+			if allowedHeaders != "" {
+				headers.Set("Access-Control-Allow-Headers", allowedHeaders)
+			} else if requested := ctx.Request.Header.Peek("Access-Control-Request-Headers"); len(requested) > 0 {
+				headers.SetBytesV("Access-Control-Allow-Headers", requested)
+				headers.Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			if maxAge != "" {
+				headers.Set("Access-Control-Max-Age", maxAge)
+			}
+
+			ctx.SetStatusCode(http.StatusNoContent)
+		}
+	}
+}
+
+// setAllowOrigin sets the header allowing the origin (and the Vary header, if the response depends on the origin).
+func setAllowOrigin(headers *fasthttp.ResponseHeader, origin string, allowAny bool) {
+	if allowAny {
+		headers.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		headers.Set("Access-Control-Allow-Origin", origin)
+		headers.Add("Vary", "Origin")
+	}
+}
@@ -0,0 +1,170 @@
+package cors_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"gh.tarampamp.am/error-pages/internal/http/httptest"
+	"gh.tarampamp.am/error-pages/internal/http/middleware/cors"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	var next = func(ctx *fasthttp.RequestCtx) {
+		ctx.Error("error page", http.StatusNotFound) // resets the response headers
+	}
+
+	for name, tt := range map[string]struct {
+		giveOptions cors.Options
+		giveMethod  string
+		giveHeaders map[string]string
+
+		wantStatusCode int
+		wantBody       string
+		wantHeaders    map[string]string
+	}{
+		"allowed origin": {
+			giveOptions: cors.Options{AllowedOrigins: []string{"https://example.com"}},
+			giveMethod:  http.MethodGet,
+			giveHeaders: map[string]string{"Origin": "https://EXAMPLE.com"},
+
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "error page",
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://EXAMPLE.com",
+				"Vary":                         "Origin",
+				"Access-Control-Allow-Methods": "",
+			},
+		},
+		"any origin": {
+			giveOptions: cors.Options{AllowedOrigins: []string{"*"}},
+			giveMethod:  http.MethodGet,
+			giveHeaders: map[string]string{"Origin": "https://example.com"},
+
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "error page",
+			wantHeaders:    map[string]string{"Access-Control-Allow-Origin": "*", "Vary": ""},
+		},
+		"disallowed origin": {
+			giveOptions: cors.Options{AllowedOrigins: []string{"https://example.com"}},
+			giveMethod:  http.MethodGet,
+			giveHeaders: map[string]string{"Origin": "https://evil.com"},
+
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "error page",
+			wantHeaders:    map[string]string{"Access-Control-Allow-Origin": "", "Vary": "Origin"},
+		},
+		"without origin": {
+			giveOptions: cors.Options{AllowedOrigins: []string{"*"}},
+			giveMethod:  http.MethodGet,
+
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "error page",
+			wantHeaders:    map[string]string{"Access-Control-Allow-Origin": "", "Vary": ""},
+		},
+		"without origin, specific origins allowed": {
+			giveOptions: cors.Options{AllowedOrigins: []string{"https://example.com"}},
+			giveMethod:  http.MethodGet,
+
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "error page",
+			wantHeaders:    map[string]string{"Access-Control-Allow-Origin": "", "Vary": "Origin"},
+		},
+		"preflight": {
+			giveOptions: cors.Options{
+				AllowedOrigins: []string{"https://example.com"},
+				AllowedMethods: []string{http.MethodGet, http.MethodHead},
+				AllowedHeaders: []string{"Accept", "X-Format"},
+				MaxAge:         10 * time.Minute,
+			},
+			giveMethod: http.MethodOptions,
+			giveHeaders: map[string]string{
+				"Origin":                         "https://example.com",
+				"Access-Control-Request-Method":  http.MethodGet,
+				"Access-Control-Request-Headers": "x-foo",
+			},
+
+			wantStatusCode: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://example.com",
+				"Access-Control-Allow-Methods": "GET, HEAD",
+				"Access-Control-Allow-Headers": "Accept, X-Format",
+				"Access-Control-Max-Age":       "600",
+			},
+		},
+		"preflight, requested headers are mirrored": {
+			giveOptions: cors.Options{AllowedOrigins: []string{"*"}, AllowedMethods: []string{http.MethodGet}},
+			giveMethod:  http.MethodOptions,
+			giveHeaders: map[string]string{
+				"Origin":                         "https://example.com",
+				"Access-Control-Request-Method":  http.MethodGet,
+				"Access-Control-Request-Headers": "x-foo",
+			},
+
+			wantStatusCode: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "*",
+				"Access-Control-Allow-Headers": "x-foo",
+				"Access-Control-Max-Age":       "",
+			},
+		},
+		"preflight from disallowed origin": {
+			giveOptions: cors.Options{AllowedOrigins: []string{"https://example.com"}},
+			giveMethod:  http.MethodOptions,
+			giveHeaders: map[string]string{"Origin": "https://evil.com", "Access-Control-Request-Method": "GET"},
+
+			wantStatusCode: http.StatusNotFound, // passed to the next handler
+			wantBody:       "error page",
+			wantHeaders:    map[string]string{"Access-Control-Allow-Origin": "", "Access-Control-Allow-Methods": ""},
+		},
+		"preflight passed to the next handler": {
+			giveOptions: cors.Options{
+				AllowedOrigins: []string{"https://example.com"},
+				PassPreflight:  func(*fasthttp.RequestCtx) bool { return true },
+			},
+			giveMethod:  http.MethodOptions,
+			giveHeaders: map[string]string{"Origin": "https://example.com", "Access-Control-Request-Method": "GET"},
+
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "error page",
+			wantHeaders:    map[string]string{"Access-Control-Allow-Origin": "", "Vary": ""},
+		},
+		"options without the preflight headers": {
+			giveOptions: cors.Options{AllowedOrigins: []string{"*"}},
+			giveMethod:  http.MethodOptions,
+			giveHeaders: map[string]string{"Origin": "https://example.com"},
+
+			wantStatusCode: http.StatusNotFound,
+			wantBody:       "error page",
+			wantHeaders:    map[string]string{"Access-Control-Allow-Origin": "*"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequest(tt.giveMethod, "http://testing/404", http.NoBody)
+			require.NoError(t, err)
+
+			for k, v := range tt.giveHeaders {
+				req.Header.Set(k, v)
+			}
+
+			httptest.HandleFastRequest(t, cors.New(tt.giveOptions)(next), req,
+				func(status int, body string, headers http.Header) {
+					assert.Equal(t, tt.wantStatusCode, status)
+					assert.Equal(t, tt.wantBody, body)
+
+					for key, value := range tt.wantHeaders {
+						assert.Equal(t, value, headers.Get(key), key)
+					}
+				},
+			)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package logreq
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+
+	"gh.tarampamp.am/error-pages/internal/config"
+	"gh.tarampamp.am/error-pages/internal/http/middleware/requestid"
+)
+
+// Entry is a single access log record. Its fields are available in the access log templates (e.g.,
+// `{{ .RemoteAddr }} {{ .Method }} {{ .URI }} {{ .Status }}`).
+type Entry struct {
+	Time            time.Time         // the time the request was received
+	RequestID       string            // the request ID (the `X-Request-Id` header value)
+	RemoteAddr      string            // the client IP address
+	Method          string            // the HTTP method
+	URI             string            // the request URI (path and query)
+	Protocol        string            // the HTTP protocol version (e.g., HTTP/1.1)
+	Status          int               // the response status code
+	BytesSent       int               // the response body size
+	Referer         string            // the value of the `Referer` header
+	UserAgent       string            // the value of the `User-Agent` header
+	ContentType     string            // the response content type
+	Duration        time.Duration     // the request processing duration
+	RequestHeaders  map[string]string // the configured request headers (the key is the header name)
+	ResponseHeaders map[string]string // the configured response headers (the key is the header name)
+}
+
+// newEntry creates a new access log entry from the request context. Only the listed headers are included, and
+// their sensitive values are masked.
+func newEntry(
+	ctx *fasthttp.RequestCtx,
+	startedAt time.Time,
+	reqHeaders, respHeaders []string,
+	redaction config.Redaction,
+) Entry {
+	var e = Entry{
+		Time:        startedAt,
+		RequestID:   string(ctx.Request.Header.Peek(requestid.Header)),
+		RemoteAddr:  ctx.RemoteIP().String(),
+		Method:      string(ctx.Method()),
+		URI:         string(ctx.RequestURI()),
+		Protocol:    string(ctx.Request.Header.Protocol()),
+		Status:      ctx.Response.StatusCode(),
+		BytesSent:   len(ctx.Response.Body()),
+		Referer:     string(ctx.Referer()),
+		UserAgent:   string(ctx.UserAgent()),
+		ContentType: string(ctx.Response.Header.ContentType()),
+		Duration:    time.Since(startedAt).Round(time.Microsecond),
+	}
+
+	if len(reqHeaders) > 0 {
+		e.RequestHeaders = make(map[string]string, len(reqHeaders))
+
+		for _, name := range reqHeaders {
+			e.RequestHeaders[name] = redaction.Redact(name, string(ctx.Request.Header.Peek(name)))
+		}
+	}
+
+	if len(respHeaders) > 0 {
+		e.ResponseHeaders = make(map[string]string, len(respHeaders))
+
+		for _, name := range respHeaders {
+			e.ResponseHeaders[name] = redaction.Redact(name, string(ctx.Response.Header.Peek(name)))
+		}
+	}
+
+	return e
+}
+
+// headerFieldName converts the HTTP header name into the log field name (e.g., "X-Request-Id" with the "req_"
+// prefix becomes "req_x_request_id").
+func headerFieldName(prefix, header string) string {
+	return prefix + strings.ReplaceAll(strings.ToLower(header), "-", "_")
+}
+
+// Common returns the entry in the Apache Common Log Format:
+//
+//	127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /404.html HTTP/1.1" 404 2326
+func (e Entry) Common() string {
+	var b strings.Builder
+
+	b.WriteString(e.RemoteAddr)
+	b.WriteString(" - - [")
+	b.WriteString(e.Time.Format("02/Jan/2006:15:04:05 -0700"))
+	b.WriteString(`] "`)
+	b.WriteString(e.Method)
+	b.WriteByte(' ')
+	b.WriteString(e.URI)
+	b.WriteByte(' ')
+	b.WriteString(e.Protocol)
+	b.WriteString(`" `)
+	b.WriteString(strconv.Itoa(e.Status))
+	b.WriteByte(' ')
+
+	if e.BytesSent > 0 {
+		b.WriteString(strconv.Itoa(e.BytesSent))
+	} else {
+		b.WriteByte('-')
+	}
+
+	return b.String()
+}
+
+// Combined returns the entry in the Apache Combined Log Format (the Common Log Format with the referer and
+// user agent):
+//
+//	127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /404.html HTTP/1.1" 404 2326 "http://example.com/" "curl/8.0"
+func (e Entry) Combined() string {
+	return e.Common() + ` "` + escapeQuoted(e.Referer) + `" "` + escapeQuoted(e.UserAgent) + `"`
+}
+
+// JSON returns the entry as a JSON object. The field names may be renamed using the names map (the key is the
+// default field name and the value is the custom one).
+func (e Entry) JSON(names map[string]string) ([]byte, error) {
+	var fields = map[string]any{
+		"time":         e.Time.Format(time.RFC3339Nano),
+		"request_id":   e.RequestID,
+		"remote_addr":  e.RemoteAddr,
+		"method":       e.Method,
+		"url":          e.URI,
+		"protocol":     e.Protocol,
+		"status":       e.Status,
+		"bytes":        e.BytesSent,
+		"referer":      e.Referer,
+		"user_agent":   e.UserAgent,
+		"content_type": e.ContentType,
+		"duration_ms":  float64(e.Duration.Microseconds()) / 1000, //nolint:mnd
+	}
+
+	for name, value := range e.RequestHeaders {
+		fields[headerFieldName("req_", name)] = value
+	}
+
+	for name, value := range e.ResponseHeaders {
+		fields[headerFieldName("resp_", name)] = value
+	}
+
+	for from, to := range names {
+		if value, ok := fields[from]; ok && to != "" && to != from {
+			delete(fields, from)
+
+			fields[to] = value
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// escapeQuoted escapes the double quotes and backslashes in the string to be used inside the quoted value.
+func escapeQuoted(s string) string {
+	if !strings.ContainsAny(s, `"\`) {
+		return s
+	}
+
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
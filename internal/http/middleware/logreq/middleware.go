@@ -1,20 +1,93 @@
 package logreq
 
 import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	"github.com/valyala/fasthttp"
 
+	"gh.tarampamp.am/error-pages/internal/config"
 	"gh.tarampamp.am/error-pages/internal/logger"
 )
 
+type (
+	options struct {
+		format          config.AccessLogFormat
+		writer          io.Writer
+		template        *template.Template
+		fieldNames      map[string]string
+		requestHeaders  []string
+		responseHeaders []string
+		redaction       config.Redaction
+		sampler         *Sampler
+	}
+
+	// Option allows you to change some settings of the middleware.
+	Option func(*options)
+)
+
+// WithFormat sets the access log format. The default format uses the application logger.
+func WithFormat(f config.AccessLogFormat) Option { return func(o *options) { o.format = f } }
+
+// WithWriter sets the writer for the access log lines (stdout by default). It is not used with the default format.
+func WithWriter(w io.Writer) Option { return func(o *options) { o.writer = w } }
+
+// WithTemplate sets the template for the access log line (the template is executed with the [Entry]).
+func WithTemplate(t *template.Template) Option { return func(o *options) { o.template = t } }
+
+// WithFieldNames allows renaming the JSON fields (the key is the default field name, the value is the custom one).
+func WithFieldNames(names map[string]string) Option { return func(o *options) { o.fieldNames = names } }
+
+// WithRequestHeaders sets the names of the request headers that become the access log fields.
+func WithRequestHeaders(names ...string) Option { return func(o *options) { o.requestHeaders = names } }
+
+// WithResponseHeaders sets the names of the response headers that become the access log fields.
+func WithResponseHeaders(names ...string) Option {
+	return func(o *options) { o.responseHeaders = names }
+}
+
+// WithRedaction sets the redaction settings for the sensitive HTTP header values (applied to the headers in the
+// debug output and to the header fields).
+func WithRedaction(r config.Redaction) Option { return func(o *options) { o.redaction = r } }
+
+// WithSampler enables the access log sampling using the given sampler (see [NewSampler]). The summary with the
+// number of dropped entries is logged by the sampler itself (see [Sampler.Watch]).
+func WithSampler(s *Sampler) Option { return func(o *options) { o.sampler = s } }
+
 // New creates a middleware that logs every incoming request.
 //
 // The skipper function should return true if the request should be skipped. It's ok to pass nil.
-func New(
+func New( //nolint:funlen,gocognit
 	log *logger.Logger,
 	skipper func(*fasthttp.RequestCtx) bool,
+	opts ...Option,
 ) func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	var o = options{writer: os.Stdout}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.format == config.AccessLogFormatTemplate && o.template == nil {
+		o.format = config.AccessLogFormatDefault // fallback, since the template is not set
+	}
+
+	var mu sync.Mutex // protects the writer
+
+	// writeLine writes a single access log line to the writer
+	var writeLine = func(line []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if _, err := o.writer.Write(line); err != nil {
+			log.Error("failed to write the access log record", logger.Error(err))
+		}
+	}
+
 	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 		return func(ctx *fasthttp.RequestCtx) {
 			if skipper != nil && skipper(ctx) {
@@ -26,38 +99,88 @@ func New(
 			var now = time.Now()
 
 			defer func() {
-				var fields = []logger.Attr{
-					logger.Int("status code", ctx.Response.StatusCode()),
-					logger.String("useragent", string(ctx.UserAgent())),
-					logger.String("method", string(ctx.Method())),
-					logger.String("url", string(ctx.RequestURI())),
-					logger.String("referer", string(ctx.Referer())),
-					logger.String("content type", string(ctx.Response.Header.ContentType())),
-					logger.String("remote addr", ctx.RemoteAddr().String()),
-					logger.Duration("duration", time.Since(now).Round(time.Microsecond)),
+				// the access log records are the info-level ones in any format, so the "http" component level is
+				// respected even when the lines are written directly to the writer
+				if log.Level() > logger.InfoLevel {
+					return
 				}
 
-				if log.Level() <= logger.DebugLevel {
-					var (
-						reqHeaders  = make(map[string]string)
-						respHeaders = make(map[string]string)
-					)
+				if o.sampler != nil && !o.sampler.Allow(ctx.Response.StatusCode()) {
+					return
+				}
+
+				var entry = newEntry(ctx, now, o.requestHeaders, o.responseHeaders, o.redaction)
+
+				switch o.format {
+				case config.AccessLogFormatCommon:
+					writeLine([]byte(entry.Common() + "\n"))
 
-					for key, value := range ctx.Request.Header.All() {
-						reqHeaders[string(key)] = string(value)
+				case config.AccessLogFormatCombined:
+					writeLine([]byte(entry.Combined() + "\n"))
+
+				case config.AccessLogFormatJSON:
+					if data, err := entry.JSON(o.fieldNames); err != nil {
+						log.Error("failed to encode the access log record", logger.Error(err))
+					} else {
+						writeLine(append(data, '\n'))
 					}
 
-					for key, value := range ctx.Response.Header.All() {
-						respHeaders[string(key)] = string(value)
+				case config.AccessLogFormatTemplate:
+					var buf strings.Builder
+
+					if err := o.template.Execute(&buf, entry); err != nil {
+						log.Error("failed to render the access log record", logger.Error(err))
+					} else {
+						if line := buf.String(); !strings.HasSuffix(line, "\n") {
+							buf.WriteByte('\n')
+						}
+
+						writeLine([]byte(buf.String()))
 					}
 
-					fields = append(fields,
-						logger.Any("request headers", reqHeaders),
-						logger.Any("response headers", respHeaders),
-					)
-				}
+				default:
+					var fields = []logger.Attr{
+						logger.Int("status code", entry.Status),
+						logger.String("request id", entry.RequestID),
+						logger.String("useragent", entry.UserAgent),
+						logger.String("method", entry.Method),
+						logger.String("url", entry.URI),
+						logger.String("referer", entry.Referer),
+						logger.String("content type", entry.ContentType),
+						logger.String("remote addr", ctx.RemoteAddr().String()),
+						logger.Duration("duration", entry.Duration),
+					}
 
-				log.Info("HTTP request processed", fields...)
+					for _, name := range o.requestHeaders {
+						fields = append(fields, logger.String(headerFieldName("req_", name), entry.RequestHeaders[name]))
+					}
+
+					for _, name := range o.responseHeaders {
+						fields = append(fields, logger.String(headerFieldName("resp_", name), entry.ResponseHeaders[name]))
+					}
+
+					if log.Level() <= logger.DebugLevel {
+						var (
+							reqHeaders  = make(map[string]string)
+							respHeaders = make(map[string]string)
+						)
+
+						for key, value := range ctx.Request.Header.All() {
+							reqHeaders[string(key)] = o.redaction.Redact(string(key), string(value))
+						}
+
+						for key, value := range ctx.Response.Header.All() {
+							respHeaders[string(key)] = o.redaction.Redact(string(key), string(value))
+						}
+
+						fields = append(fields,
+							logger.Any("request headers", reqHeaders),
+							logger.Any("response headers", respHeaders),
+						)
+					}
+
+					log.Info("HTTP request processed", fields...)
+				}
 			}()
 
 			next(ctx)
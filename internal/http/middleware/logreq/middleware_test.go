@@ -2,12 +2,16 @@ package logreq_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http"
 	"testing"
+	"text/template"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/valyala/fasthttp"
 
+	"gh.tarampamp.am/error-pages/internal/config"
 	"gh.tarampamp.am/error-pages/internal/http/httptest"
 	"gh.tarampamp.am/error-pages/internal/http/middleware/logreq"
 	"gh.tarampamp.am/error-pages/internal/logger"
@@ -43,4 +47,262 @@ func TestNew(t *testing.T) {
 	assert.Contains(t, logRecord, `"url":"/foo/bar"`)
 	assert.Contains(t, logRecord, `"referer":"https://example.com"`)
 	assert.Contains(t, logRecord, `application/json`)
+	assert.Contains(t, logRecord, `"request id":""`) // the request ID middleware is not used
+}
+
+func TestNew_ECSLogger(t *testing.T) {
+	t.Parallel()
+
+	var (
+		buf    bytes.Buffer
+		log, _ = logger.New(logger.InfoLevel, logger.ECSFormat, &buf)
+
+		mw     = logreq.New(log, nil)
+		req, _ = http.NewRequest(http.MethodGet, "http://testing/404.html", http.NoBody)
+	)
+
+	req.Header.Set("User-Agent", "test")
+
+	httptest.HandleFastRequest(t,
+		mw(func(ctx *fasthttp.RequestCtx) { ctx.SetStatusCode(http.StatusNotFound) }),
+		req,
+		func(int, string, http.Header) {},
+	)
+
+	var record map[string]any
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+	assert.Equal(t, "HTTP request processed", record["message"])
+	assert.EqualValues(t, http.StatusNotFound, record["http.response.status_code"])
+	assert.Equal(t, "GET", record["http.request.method"])
+	assert.Equal(t, "/404.html", record["url.original"])
+	assert.Equal(t, "test", record["user_agent.original"])
+	assert.Contains(t, record, "event.duration")
+	assert.Contains(t, record, "client.address")
+}
+
+func TestNew_AccessLogFormats(t *testing.T) {
+	t.Parallel()
+
+	var tpl = template.Must(template.New("").Parse(
+		`{{ .Method }} {{ .URI }} {{ .Status }} {{ index .RequestHeaders "X-Request-Id" }}`,
+	))
+
+	for name, tt := range map[string]struct {
+		giveOptions []logreq.Option
+		wantRegexp  string
+	}{
+		"common": {
+			giveOptions: []logreq.Option{logreq.WithFormat(config.AccessLogFormatCommon)},
+			wantRegexp:  `^\S+ - - \[\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}] "PUT /foo/bar\?baz=1 HTTP/1\.1" 404 9\n$`, //nolint:lll
+		},
+		"combined": {
+			giveOptions: []logreq.Option{logreq.WithFormat(config.AccessLogFormatCombined)},
+			wantRegexp:  `^\S+ - - \[.+] "PUT /foo/bar\?baz=1 HTTP/1\.1" 404 9 "https://example\.com" "test \\"agent\\""\n$`, //nolint:lll
+		},
+		"json": {
+			giveOptions: []logreq.Option{
+				logreq.WithFormat(config.AccessLogFormatJSON),
+				logreq.WithFieldNames(map[string]string{"status": "http_status", "req_x_request_id": "request_id"}),
+				logreq.WithRequestHeaders("X-Request-Id"),
+				logreq.WithResponseHeaders("X-Foo"),
+			},
+			wantRegexp: `^\{.*"http_status":404.*\}\n$`,
+		},
+		"template": {
+			giveOptions: []logreq.Option{
+				logreq.WithFormat(config.AccessLogFormatTemplate),
+				logreq.WithTemplate(tpl),
+				logreq.WithRequestHeaders("X-Request-Id"),
+			},
+			wantRegexp: `^PUT /foo/bar\?baz=1 404 req-id-123\n$`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				buf    bytes.Buffer
+				appLog bytes.Buffer
+				log, _ = logger.New(logger.DebugLevel, logger.JSONFormat, &appLog)
+
+				mw = logreq.New(log, nil, append(tt.giveOptions, logreq.WithWriter(&buf))...)
+
+				req, _ = http.NewRequest(http.MethodPut, "http://testing/foo/bar?baz=1", http.NoBody)
+			)
+
+			req.Header.Set("User-Agent", `test "agent"`)
+			req.Header.Set("Referer", "https://example.com")
+			req.Header.Set("X-Request-Id", "req-id-123")
+
+			httptest.HandleFastRequest(t,
+				mw(func(ctx *fasthttp.RequestCtx) {
+					ctx.Response.Header.Set("X-Foo", "bar")
+					ctx.SetStatusCode(http.StatusNotFound)
+					_, _ = ctx.WriteString("not found")
+				}),
+				req,
+				func(status int, _ string, _ http.Header) { assert.Equal(t, http.StatusNotFound, status) },
+			)
+
+			assert.Regexp(t, tt.wantRegexp, buf.String())
+			assert.Empty(t, appLog.String()) // the application log is not used
+		})
+	}
+
+	t.Run("json fields", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			buf bytes.Buffer
+			mw  = logreq.New(logger.NewNop(), nil,
+				logreq.WithFormat(config.AccessLogFormatJSON),
+				logreq.WithWriter(&buf),
+				logreq.WithFieldNames(map[string]string{"status": "http_status", "request_id": "trace.request_id"}),
+				logreq.WithRequestHeaders("X-Request-Id"),
+				logreq.WithResponseHeaders("X-Foo"),
+			)
+			req, _ = http.NewRequest(http.MethodGet, "http://testing/404", http.NoBody)
+		)
+
+		req.Header.Set("X-Request-Id", "req-id-123")
+
+		httptest.HandleFastRequest(t,
+			mw(func(ctx *fasthttp.RequestCtx) {
+				ctx.Response.Header.Set("X-Foo", "bar")
+				ctx.SetStatusCode(http.StatusNotFound)
+			}),
+			req,
+			func(int, string, http.Header) {},
+		)
+
+		var record map[string]any
+
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+		assert.EqualValues(t, 404, record["http_status"])
+		assert.Equal(t, "req-id-123", record["trace.request_id"])
+		assert.Equal(t, "req-id-123", record["req_x_request_id"])
+		assert.NotContains(t, record, "request_id")
+		assert.Equal(t, "bar", record["resp_x_foo"])
+		assert.Equal(t, "GET", record["method"])
+		assert.Equal(t, "/404", record["url"])
+		assert.NotContains(t, record, "status")
+		assert.Contains(t, record, "duration_ms")
+		assert.Contains(t, record, "time")
+	})
+
+	t.Run("default format with headers", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			buf    bytes.Buffer
+			log, _ = logger.New(logger.InfoLevel, logger.JSONFormat, &buf)
+			mw     = logreq.New(log, nil, logreq.WithRequestHeaders("X-Request-Id"))
+			req, _ = http.NewRequest(http.MethodGet, "http://testing/404", http.NoBody)
+		)
+
+		req.Header.Set("X-Request-Id", "req-id-123")
+
+		httptest.HandleFastRequest(t, mw(func(*fasthttp.RequestCtx) {}), req, func(int, string, http.Header) {})
+
+		assert.Contains(t, buf.String(), `"msg":"HTTP request processed"`)
+		assert.Contains(t, buf.String(), `"req_x_request_id":"req-id-123"`)
+		assert.NotContains(t, buf.String(), `"request headers"`) // not the debug level
+	})
+}
+
+func TestNew_AccessLogComponentLevel(t *testing.T) {
+	t.Parallel()
+
+	var (
+		buf    bytes.Buffer
+		log, _ = logger.New(logger.InfoLevel, logger.JSONFormat, &bytes.Buffer{})
+		mw     = logreq.New(log.Named("http"), nil,
+			logreq.WithFormat(config.AccessLogFormatCommon),
+			logreq.WithWriter(&buf),
+		)
+		req, _ = http.NewRequest(http.MethodGet, "http://testing/404", http.NoBody)
+	)
+
+	log.ComponentLevels().Apply(map[string]logger.Level{"http": logger.WarnLevel})
+
+	httptest.HandleFastRequest(t, mw(func(*fasthttp.RequestCtx) {}), req, func(int, string, http.Header) {})
+
+	assert.Empty(t, buf.String()) // silenced by the component level
+
+	log.ComponentLevels().Apply(map[string]logger.Level{"http": logger.InfoLevel}) // changed at runtime
+
+	httptest.HandleFastRequest(t, mw(func(*fasthttp.RequestCtx) {}), req, func(int, string, http.Header) {})
+
+	assert.Contains(t, buf.String(), `"GET /404 HTTP/1.1" 200`)
+}
+
+func TestNew_Redaction(t *testing.T) {
+	t.Parallel()
+
+	var (
+		buf    bytes.Buffer
+		log, _ = logger.New(logger.DebugLevel, logger.JSONFormat, &buf)
+
+		mw = logreq.New(log, nil,
+			logreq.WithRedaction(config.Redaction{Headers: []string{"Authorization", "Cookie", "Set-Cookie"}, KeepChars: 4}),
+			logreq.WithRequestHeaders("Authorization"),
+		)
+		req, _ = http.NewRequest(http.MethodGet, "http://testing/404", http.NoBody)
+	)
+
+	req.Header.Set("Authorization", "Bearer super-secret-token")
+	req.Header.Set("Cookie", "session=super-secret-session")
+	req.Header.Set("X-Request-Id", "req-id-123")
+
+	httptest.HandleFastRequest(t,
+		mw(func(ctx *fasthttp.RequestCtx) {
+			ctx.Response.Header.Set("Set-Cookie", "session=another-secret-session")
+			ctx.SetStatusCode(http.StatusOK)
+		}),
+		req,
+		func(int, string, http.Header) {},
+	)
+
+	var logRecord = buf.String()
+
+	assert.NotContains(t, logRecord, "super-secret")
+	assert.NotContains(t, logRecord, "another-secret")
+	assert.Contains(t, logRecord, `"Authorization":"Bear****"`)
+	assert.Contains(t, logRecord, `"req_authorization":"Bear****"`)
+	assert.Contains(t, logRecord, `"Cookie":"sess****"`)
+	assert.Contains(t, logRecord, `"Set-Cookie":"sess****"`)
+	assert.Contains(t, logRecord, `"X-Request-Id":"req-id-123"`)
+}
+
+func TestNew_Sampling(t *testing.T) {
+	t.Parallel()
+
+	var (
+		buf, appLog bytes.Buffer
+		log, _      = logger.New(logger.InfoLevel, logger.JSONFormat, &appLog)
+
+		sampler = logreq.NewSampler(log, 2, 0)
+
+		mw = logreq.New(log, nil,
+			logreq.WithFormat(config.AccessLogFormatCommon),
+			logreq.WithWriter(&buf),
+			logreq.WithSampler(sampler),
+		)
+		handler = mw(func(ctx *fasthttp.RequestCtx) { ctx.SetStatusCode(http.StatusBadGateway) })
+	)
+
+	for range 5 {
+		httptest.HandleFast(t, handler, http.MethodGet, "http://testing/502", http.NoBody, func(int, string, http.Header) {})
+	}
+
+	assert.Equal(t, 2, bytes.Count(buf.Bytes(), []byte("\n"))) // only the first 2 entries are logged
+	assert.Empty(t, appLog.String())                           // the summary is not logged by the requests
+
+	sampler.Flush()
+
+	assert.Contains(t, appLog.String(), `"msg":"HTTP access log entries dropped by sampling"`)
+	assert.Contains(t, appLog.String(), `"502":3`)
 }
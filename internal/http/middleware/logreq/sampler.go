@@ -0,0 +1,115 @@
+package logreq
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"gh.tarampamp.am/error-pages/internal/logger"
+)
+
+// Sampler limits the number of access log entries per status code: within each second, the first N entries for
+// every status code are allowed, and after that only every M-th entry is allowed. The number of dropped entries
+// is counted per status code until it is reported by the summary (see [Sampler.Flush] and [Sampler.Watch]).
+//
+// It's safe for concurrent use.
+type Sampler struct {
+	first, thereafter uint64 // N and M
+	log               *logger.Logger
+
+	mu      sync.Mutex
+	second  int64            // the current second (unix time)
+	counts  map[int]uint64   // map[status_code]entries_count (within the current second)
+	dropped map[int]uint64   // map[status_code]dropped_count (since the last summary)
+	nowFn   func() time.Time // for testing purposes
+}
+
+// NewSampler creates a new sampler. If the thereafter is zero, all the entries after the first N are dropped. The
+// summary is logged using the given logger.
+func NewSampler(log *logger.Logger, first, thereafter uint64) *Sampler {
+	return &Sampler{
+		first:      first,
+		thereafter: thereafter,
+		log:        log,
+		counts:     make(map[int]uint64),
+		dropped:    make(map[int]uint64),
+		nowFn:      time.Now,
+	}
+}
+
+// Allow reports whether the entry with the given status code should be logged.
+func (s *Sampler) Allow(code int) bool {
+	var now = s.nowFn().Unix()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now != s.second { // a new second has started - reset the counters
+		s.second = now
+		clear(s.counts)
+	}
+
+	s.counts[code]++
+
+	var n = s.counts[code]
+
+	if n <= s.first || (s.thereafter > 0 && (n-s.first)%s.thereafter == 0) {
+		return true
+	}
+
+	s.dropped[code]++
+
+	return false
+}
+
+// Flush logs the summary with the number of dropped entries per status code (if something was dropped since the
+// last summary) and resets the counters.
+func (s *Sampler) Flush() {
+	s.mu.Lock()
+
+	if len(s.dropped) == 0 {
+		s.mu.Unlock()
+
+		return
+	}
+
+	var dropped = maps.Clone(s.dropped)
+
+	clear(s.dropped)
+	s.mu.Unlock()
+
+	var (
+		codes  = slices.Sorted(maps.Keys(dropped))
+		fields = make([]logger.Attr, 0, len(codes)+1)
+		total  uint64
+	)
+
+	for _, code := range codes {
+		fields = append(fields, logger.Uint64(strconv.Itoa(code), dropped[code]))
+		total += dropped[code]
+	}
+
+	// logged as a warning, so it's not hidden when the access log is quieted using the component log level
+	s.log.Warn("HTTP access log entries dropped by sampling", append(fields, logger.Uint64("total", total))...)
+}
+
+// Watch logs the summary with the given interval until the context is canceled. The last summary is logged right
+// before returning, so the counters are not lost on shutdown.
+func (s *Sampler) Watch(ctx context.Context, interval time.Duration) {
+	var ticker = time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.Flush()
+
+			return
+		case <-ticker.C:
+			s.Flush()
+		}
+	}
+}
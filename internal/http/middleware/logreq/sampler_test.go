@@ -0,0 +1,128 @@
+package logreq
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gh.tarampamp.am/error-pages/internal/logger"
+)
+
+// syncBuffer is a [bytes.Buffer] safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func TestSampler(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now    = time.Unix(1_700_000_000, 0)
+		buf    bytes.Buffer
+		log, _ = logger.New(logger.WarnLevel, logger.JSONFormat, &buf) // e.g., the access log is quieted
+		s      = NewSampler(log, 3, 5)
+	)
+
+	s.nowFn = func() time.Time { return now }
+
+	var allowed502, allowed404 int
+
+	for range 23 {
+		if s.Allow(502) {
+			allowed502++
+		}
+	}
+
+	for range 2 {
+		if s.Allow(404) {
+			allowed404++
+		}
+	}
+
+	assert.Equal(t, 3+4, allowed502) // first 3, then every 5th of the remaining 20
+	assert.Equal(t, 2, allowed404)   // another status code has its own counter
+
+	now = now.Add(time.Second) // the next second - the counters are reset
+
+	assert.True(t, s.Allow(502))
+
+	s.Flush()
+
+	assert.Contains(t, buf.String(), `"msg":"HTTP access log entries dropped by sampling"`)
+	assert.Contains(t, buf.String(), `"level":"warn"`)
+	assert.Contains(t, buf.String(), `"502":16`)
+	assert.Contains(t, buf.String(), `"total":16`)
+
+	buf.Reset()
+	s.Flush()
+
+	assert.Empty(t, buf.String()) // nothing was dropped since the last summary
+}
+
+func TestSampler_DropAllThereafter(t *testing.T) {
+	t.Parallel()
+
+	var s = NewSampler(logger.NewNop(), 1, 0)
+
+	s.nowFn = func() time.Time { return time.Unix(1_700_000_000, 0) }
+
+	assert.True(t, s.Allow(500))
+
+	for range 10 {
+		assert.False(t, s.Allow(500))
+	}
+}
+
+func TestSampler_Watch(t *testing.T) {
+	t.Parallel()
+
+	var (
+		buf    syncBuffer
+		log, _ = logger.New(logger.InfoLevel, logger.JSONFormat, &buf)
+		s      = NewSampler(log, 0, 0) // drop everything
+
+		ctx, cancel = context.WithCancel(context.Background())
+		done        = make(chan struct{})
+	)
+
+	defer cancel()
+
+	go func() { s.Watch(ctx, 10*time.Millisecond); close(done) }()
+
+	s.Allow(502)
+
+	// the summary is logged periodically, without any further requests
+	assert.Eventually(t, func() bool { return strings.Contains(buf.String(), `"502":1`) }, time.Second, 5*time.Millisecond)
+
+	s.Allow(504)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.FailNow(t, "the watcher is not stopped")
+	}
+
+	assert.Contains(t, buf.String(), `"504":1`) // flushed on stop
+}
@@ -0,0 +1,84 @@
+package requestid
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Header is the HTTP header name used to pass the request ID (the de-facto standard, used by ingress-nginx and
+// many other proxies).
+const Header = "X-Request-Id"
+
+// maxLength limits the length of the request ID provided by the client (longer ones are replaced).
+const maxLength = 128
+
+// New creates a middleware that makes sure every request has an ID: if the incoming request has no (or an invalid)
+// `X-Request-Id` header, a new UUIDv7 is generated and set to the request header, so the next handlers (and the
+// access log) can use it. The ID is echoed in the response header.
+func New() func(fasthttp.RequestHandler) fasthttp.RequestHandler {
+	return func(next fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			// copy the value, since the slice returned by Peek may be reused when the request headers are modified
+			var id = append([]byte(nil), ctx.Request.Header.Peek(Header)...)
+
+			if !isValid(id) {
+				id = []byte(NewUUIDv7())
+
+				ctx.Request.Header.SetBytesV(Header, id)
+			}
+
+			next(ctx)
+
+			ctx.Response.Header.SetBytesV(Header, id)
+		}
+	}
+}
+
+// isValid checks if the request ID is not empty, not too long and contains only visible ASCII characters (to
+// prevent the logs and headers injection).
+func isValid(id []byte) bool {
+	if len(id) == 0 || len(id) > maxLength {
+		return false
+	}
+
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewUUIDv7 generates a new UUID version 7 (time-ordered, RFC 9562), e.g. "0190b3a4-7f2e-7c3a-9d4b-1a2b3c4d5e6f".
+func NewUUIDv7() string {
+	var (
+		u  [16]byte
+		ms = uint64(time.Now().UnixMilli()) //nolint:gosec
+	)
+
+	_, _ = rand.Read(u[6:]) // the random part
+
+	// the first 48 bits are the Unix timestamp in milliseconds
+	u[0], u[1], u[2], u[3], u[4], u[5] = byte(ms>>40), byte(ms>>32), byte(ms>>24), byte(ms>>16), byte(ms>>8), byte(ms)
+
+	u[6] = (u[6] & 0x0f) | 0x70 // version 7
+	u[8] = (u[8] & 0x3f) | 0x80 // variant 10
+
+	var buf [36]byte
+
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+
+	return string(buf[:])
+}
@@ -0,0 +1,95 @@
+package requestid_test
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+
+	"gh.tarampamp.am/error-pages/internal/http/httptest"
+	"gh.tarampamp.am/error-pages/internal/http/middleware/requestid"
+)
+
+var uuidV7 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewUUIDv7(t *testing.T) {
+	t.Parallel()
+
+	var prev string
+
+	for range 100 {
+		var id = requestid.NewUUIDv7()
+
+		assert.Regexp(t, uuidV7, id)
+		assert.NotEqual(t, prev, id)
+
+		prev = id
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	var (
+		seen    string
+		handler = requestid.New()(func(ctx *fasthttp.RequestCtx) {
+			seen = string(ctx.Request.Header.Peek(requestid.Header))
+		})
+	)
+
+	t.Run("generated", func(t *testing.T) {
+		for _, give := range []string{"", "with space", "non-ascii-é", strings.Repeat("a", 129)} {
+			req, err := http.NewRequest(http.MethodGet, "http://testing/", http.NoBody)
+			require.NoError(t, err)
+
+			if give != "" {
+				req.Header.Set(requestid.Header, give)
+			}
+
+			httptest.HandleFastRequest(t, handler, req, func(_ int, _ string, headers http.Header) {
+				assert.Regexp(t, uuidV7, seen, give)
+				assert.Equal(t, seen, headers.Get(requestid.Header))
+			})
+		}
+	})
+
+	t.Run("provided", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, "http://testing/", http.NoBody)
+		require.NoError(t, err)
+
+		req.Header.Set(requestid.Header, "abc-123")
+
+		httptest.HandleFastRequest(t, handler, req, func(_ int, _ string, headers http.Header) {
+			assert.Equal(t, "abc-123", seen)
+			assert.Equal(t, "abc-123", headers.Get(requestid.Header))
+		})
+	})
+}
+
+func TestNew_HeadersModifiedByNext(t *testing.T) {
+	t.Parallel()
+
+	var handler = requestid.New()(func(ctx *fasthttp.RequestCtx) {
+		// the next handlers may modify the request headers (e.g., the proxy mode), which may reuse the memory
+		ctx.Request.Header.Set(requestid.Header, "zzz-999")
+		ctx.Request.Header.Reset()
+
+		for i := range 64 {
+			ctx.Request.Header.Set("X-Foo-"+strconv.Itoa(i), strings.Repeat("x", 64))
+		}
+	})
+
+	req, err := http.NewRequest(http.MethodGet, "http://testing/", http.NoBody)
+	require.NoError(t, err)
+
+	req.Header.Set(requestid.Header, "abc-123")
+
+	httptest.HandleFastRequest(t, handler, req, func(_ int, _ string, headers http.Header) {
+		assert.Equal(t, "abc-123", headers.Get(requestid.Header))
+	})
+}
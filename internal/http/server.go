@@ -4,28 +4,48 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"net"
 	"net/http"
+	"net/http/fcgi"
+	"os"
 	"strings"
+	textTemplate "text/template"
 	"time"
 
 	"github.com/valyala/fasthttp"
 
 	"gh.tarampamp.am/error-pages/internal/appmeta"
 	"gh.tarampamp.am/error-pages/internal/config"
+	"gh.tarampamp.am/error-pages/internal/http/handlers/assets"
 	ep "gh.tarampamp.am/error-pages/internal/http/handlers/error_page"
 	"gh.tarampamp.am/error-pages/internal/http/handlers/live"
+	"gh.tarampamp.am/error-pages/internal/http/handlers/loglevel"
+	maintenanceHandler "gh.tarampamp.am/error-pages/internal/http/handlers/maintenance"
+	"gh.tarampamp.am/error-pages/internal/http/handlers/proxy"
 	"gh.tarampamp.am/error-pages/internal/http/handlers/static"
 	"gh.tarampamp.am/error-pages/internal/http/handlers/version"
+	"gh.tarampamp.am/error-pages/internal/http/middleware/adminauth"
+	"gh.tarampamp.am/error-pages/internal/http/middleware/cors"
 	"gh.tarampamp.am/error-pages/internal/http/middleware/logreq"
+	"gh.tarampamp.am/error-pages/internal/http/middleware/requestid"
 	"gh.tarampamp.am/error-pages/internal/logger"
+	"gh.tarampamp.am/error-pages/internal/maintenance"
+	"gh.tarampamp.am/error-pages/internal/overrides"
+	"gh.tarampamp.am/error-pages/internal/statuspage"
+	"gh.tarampamp.am/error-pages/internal/suggest"
+	"gh.tarampamp.am/error-pages/internal/template"
+	"gh.tarampamp.am/error-pages/internal/tracing"
 )
 
 // Server is an HTTP server for serving error pages.
 type Server struct {
 	log        *logger.Logger
 	server     *fasthttp.Server
+	fcgi       *fastCGIServer
+	basePath   string // the configured base path (used by the FastCGI requests mapping)
 	beforeStop func()
+	afterStop  func() // called after the server is stopped (e.g., to close the tracer and flush the access log summary)
 }
 
 // NewServer creates a new HTTP server.
@@ -46,32 +66,209 @@ func NewServer(log *logger.Logger, readBufferSize uint) Server {
 			CloseOnShutdown:              true,
 			Logger:                       logger.NewStdLog(log),
 		},
+		fcgi:       &fastCGIServer{},
 		beforeStop: func() {}, // noop
+		afterStop:  func() {}, // noop
 	}
 }
 
 // Register server handlers, middlewares, etc.
 func (s *Server) Register(cfg *config.Config) error {
+	s.basePath = cfg.BasePath
+
 	var (
-		liveHandler    = live.New()
-		versionHandler = version.New(appmeta.Version())
-		faviconHandler = static.New(static.Favicon)
+		liveHandler     = live.New()
+		versionHandler  = version.New(appmeta.Version())
+		logLevelHandler fasthttp.RequestHandler // nil if the admin endpoints are disabled
+		maintHandler    fasthttp.RequestHandler // nil if the admin endpoints are disabled
+		faviconHandler  = static.New(static.Favicon)
+		robotsHandler   fasthttp.RequestHandler // nil if the robots.txt file is not provided
+		assetsHandler   fasthttp.RequestHandler // nil if the assets directory is not configured
+		proxyHandler    fasthttp.RequestHandler // nil if the reverse proxy mode is disabled
 
-		errorPagesHandler, closeCache = ep.New(cfg, s.log)
+		epOpts  []ep.Option
+		closeFn = []func(){} // functions to call after the server shutdown (or if the registration fails)
 
 		notFound   = http.StatusText(http.StatusNotFound) + "\n"
 		notAllowed = http.StatusText(http.StatusMethodNotAllowed) + "\n"
 	)
 
-	// wrap the before shutdown function to close the cache
+	if cfg.Tracing.Endpoint != "" {
+		var tracer = tracing.New(s.log, cfg.Tracing.Endpoint, cfg.Tracing.ServiceName,
+			tracing.WithScope("error-pages", appmeta.Version()),
+		)
+
+		epOpts = append(epOpts, ep.WithTracer(tracer))
+		closeFn = append(closeFn, func() {
+			var ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second) //nolint:mnd
+			defer cancel()
+
+			if err := tracer.Close(ctx); err != nil {
+				s.log.Warn("Failed to flush the tracing spans", logger.Error(err))
+			}
+		})
+	}
+
+	if cfg.NamespacesDir != "" {
+		dir, err := overrides.Load(cfg.NamespacesDir, cfg.Templates, s.log)
+		if err != nil {
+			for _, fn := range closeFn { // the after shutdown function is not set yet
+				fn()
+			}
+
+			return fmt.Errorf("cannot load the namespace overrides: %w", err)
+		}
+
+		var ctx, cancel = context.WithCancel(context.Background())
+
+		go dir.Watch(ctx, 2*time.Second) //nolint:mnd // the ConfigMap updates are not so frequent
+
+		epOpts = append(epOpts, ep.WithOverrides(dir))
+		closeFn = append(closeFn, cancel)
+	}
+
+	if cfg.StatusPage.Source != "" {
+		var (
+			source      = statuspage.New(cfg.StatusPage.Source, s.log.Named("status-page"))
+			ctx, cancel = context.WithCancel(context.Background())
+		)
+
+		// the failures are logged only, the error pages are rendered without the incident in this case
+		go source.Watch(ctx, cfg.StatusPage.Interval)
+
+		epOpts = append(epOpts, ep.WithStatusPage(source, cfg.StatusPage.Codes))
+		closeFn = append(closeFn, cancel)
+	}
+
+	if cfg.Suggestions.Source != "" {
+		var (
+			idx         = suggest.New(cfg.Suggestions.Source, s.log.Named("suggestions"))
+			ctx, cancel = context.WithCancel(context.Background())
+		)
+
+		// the failures are logged only, the 404 error pages are rendered without the suggestions in this case
+		go idx.Watch(ctx, cfg.Suggestions.Interval)
+
+		epOpts = append(epOpts, ep.WithSuggestions(idx, int(cfg.Suggestions.Limit))) //nolint:gosec
+		closeFn = append(closeFn, cancel)
+	}
+
+	if name := cfg.Maintenance.Template; name != "" && !cfg.Templates.Has(name) {
+		for _, fn := range closeFn { // the after shutdown function is not set yet
+			fn()
+		}
+
+		return fmt.Errorf("maintenance template %q not found", name)
+	}
+
+	var maintenanceMode = maintenance.New(
+		maintenance.WithWindow(cfg.Maintenance.Start, cfg.Maintenance.End),
+		maintenance.WithFlagFile(cfg.Maintenance.FlagFile),
+	)
+
+	if cfg.Maintenance.FlagFile != "" {
+		var ctx, cancel = context.WithCancel(context.Background())
+
+		go maintenanceMode.Watch(ctx, time.Second) // the flag file is not read on every request
+
+		closeFn = append(closeFn, cancel)
+	}
+
+	epOpts = append(epOpts, ep.WithMaintenance(maintenanceMode, cfg.Maintenance.Template))
+
+	var errorPagesHandler, closeCache = ep.New(cfg, s.log.Named("render"), epOpts...)
+
+	// the cache is closed before the shutdown, and everything else (the tracer, watchers, etc.) - after the in-flight
+	// requests are served
 	s.beforeStop = closeCache
+	s.afterStop = func() {
+		for _, fn := range closeFn {
+			fn()
+		}
+	}
+
+	if cfg.AdminToken != "" {
+		logLevelHandler = adminauth.New(cfg.AdminToken, loglevel.Methods()...)(loglevel.New(s.log.ComponentLevels()))
+		maintHandler = adminauth.New(cfg.AdminToken, maintenanceHandler.Methods()...)(maintenanceHandler.New(maintenanceMode))
+	}
+
+	if cfg.AssetsDir != "" {
+		root, err := os.OpenRoot(cfg.AssetsDir) // the root protects from escaping the directory (e.g., by symlinks)
+		if err != nil {
+			s.beforeStop()
+			s.afterStop()
+
+			return fmt.Errorf("cannot open the assets directory: %w", err)
+		}
+
+		closeFn = append(closeFn, func() { _ = root.Close() })
+
+		var assetsFS = root.FS()
+
+		assetsHandler = assets.New(assetsFS, cfg.BasePath+template.AssetsPathPrefix)
+
+		// the favicon and robots.txt from the assets directory override the built-in ones
+		if content, readErr := fs.ReadFile(assetsFS, "favicon.ico"); readErr == nil {
+			faviconHandler = static.New(content)
+		}
+
+		if content, readErr := fs.ReadFile(assetsFS, "robots.txt"); readErr == nil {
+			robotsHandler = static.New(content)
+		}
+	}
+
+	if cfg.Proxy.Upstream != "" {
+		var intercept = make(config.Codes, len(cfg.Proxy.InterceptCodes)) // used for the wildcards matching only
+
+		for _, code := range cfg.Proxy.InterceptCodes {
+			intercept[code] = config.CodeDescription{}
+		}
+
+		handler, err := proxy.New(proxy.Options{
+			Upstream: cfg.Proxy.Upstream,
+			Timeout:  cfg.Proxy.Timeout,
+			Intercept: func(statusCode int) bool {
+				_, found := intercept.Find(uint16(statusCode)) //nolint:gosec
+
+				return found
+			},
+			OnError: func(ctx *fasthttp.RequestCtx, statusCode uint16) {
+				ep.ForceCode(ctx, statusCode)
+				errorPagesHandler(ctx)
+			},
+		})
+		if err != nil {
+			s.beforeStop()
+			s.afterStop()
+
+			return fmt.Errorf("cannot create the reverse proxy: %w", err)
+		}
+
+		proxyHandler = handler
+	}
 
 	s.server.Handler = func(ctx *fasthttp.RequestCtx) {
 		var url, method = string(ctx.Path()), string(ctx.Method())
 
+		// in the reverse proxy mode, everything except the own routes is forwarded to the upstream
+		if proxyHandler != nil && !isOwnRoute(url, cfg.BasePath, cfg.AdminToken != "") {
+			proxyHandler(ctx)
+
+			return
+		}
+
+		// strip the base path prefix (if configured); requests outside the base path are handled as wrong ones
+		if cfg.BasePath != "" {
+			if trimmed, ok := trimBasePath(url, cfg.BasePath); ok {
+				url = trimmed
+			} else {
+				url = ""
+			}
+		}
+
 		switch {
 		// live endpoints
-		case url == "/healthz" || url == "/health/live" || url == "/health" || url == "/live":
+		case isLiveRoute(url):
 			liveHandler(ctx)
 
 		// version endpoint
@@ -82,14 +279,31 @@ func (s *Server) Register(cfg *config.Config) error {
 		case url == "/favicon.ico":
 			faviconHandler(ctx)
 
+		// robots.txt endpoint (only if provided in the assets directory)
+		case url == "/robots.txt" && robotsHandler != nil:
+			robotsHandler(ctx)
+
+		// runtime logging levels endpoint (only if the admin token is configured)
+		case url == "/_admin/log-level" && logLevelHandler != nil:
+			logLevelHandler(ctx)
+
+		// maintenance mode endpoint (only if the admin token is configured)
+		case url == "/_admin/maintenance" && maintHandler != nil:
+			maintHandler(ctx)
+
+		// static assets endpoint (only if the assets directory is configured)
+		case strings.HasPrefix(url, template.AssetsPathPrefix+"/") && assetsHandler != nil:
+			assetsHandler(ctx)
+
 		// error pages endpoints:
 		//	- /
 		//	-	/{code}.html
 		//	- /{code}.htm
 		//	- /{code}
+		//	- /{code}.json, /{code}.xml, /{code}.txt (the format is forced by the extension)
 		//
 		// the HTTP method is not limited to GET and HEAD - it can be any
-		case url == "/" || ep.URLContainsCode(url) || ep.HeadersContainCode(&ctx.Request.Header):
+		case url == "/" || ep.URLContainsCode(url) || (url != "" && ep.RequestContainsCode(&ctx.Request, cfg.CodeSources)):
 			errorPagesHandler(ctx)
 
 		// wrong requests handling
@@ -106,34 +320,154 @@ func (s *Server) Register(cfg *config.Config) error {
 	}
 
 	// apply middleware
-	s.server.Handler = logreq.New(s.log, func(ctx *fasthttp.RequestCtx) bool {
-		// skip logging healthcheck and .ico (favicon) requests
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		var corsOpts = cors.Options{
+			AllowedOrigins: cfg.CORS.AllowedOrigins,
+			AllowedMethods: cfg.CORS.AllowedMethods,
+			AllowedHeaders: cfg.CORS.AllowedHeaders,
+			MaxAge:         cfg.CORS.MaxAge,
+		}
+
+		if proxyHandler != nil { // the preflight requests to the upstream are answered by the upstream itself
+			corsOpts.PassPreflight = func(ctx *fasthttp.RequestCtx) bool {
+				return !isOwnRoute(string(ctx.Path()), cfg.BasePath, cfg.AdminToken != "")
+			}
+		}
+
+		s.server.Handler = cors.New(corsOpts)(s.server.Handler)
+	}
+
+	var logreqOpts = []logreq.Option{
+		logreq.WithFormat(cfg.AccessLog.Format),
+		logreq.WithFieldNames(cfg.AccessLog.FieldNames),
+		logreq.WithRequestHeaders(cfg.AccessLog.RequestHeaders...),
+		logreq.WithResponseHeaders(cfg.AccessLog.ResponseHeaders...),
+		logreq.WithRedaction(cfg.Redaction),
+	}
+
+	if cfg.AccessLog.Format == config.AccessLogFormatTemplate {
+		tpl, err := textTemplate.New("access-log").Parse(cfg.AccessLog.Template)
+		if err != nil {
+			s.beforeStop()
+			s.afterStop()
+
+			return fmt.Errorf("cannot parse the access log template: %w", err)
+		}
+
+		logreqOpts = append(logreqOpts, logreq.WithTemplate(tpl))
+	}
+
+	if w := s.log.Writer(); w != nil { // e.g., the log file - the access log lines should be written there too
+		logreqOpts = append(logreqOpts, logreq.WithWriter(w))
+	}
+
+	if sampling := cfg.AccessLog.Sampling; sampling.First > 0 {
+		var (
+			sampler     = logreq.NewSampler(s.log.Named("http"), sampling.First, sampling.Thereafter)
+			ctx, cancel = context.WithCancel(context.Background())
+		)
+
+		// the summary is logged periodically, and the last one - after the server is stopped (so the entries
+		// dropped while in-flight requests are being processed are reported too)
+		go sampler.Watch(ctx, sampling.SummaryInterval)
+
+		logreqOpts = append(logreqOpts, logreq.WithSampler(sampler))
+		closeFn = append(closeFn, func() { cancel(); sampler.Flush() })
+	}
+
+	s.server.Handler = logreq.New(s.log.Named("http"), func(ctx *fasthttp.RequestCtx) bool {
+		// skip logging healthcheck, .ico (favicon) and static assets requests
 		return strings.Contains(strings.ToLower(string(ctx.UserAgent())), "healthcheck") ||
-			strings.HasSuffix(string(ctx.Path()), ".ico")
-	})(s.server.Handler)
+			strings.HasSuffix(string(ctx.Path()), ".ico") ||
+			strings.HasPrefix(string(ctx.Path()), cfg.BasePath+template.AssetsPathPrefix+"/")
+	}, logreqOpts...)(s.server.Handler)
+
+	// the request ID middleware should be the first one, so the ID is available for the access log and handlers
+	s.server.Handler = requestid.New()(s.server.Handler)
 
 	return nil
 }
 
+// trimBasePath removes the base path prefix from the URL path. It returns false if the URL is not under the base
+// path (e.g., "/_errorsfoo" is not under "/_errors"). The base path itself is treated as the root ("/").
+func trimBasePath(url, basePath string) (string, bool) {
+	var trimmed, ok = strings.CutPrefix(url, basePath)
+	if !ok {
+		return "", false
+	}
+
+	switch {
+	case trimmed == "":
+		return "/", true
+	case trimmed[0] != '/':
+		return "", false
+	}
+
+	return trimmed, true
+}
+
+// isLiveRoute reports whether the URL path is one of the liveness probe endpoints.
+func isLiveRoute(url string) bool {
+	return url == "/healthz" || url == "/health/live" || url == "/health" || url == "/live"
+}
+
+// isOwnRoute reports whether the URL path should be handled by the server itself in the reverse proxy mode. With
+// the base path configured, these are all the paths under it. Otherwise, as few paths as possible are taken from the
+// upstream: the static assets, the `/healthz` liveness probe (used by the healthcheck command, so the probes check
+// this server, not the upstream), and the admin endpoints (only if they are enabled).
+func isOwnRoute(url, basePath string, withAdmin bool) bool {
+	if basePath != "" {
+		_, ok := trimBasePath(url, basePath)
+
+		return ok
+	}
+
+	return strings.HasPrefix(url, template.AssetsPathPrefix+"/") || url == "/healthz" ||
+		(withAdmin && strings.HasPrefix(url, "/_admin/"))
+}
+
 // Start server.
-func (s *Server) Start(ip string, port uint16) (err error) {
-	if net.ParseIP(ip) == nil {
-		return errors.New("invalid IP address")
+func (s *Server) Start(ip string, port uint16) error {
+	ln, err := listen(ip, port)
+	if err != nil {
+		return err
+	}
+
+	return s.server.Serve(ln)
+}
+
+// StartFastCGI starts the FastCGI (instead of HTTP) server with the same routing. The [http.ErrServerClosed] is
+// returned after the server is stopped.
+func (s *Server) StartFastCGI(ip string, port uint16) error {
+	ln, err := listen(ip, port)
+	if err != nil {
+		return err
+	}
+
+	s.fcgi.mu.Lock()
+	s.fcgi.ln = ln
+	s.fcgi.mu.Unlock()
+
+	var handler = s.fcgi.track(newFastCGIHandler(s.server.Handler, s.basePath, fcgi.ProcessEnv, s.log))
+
+	if err = fcgi.Serve(ln, handler); errors.Is(err, net.ErrClosed) {
+		return http.ErrServerClosed
 	}
 
-	var ln net.Listener
+	return err
+}
+
+// listen creates the TCP listener for the given IP (v4 or v6) address and port.
+func listen(ip string, port uint16) (net.Listener, error) {
+	if net.ParseIP(ip) == nil {
+		return nil, errors.New("invalid IP address")
+	}
 
 	if strings.Count(ip, ":") >= 2 { //nolint:mnd // ipv6
-		if ln, err = net.Listen("tcp6", fmt.Sprintf("[%s]:%d", ip, port)); err != nil {
-			return err
-		}
-	} else { // ipv4
-		if ln, err = net.Listen("tcp4", fmt.Sprintf("%s:%d", ip, port)); err != nil {
-			return err
-		}
+		return net.Listen("tcp6", fmt.Sprintf("[%s]:%d", ip, port))
 	}
 
-	return s.server.Serve(ln)
+	return net.Listen("tcp4", fmt.Sprintf("%s:%d", ip, port)) // ipv4
 }
 
 // Stop server gracefully.
@@ -142,6 +476,11 @@ func (s *Server) Stop(timeout time.Duration) error {
 	defer cancel()
 
 	s.beforeStop()
+	defer s.afterStop()
+
+	if started, err := s.fcgi.shutdown(ctx); started {
+		return err
+	}
 
 	return s.server.ShutdownWithContext(ctx)
 }
@@ -6,6 +6,11 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,6 +19,7 @@ import (
 
 	"gh.tarampamp.am/error-pages/internal/config"
 	appHttp "gh.tarampamp.am/error-pages/internal/http"
+	"gh.tarampamp.am/error-pages/internal/logfile"
 	"gh.tarampamp.am/error-pages/internal/logger"
 )
 
@@ -182,6 +188,16 @@ func TestRouting(t *testing.T) {
 				assert.Contains(t, headers.Get("Content-Type"), "text/plain")
 			})
 
+			t.Run("code in URL, .json", func(t *testing.T) {
+				var status, body, headers = sendRequest(t,
+					http.MethodGet, baseUrl+"/502.json", map[string]string{"Accept": "text/html"},
+				)
+
+				assert.Equal(t, http.StatusOK, status)
+				assert.Contains(t, string(body), `"code": 502`)
+				assert.Contains(t, headers.Get("Content-Type"), "application/json")
+			})
+
 			t.Run("code in URL, without extension", func(t *testing.T) {
 				var status, body, headers = sendRequest(t, http.MethodGet, baseUrl+"/405")
 
@@ -314,6 +330,502 @@ func TestRouting(t *testing.T) {
 	})
 }
 
+func TestRoutingWithBasePath(t *testing.T) {
+	var (
+		srv = appHttp.NewServer(logger.NewNop(), 1025*5)
+		cfg = config.New()
+	)
+
+	assert.NoError(t, cfg.Templates.Add("unit-test", `<link rel="icon" href="{{ base_path }}/favicon.ico">{{ code }}`))
+
+	cfg.TemplateName = "unit-test"
+	cfg.BasePath = "/_errors"
+
+	require.NoError(t, srv.Register(&cfg))
+
+	var baseUrl, stopServer = startServer(t, &srv)
+
+	defer stopServer()
+
+	t.Run("routes under the base path", func(t *testing.T) {
+		for _, route := range []string{
+			"/_errors/healthz", "/_errors/health", "/_errors/version", "/_errors/favicon.ico",
+			"/_errors", "/_errors/", "/_errors/404", "/_errors/503.html",
+		} {
+			status, body, _ := sendRequest(t, http.MethodGet, baseUrl+route)
+
+			assert.Equal(t, http.StatusOK, status, route)
+			assert.NotEmpty(t, body, route)
+		}
+	})
+
+	t.Run("code in the URL", func(t *testing.T) {
+		var status, body, _ = sendRequest(t, http.MethodGet, baseUrl+"/_errors/503.html")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, string(body), "503: Service Unavailable")
+	})
+
+	t.Run("base path is available in templates", func(t *testing.T) {
+		var status, body, _ = sendRequest(t,
+			http.MethodGet, baseUrl+"/_errors/404", map[string]string{"Accept": "text/html"},
+		)
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, string(body), `/_errors/favicon.ico`)
+	})
+
+	t.Run("routes outside the base path", func(t *testing.T) {
+		for _, route := range []string{"/", "/healthz", "/version", "/favicon.ico", "/404", "/_errorsfoo", "/foo/_errors"} {
+			status, _, _ := sendRequest(t, http.MethodGet, baseUrl+route, map[string]string{"X-Code": "500"})
+
+			assert.Equal(t, http.StatusNotFound, status, route)
+		}
+	})
+}
+
+func TestRoutingWithAssets(t *testing.T) {
+	var (
+		srv       = appHttp.NewServer(logger.NewNop(), 1025*5)
+		cfg       = config.New()
+		assetsDir = t.TempDir()
+	)
+
+	require.NoError(t, os.WriteFile(filepath.Join(assetsDir, "logo.svg"), []byte("<svg></svg>"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(assetsDir, "favicon.ico"), []byte("custom favicon"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(assetsDir, "robots.txt"), []byte("User-agent: *\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(t.TempDir(), "secret.txt"), []byte("secret"), 0o600))
+
+	assert.NoError(t, cfg.Templates.Add("unit-test", `<img src="{{ asset "logo.svg" }}">`))
+
+	cfg.TemplateName = "unit-test"
+	cfg.BasePath = "/_errors"
+	cfg.AssetsDir = assetsDir
+
+	require.NoError(t, srv.Register(&cfg))
+
+	var baseUrl, stopServer = startServer(t, &srv)
+
+	defer stopServer()
+
+	t.Run("asset", func(t *testing.T) {
+		var status, body, headers = sendRequest(t, http.MethodGet, baseUrl+"/_errors/_assets/logo.svg")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "<svg></svg>", string(body))
+		assert.Equal(t, "image/svg+xml", headers.Get("Content-Type"))
+		assert.NotEmpty(t, headers.Get("Cache-Control"))
+	})
+
+	t.Run("asset URL in the template", func(t *testing.T) {
+		var _, body, _ = sendRequest(t, http.MethodGet, baseUrl+"/_errors/404", map[string]string{"Accept": "text/html"})
+
+		assert.Contains(t, string(body), "/_errors/_assets/logo.svg")
+	})
+
+	t.Run("favicon and robots.txt overrides", func(t *testing.T) {
+		var status, body, _ = sendRequest(t, http.MethodGet, baseUrl+"/_errors/favicon.ico")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "custom favicon", string(body))
+
+		status, body, _ = sendRequest(t, http.MethodGet, baseUrl+"/_errors/robots.txt")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "User-agent: *\n", string(body))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		for _, route := range []string{
+			"/_errors/_assets/missing.svg",
+			"/_errors/_assets/../secret.txt",
+			"/_errors/_assets/%2e%2e/%2e%2e/secret.txt",
+			"/_assets/logo.svg",
+		} {
+			status, _, _ := sendRequest(t, http.MethodGet, baseUrl+route)
+
+			assert.Equal(t, http.StatusNotFound, status, route)
+		}
+	})
+}
+
+func TestRoutingWithCORS(t *testing.T) {
+	var (
+		srv = appHttp.NewServer(logger.NewNop(), 1025*5)
+		cfg = config.New()
+	)
+
+	cfg.CORS.AllowedOrigins = []string{"https://app.example.com"}
+
+	require.NoError(t, srv.Register(&cfg))
+
+	var baseUrl, stopServer = startServer(t, &srv)
+
+	defer stopServer()
+
+	t.Run("preflight", func(t *testing.T) {
+		var status, body, headers = sendRequest(t, http.MethodOptions, baseUrl+"/404", map[string]string{
+			"Origin":                        "https://app.example.com",
+			"Access-Control-Request-Method": http.MethodGet,
+		})
+
+		assert.Equal(t, http.StatusNoContent, status)
+		assert.Empty(t, body)
+		assert.Equal(t, "https://app.example.com", headers.Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET, HEAD", headers.Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "600", headers.Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("error page", func(t *testing.T) {
+		var status, body, headers = sendRequest(t, http.MethodGet, baseUrl+"/404", map[string]string{
+			"Origin": "https://app.example.com",
+			"Accept": "application/json",
+		})
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, string(body), `"code": 404`)
+		assert.Equal(t, "https://app.example.com", headers.Get("Access-Control-Allow-Origin"))
+	})
+}
+
+func TestRoutingWithRequestID(t *testing.T) {
+	var (
+		srv = appHttp.NewServer(logger.NewNop(), 1025*5)
+		cfg = config.New()
+	)
+
+	require.NoError(t, srv.Register(&cfg))
+
+	var baseUrl, stopServer = startServer(t, &srv)
+
+	defer stopServer()
+
+	t.Run("generated", func(t *testing.T) {
+		var status, body, headers = sendRequest(t, http.MethodGet, baseUrl+"/500.json")
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, headers.Get("X-Request-Id"))
+		assert.Contains(t, string(body), `"request_id": "`+headers.Get("X-Request-Id")+`"`)
+	})
+
+	t.Run("provided", func(t *testing.T) {
+		var status, body, headers = sendRequest(t, http.MethodGet, baseUrl+"/500.txt", map[string]string{
+			"X-Request-Id": "abc-123",
+		})
+
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "abc-123", headers.Get("X-Request-Id"))
+		assert.Contains(t, string(body), "Request ID: abc-123")
+	})
+}
+
+func TestRoutingWithAccessLogFile(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "app.log")
+
+	file, err := logfile.Open(path, logfile.Options{})
+	require.NoError(t, err)
+
+	defer func() { _ = file.Close() }()
+
+	var (
+		log, _ = logger.New(logger.InfoLevel, logger.JSONFormat, file)
+		srv    = appHttp.NewServer(log, 1025*5)
+		cfg    = config.New()
+	)
+
+	cfg.AccessLog.Format = config.AccessLogFormatCommon
+
+	require.NoError(t, srv.Register(&cfg))
+
+	var baseUrl, stopServer = startServer(t, &srv)
+
+	status, _, _ := sendRequest(t, http.MethodGet, baseUrl+"/404.txt")
+	assert.Equal(t, http.StatusOK, status)
+
+	stopServer()
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// the access log line (in the non-default format) is written to the log file, not to stdout
+	assert.Regexp(t, `(?m)^\S+ - - \[[^]]+\] "GET /404\.txt HTTP/1\.1" 200 \d+$`, string(content))
+}
+
+func TestRoutingWithAdminToken(t *testing.T) {
+	var (
+		log, _ = logger.New(logger.InfoLevel, logger.JSONFormat, io.Discard)
+		srv    = appHttp.NewServer(log, 1025*5)
+		cfg    = config.New()
+	)
+
+	cfg.AdminToken = "secret"
+
+	require.NoError(t, srv.Register(&cfg))
+
+	var baseUrl, stopServer = startServer(t, &srv)
+
+	defer stopServer()
+
+	status, _, _ := sendRequest(t, http.MethodGet, baseUrl+"/_admin/log-level")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, body, _ := sendRequest(t, http.MethodGet, baseUrl+"/_admin/log-level", map[string]string{
+		"Authorization": "Bearer secret",
+	})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "info\n", string(body))
+
+	req, err := http.NewRequest(http.MethodPut, baseUrl+"/_admin/log-level", strings.NewReader("http=debug"))
+	require.NoError(t, err)
+
+	req.Header.Set("Authorization", "Bearer secret")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, logger.DebugLevel, log.Named("http").Level())
+}
+
+func TestRoutingWithMaintenance(t *testing.T) {
+	var (
+		srv = appHttp.NewServer(logger.NewNop(), 1025*5)
+		cfg = config.New()
+	)
+
+	cfg.AdminToken = "secret"
+	cfg.Maintenance.Template = "ghost"
+
+	require.NoError(t, srv.Register(&cfg))
+
+	var baseUrl, stopServer = startServer(t, &srv)
+
+	defer stopServer()
+
+	var (
+		auth       = map[string]string{"Authorization": "Bearer secret"}
+		acceptJSON = map[string]string{"Accept": "application/json"}
+	)
+
+	var setMaintenance = func(t *testing.T, method, body string) {
+		t.Helper()
+
+		req, err := http.NewRequest(method, baseUrl+"/_admin/maintenance", strings.NewReader(body))
+		require.NoError(t, err)
+
+		req.Header.Set("Authorization", "Bearer secret")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	status, body, _ := sendRequest(t, http.MethodGet, baseUrl+"/_admin/maintenance", auth)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "inactive\n", string(body))
+
+	setMaintenance(t, http.MethodPut, "1h")
+
+	status, body, headers := sendRequest(t, http.MethodGet, baseUrl+"/404", acceptJSON)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Contains(t, string(body), `"code": 503`)
+	assert.Contains(t, string(body), `"maintenance": {`)
+
+	retryAfter, err := strconv.Atoi(headers.Get("Retry-After"))
+	require.NoError(t, err)
+	assert.InDelta(t, 3600, retryAfter, 60)
+
+	status, body, _ = sendRequest(t, http.MethodGet, baseUrl+"/500", map[string]string{"Accept": "text/html"})
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Contains(t, string(body), "503")
+
+	setMaintenance(t, http.MethodDelete, "")
+
+	status, body, headers = sendRequest(t, http.MethodGet, baseUrl+"/404", acceptJSON)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, string(body), `"code": 404`)
+	assert.NotContains(t, string(body), "maintenance")
+	assert.Empty(t, headers.Get("Retry-After"))
+}
+
+func TestRoutingWithProxy(t *testing.T) {
+	var upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok", "/_errors/ok", "/health", "/live", "/_admin/status":
+			_, _ = fmt.Fprint(w, "upstream content")
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = fmt.Fprint(w, "stack trace")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	defer upstream.Close()
+
+	for name, basePath := range map[string]string{"without base path": "", "with base path": "/_errors"} {
+		t.Run(name, func(t *testing.T) {
+			var (
+				srv = appHttp.NewServer(logger.NewNop(), 1025*5)
+				cfg = config.New()
+			)
+
+			cfg.BasePath = basePath
+			cfg.Proxy.Upstream = upstream.URL
+
+			require.NoError(t, srv.Register(&cfg))
+
+			var baseUrl, stopServer = startServer(t, &srv)
+
+			defer stopServer()
+
+			status, body, _ := sendRequest(t, http.MethodGet, baseUrl+"/ok")
+			assert.Equal(t, http.StatusOK, status)
+			assert.Equal(t, "upstream content", string(body))
+
+			status, body, headers := sendRequest(t, http.MethodGet, baseUrl+"/fail", map[string]string{
+				"Accept": "application/json",
+			})
+			assert.Equal(t, http.StatusInternalServerError, status)
+			assert.Contains(t, headers.Get("Content-Type"), "application/json")
+			assert.Contains(t, string(body), `"code": 500`)
+			assert.NotContains(t, string(body), "stack trace")
+
+			status, body, _ = sendRequest(t, http.MethodGet, baseUrl+"/missing", map[string]string{
+				"Accept": "text/plain",
+			})
+			assert.Equal(t, http.StatusNotFound, status)
+			assert.Contains(t, string(body), "Error 404: Not Found")
+
+			// the liveness probes check this server, not the upstream
+			status, _, _ = sendRequest(t, http.MethodGet, baseUrl+basePath+"/healthz")
+			assert.Equal(t, http.StatusOK, status)
+
+			// the upstream's own health and admin endpoints are not taken over (the admin token is not set)
+			for _, path := range []string{"/health", "/live", "/_admin/status"} {
+				status, body, _ = sendRequest(t, http.MethodGet, baseUrl+path)
+				assert.Equal(t, http.StatusOK, status, path)
+				assert.Equal(t, "upstream content", string(body), path)
+			}
+
+			if basePath != "" { // the own routes are available under the base path
+				status, body, _ = sendRequest(t, http.MethodGet, baseUrl+basePath+"/500.txt")
+				assert.Equal(t, http.StatusOK, status)
+				assert.Contains(t, string(body), "Error 500")
+			}
+		})
+	}
+}
+
+func TestRoutingWithProxyAndCORS(t *testing.T) {
+	var upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Access-Control-Allow-Origin", "https://upstream.example.com")
+			w.Header().Set("Access-Control-Allow-Methods", "PUT")
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	defer upstream.Close()
+
+	var (
+		srv = appHttp.NewServer(logger.NewNop(), 1025*5)
+		cfg = config.New()
+	)
+
+	cfg.CORS.AllowedOrigins = []string{"https://app.example.com"}
+	cfg.Proxy.Upstream = upstream.URL
+	cfg.BasePath = "/_errors"
+
+	require.NoError(t, srv.Register(&cfg))
+
+	var baseUrl, stopServer = startServer(t, &srv)
+
+	defer stopServer()
+
+	var preflight = map[string]string{
+		"Origin":                        "https://app.example.com",
+		"Access-Control-Request-Method": http.MethodPut,
+	}
+
+	// the preflight request to the upstream is answered by the upstream
+	status, _, headers := sendRequest(t, http.MethodOptions, baseUrl+"/api", preflight)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "https://upstream.example.com", headers.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "PUT", headers.Get("Access-Control-Allow-Methods"))
+
+	// and the preflight request to the own routes is answered by the server
+	status, _, headers = sendRequest(t, http.MethodOptions, baseUrl+"/_errors/404", preflight)
+	assert.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, "https://app.example.com", headers.Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, HEAD", headers.Get("Access-Control-Allow-Methods"))
+}
+
+func TestServer_RegisterErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing assets directory", func(t *testing.T) {
+		var (
+			srv = appHttp.NewServer(logger.NewNop(), 1025*5)
+			cfg = config.New()
+		)
+
+		cfg.AssetsDir = filepath.Join(t.TempDir(), "missing")
+
+		assert.ErrorContains(t, srv.Register(&cfg), "cannot open the assets directory")
+	})
+
+	t.Run("broken namespace overrides", func(t *testing.T) {
+		var (
+			srv = appHttp.NewServer(logger.NewNop(), 1025*5)
+			cfg = config.New()
+		)
+
+		cfg.NamespacesDir = t.TempDir()
+
+		require.NoError(t, os.WriteFile(filepath.Join(cfg.NamespacesDir, "foo.json"), []byte("{"), 0o600))
+
+		assert.ErrorContains(t, srv.Register(&cfg), "cannot load the namespace overrides")
+	})
+
+	t.Run("wrong proxy upstream", func(t *testing.T) {
+		var (
+			srv = appHttp.NewServer(logger.NewNop(), 1025*5)
+			cfg = config.New()
+		)
+
+		cfg.Proxy.Upstream = "ftp://example.com"
+
+		assert.ErrorContains(t, srv.Register(&cfg), "cannot create the reverse proxy")
+	})
+
+	t.Run("unknown maintenance template", func(t *testing.T) {
+		var (
+			srv = appHttp.NewServer(logger.NewNop(), 1025*5)
+			cfg = config.New()
+		)
+
+		cfg.Maintenance.Template = "foo"
+
+		assert.ErrorContains(t, srv.Register(&cfg), `maintenance template "foo" not found`)
+	})
+
+	t.Run("wrong access log template", func(t *testing.T) {
+		var (
+			srv = appHttp.NewServer(logger.NewNop(), 1025*5)
+			cfg = config.New()
+		)
+
+		cfg.AccessLog.Format = config.AccessLogFormatTemplate
+		cfg.AccessLog.Template = "{{ .Method"
+
+		assert.ErrorContains(t, srv.Register(&cfg), "cannot parse the access log template")
+	})
+}
+
 // sendRequest is a helper function to send an HTTP request and return its status code, body, and headers.
 func sendRequest(t *testing.T, method, url string, headers ...map[string]string) (
 	status int,
@@ -348,7 +860,7 @@ func startServer(t *testing.T, srv *appHttp.Server) (_ string, stop func()) {
 
 	var (
 		port     = getFreeTcpPort(t)
-		hostPort = fmt.Sprintf("%s:%d", "127.0.0.1", port) //nolint:govet
+		hostPort = net.JoinHostPort("127.0.0.1", strconv.Itoa(int(port)))
 	)
 
 	go func() {
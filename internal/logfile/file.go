@@ -0,0 +1,361 @@
+// Package logfile provides a log file writer with rotation by size and age, retention of the rotated files, and
+// their optional compression.
+package logfile
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the time format used in the rotated file names (the lexical order is the chronological one).
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+type (
+	// Options are the log file rotation settings. Zero values disable the corresponding feature.
+	Options struct {
+		MaxSize    int64         // the file is rotated when its size exceeds this value (in bytes)
+		MaxAge     time.Duration // the file is rotated when it's older than this value
+		MaxBackups uint          // the number of rotated files to keep (zero means all)
+		Compress   bool          // compress the rotated files using gzip
+	}
+
+	// File is an [io.WriteCloser] that writes to the file and rotates it when needed. The rotated files are renamed
+	// to "<name>-<timestamp><ext>" (e.g., "app-2024-01-02T15-04-05.000.log") in the same directory. It's safe for
+	// concurrent use.
+	File struct {
+		path string
+		opts Options
+
+		mu        sync.Mutex
+		file      *os.File // nil if the file is closed or cannot be (re)opened
+		closed    bool     // set by [File.Close]
+		size      int64
+		startedAt time.Time // when the current file was started (survives the restarts and reopening)
+
+		millMu sync.Mutex     // serializes the compression and removal of the rotated files
+		millWg sync.WaitGroup // tracks the running mill goroutines
+
+		nowFn func() time.Time // for testing purposes
+	}
+)
+
+var _ io.WriteCloser = (*File)(nil) // verify interface implementation
+
+// Open opens (or creates) the log file for appending. The directory is created if needed.
+func Open(path string, opts Options) (*File, error) {
+	var f = File{path: path, opts: opts, nowFn: time.Now}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { //nolint:mnd
+		return nil, fmt.Errorf("cannot create the log directory: %w", err)
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// open opens the file at the path. The caller must hold the lock.
+func (f *File) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644) //nolint:mnd
+	if err != nil {
+		return fmt.Errorf("cannot open the log file: %w", err)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+
+		return fmt.Errorf("cannot stat the log file: %w", err)
+	}
+
+	f.file, f.size, f.startedAt = file, stat.Size(), f.startTime(stat)
+
+	return nil
+}
+
+// startTime returns the time when the opened file was started. An empty file is a new one. For a non-empty file,
+// the time of the last rotation (from the newest rotated file name) is used, since the current file was created
+// right after it. Without the rotated files, the modification time is used (the file is at least that old).
+func (f *File) startTime(stat os.FileInfo) time.Time {
+	if stat.Size() == 0 {
+		return f.nowFn()
+	}
+
+	if backups := f.backups(); len(backups) > 0 {
+		if t, ok := f.backupTime(filepath.Base(backups[len(backups)-1])); ok {
+			return t
+		}
+	}
+
+	return stat.ModTime()
+}
+
+// Write writes the data to the file, rotating it before the writing if the size or age limit is reached.
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+
+	if f.file == nil { // the previous rotation or reopening failed, so try again
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if f.size > 0 && ((f.opts.MaxSize > 0 && f.size+int64(len(p)) > f.opts.MaxSize) ||
+		(f.opts.MaxAge > 0 && f.nowFn().Sub(f.startedAt) >= f.opts.MaxAge)) {
+		if err := f.rotate(); err != nil {
+			if f.file == nil {
+				return 0, err
+			}
+
+			n, _ := f.file.Write(p) // the data is not lost when the file cannot be rotated
+			f.size += int64(n)
+
+			return n, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+
+	return n, err
+}
+
+// Rotate forces the file rotation.
+func (f *File) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+
+	return f.rotate()
+}
+
+// Reopen closes and opens the file at the same path again. It's useful when the file was moved by an external
+// tool (like logrotate), so the new file is created. If the file wasn't moved, its start time is kept.
+func (f *File) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+
+	var (
+		prevStat  os.FileInfo
+		prevStart = f.startedAt
+	)
+
+	if f.file != nil {
+		prevStat, _ = f.file.Stat()
+
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+
+		f.file = nil
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	if stat, err := f.file.Stat(); err == nil && prevStat != nil && os.SameFile(prevStat, stat) {
+		f.startedAt = prevStart
+	}
+
+	return nil
+}
+
+// Close closes the file and waits for the rotated files processing to complete.
+func (f *File) Close() error {
+	f.mu.Lock()
+
+	var err error
+
+	if f.file != nil {
+		err, f.file = f.file.Close(), nil
+	}
+
+	f.closed = true
+
+	f.mu.Unlock()
+
+	f.millWg.Wait()
+
+	return err
+}
+
+// rotate renames the current file, opens a new one, and starts the rotated files processing in the background.
+// If the renaming fails, the current file is opened again, so the writing continues. If the new file cannot be
+// opened, the next write tries again. The caller must hold the lock.
+func (f *File) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	f.file = nil
+
+	var (
+		ext    = filepath.Ext(f.path)
+		backup = strings.TrimSuffix(f.path, ext) + "-" + f.nowFn().Format(backupTimeFormat) + ext
+	)
+
+	if err := os.Rename(f.path, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		err = fmt.Errorf("cannot rename the log file: %w", err)
+
+		if openErr := f.open(); openErr != nil {
+			return errors.Join(err, openErr)
+		}
+
+		f.startedAt = f.nowFn() // do not retry the rotation by age on every write
+
+		return err
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.millWg.Add(1)
+
+	go func() { defer f.millWg.Done(); f.mill() }()
+
+	return nil
+}
+
+// mill compresses the rotated files (if enabled) and removes the ones exceeding the retention count. Errors are
+// ignored, since there is no place to report them (the log file itself is the one being processed).
+func (f *File) mill() {
+	f.millMu.Lock()
+	defer f.millMu.Unlock()
+
+	var backups = f.backups()
+
+	if f.opts.Compress {
+		for i, name := range backups {
+			if strings.HasSuffix(name, ".gz") {
+				continue
+			}
+
+			if err := compress(name); err == nil {
+				backups[i] = name + ".gz"
+			}
+		}
+	}
+
+	if f.opts.MaxBackups > 0 && uint(len(backups)) > f.opts.MaxBackups {
+		for _, name := range backups[:uint(len(backups))-f.opts.MaxBackups] { // the oldest ones
+			_ = os.Remove(name)
+		}
+	}
+}
+
+// backups returns the rotated files paths sorted from the oldest to the newest.
+func (f *File) backups() []string {
+	var (
+		prefix = filepath.Base(strings.TrimSuffix(f.path, filepath.Ext(f.path))) + "-"
+		dir    = filepath.Dir(f.path)
+	)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var result = make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		var name = entry.Name()
+
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		if _, ok := f.backupTime(name); ok {
+			result = append(result, filepath.Join(dir, name))
+		}
+	}
+
+	slices.Sort(result)
+
+	return result
+}
+
+// backupTime parses the rotation time from the rotated file name (without the directory).
+func (f *File) backupTime(name string) (time.Time, bool) {
+	var (
+		ext    = filepath.Ext(f.path)
+		prefix = filepath.Base(strings.TrimSuffix(f.path, ext)) + "-"
+	)
+
+	if !strings.HasPrefix(name, prefix) {
+		return time.Time{}, false
+	}
+
+	var ts = strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz"), ext)
+
+	t, err := time.ParseInLocation(backupTimeFormat, ts, f.nowFn().Location())
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// compress compresses the file using gzip and removes the original one.
+func compress(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = src.Close() }()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644) //nolint:mnd
+	if err != nil {
+		return err
+	}
+
+	var gz = gzip.NewWriter(dst)
+
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(path + ".gz")
+
+		return err
+	}
+
+	_ = src.Close()
+
+	return os.Remove(path)
+}
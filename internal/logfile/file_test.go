@@ -0,0 +1,247 @@
+package logfile
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock returns a function that returns the current fake time, and a function to move the time forward.
+func fakeClock() (now func() time.Time, advance func(time.Duration)) {
+	var current = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	return func() time.Time { return current }, func(d time.Duration) { current = current.Add(d) }
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+func TestFile_RotateBySize(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dir          = t.TempDir()
+		path         = filepath.Join(dir, "logs", "app.log") // the directory is created automatically
+		now, advance = fakeClock()
+	)
+
+	f, err := Open(path, Options{MaxSize: 10, MaxBackups: 2})
+	require.NoError(t, err)
+
+	f.nowFn = now
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, wErr := f.Write([]byte(line))
+		require.NoError(t, wErr)
+
+		advance(time.Second)
+	}
+
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, "fourth\n", readFile(t, path))
+	assert.Equal(t, "second\n", readFile(t, filepath.Join(dir, "logs", "app-2024-01-02T03-04-07.000.log")))
+	assert.Equal(t, "third\n", readFile(t, filepath.Join(dir, "logs", "app-2024-01-02T03-04-08.000.log")))
+	assert.NoFileExists(t, filepath.Join(dir, "logs", "app-2024-01-02T03-04-06.000.log")) // removed (retention)
+
+	_, err = f.Write([]byte("closed"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestFile_RotateByAge(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dir          = t.TempDir()
+		path         = filepath.Join(dir, "app.log")
+		now, advance = fakeClock()
+	)
+
+	f, err := Open(path, Options{MaxAge: time.Hour})
+	require.NoError(t, err)
+
+	f.nowFn, f.startedAt = now, now()
+
+	_, _ = f.Write([]byte("old\n"))
+
+	advance(30 * time.Minute)
+
+	_, _ = f.Write([]byte("still old\n"))
+
+	advance(30 * time.Minute)
+
+	_, _ = f.Write([]byte("new\n"))
+
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, "new\n", readFile(t, path))
+	assert.Equal(t, "old\nstill old\n", readFile(t, filepath.Join(dir, "app-2024-01-02T04-04-05.000.log")))
+}
+
+func TestFile_Compress(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dir  = t.TempDir()
+		path = filepath.Join(dir, "app.log")
+	)
+
+	f, err := Open(path, Options{Compress: true})
+	require.NoError(t, err)
+
+	f.nowFn, _ = fakeClock()
+
+	_, _ = f.Write([]byte("to be compressed\n"))
+
+	require.NoError(t, f.Rotate())
+	require.NoError(t, f.Close()) // waits for the compression
+
+	var gzPath = filepath.Join(dir, "app-2024-01-02T03-04-05.000.log.gz")
+
+	assert.NoFileExists(t, filepath.Join(dir, "app-2024-01-02T03-04-05.000.log"))
+	require.FileExists(t, gzPath)
+
+	gzFile, err := os.Open(gzPath)
+	require.NoError(t, err)
+
+	defer func() { _ = gzFile.Close() }()
+
+	gz, err := gzip.NewReader(gzFile)
+	require.NoError(t, err)
+
+	content, err := io.ReadAll(gz)
+	require.NoError(t, err)
+
+	assert.Equal(t, "to be compressed\n", string(content))
+	assert.Empty(t, readFile(t, path))
+}
+
+func TestFile_Reopen(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dir  = t.TempDir()
+		path = filepath.Join(dir, "app.log")
+	)
+
+	f, err := Open(path, Options{})
+	require.NoError(t, err)
+
+	_, _ = f.Write([]byte("before\n"))
+
+	require.NoError(t, os.Rename(path, path+".1")) // like the logrotate does
+
+	_, _ = f.Write([]byte("still to the moved file\n"))
+
+	require.NoError(t, f.Reopen())
+
+	_, _ = f.Write([]byte("after\n"))
+
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, "before\nstill to the moved file\n", readFile(t, path+".1"))
+	assert.Equal(t, "after\n", readFile(t, path))
+	assert.ErrorIs(t, f.Reopen(), os.ErrClosed)
+}
+
+func TestFile_RotateRenameFailure(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dir  = t.TempDir()
+		path = filepath.Join(dir, "app.log")
+	)
+
+	f, err := Open(path, Options{MaxSize: 10})
+	require.NoError(t, err)
+
+	f.nowFn, _ = fakeClock()
+
+	// the non-empty directory with the rotated file name makes the renaming fail
+	var blocker = filepath.Join(dir, "app-2024-01-02T03-04-05.000.log")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(blocker, "sub"), 0o755))
+
+	_, err = f.Write([]byte("first\n"))
+	require.NoError(t, err)
+
+	n, err := f.Write([]byte("second\n"))
+	require.Error(t, err)
+	assert.Equal(t, len("second\n"), n) // written to the current file anyway
+
+	require.NoError(t, os.RemoveAll(blocker))
+
+	_, err = f.Write([]byte("third\n")) // the rotation succeeds now
+	require.NoError(t, err)
+
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, "third\n", readFile(t, path))
+	assert.Equal(t, "first\nsecond\n", readFile(t, blocker))
+}
+
+func TestFile_AgeSurvivesRestart(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dir          = t.TempDir()
+		path         = filepath.Join(dir, "app.log")
+		now, advance = fakeClock()
+	)
+
+	f, err := Open(path, Options{MaxAge: time.Hour})
+	require.NoError(t, err)
+
+	f.nowFn = now
+
+	_, _ = f.Write([]byte("rotated\n"))
+
+	require.NoError(t, f.Rotate()) // the current file is started at 03:04:05
+
+	_, _ = f.Write([]byte("old\n"))
+
+	require.NoError(t, f.Reopen()) // the same file, so the start time is kept
+	assert.Equal(t, now(), f.startedAt)
+
+	require.NoError(t, f.Close())
+
+	advance(50 * time.Minute)
+
+	f, err = Open(path, Options{MaxAge: time.Hour}) // restart
+	require.NoError(t, err)
+
+	f.nowFn = now
+	f.startedAt = f.startTime(mustStat(t, path)) // the location of the fake clock is used for parsing
+
+	_, _ = f.Write([]byte("still old\n"))
+
+	advance(10 * time.Minute)
+
+	_, _ = f.Write([]byte("new\n"))
+
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, "new\n", readFile(t, path))
+	assert.Equal(t, "old\nstill old\n", readFile(t, filepath.Join(dir, "app-2024-01-02T04-04-05.000.log")))
+}
+
+func mustStat(t *testing.T, path string) os.FileInfo {
+	t.Helper()
+
+	stat, err := os.Stat(path)
+	require.NoError(t, err)
+
+	return stat
+}
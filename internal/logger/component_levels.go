@@ -0,0 +1,159 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// ComponentLevels holds the logging levels of the named loggers (components, e.g., "http" or "render"). The
+	// components without their own level use the default one. The levels can be changed at runtime, and it's safe
+	// for concurrent use.
+	ComponentLevels struct {
+		mu    sync.Mutex // serializes the updates
+		state atomic.Pointer[componentLevelsState]
+	}
+
+	componentLevelsState struct {
+		def   Level
+		named map[string]Level // immutable, a new map is created on every update
+	}
+)
+
+// newComponentLevels creates a new registry with the given default level.
+func newComponentLevels(def Level) *ComponentLevels {
+	var c ComponentLevels
+
+	c.state.Store(&componentLevelsState{def: def})
+
+	return &c
+}
+
+// Get returns the level of the component (or the default level, if the component has no own level). The empty
+// name means the default level.
+func (c *ComponentLevels) Get(name string) Level {
+	var s = c.state.Load()
+
+	if l, ok := s.named[name]; ok {
+		return l
+	}
+
+	return s.def
+}
+
+// Apply sets the levels from the map, where the key is the component name and the empty key means the default
+// level (see [ParseLevelSpec]). The levels of the components not listed in the map are not changed.
+func (c *ComponentLevels) Apply(levels map[string]Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var (
+		current = c.state.Load()
+		next    = componentLevelsState{def: current.def, named: maps.Clone(current.named)}
+	)
+
+	for name, l := range levels {
+		if name == "" {
+			next.def = l
+
+			continue
+		}
+
+		if next.named == nil {
+			next.named = make(map[string]Level, len(levels))
+		}
+
+		next.named[name] = l
+	}
+
+	c.state.Store(&next)
+}
+
+// String returns the levels in the same format [ParseLevelSpec] accepts (e.g., "info,http=warn,render=debug").
+func (c *ComponentLevels) String() string {
+	var (
+		s     = c.state.Load()
+		parts = make([]string, 0, len(s.named)+1)
+	)
+
+	parts = append(parts, s.def.String())
+
+	for _, name := range slices.Sorted(maps.Keys(s.named)) {
+		parts = append(parts, name+"="+s.named[name].String())
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// ParseLevelSpec parses the comma-separated list of levels, where each item is either the default level (e.g.,
+// "info") or the component level in the "name=level" format (e.g., "http=warn"). The result map uses the empty key
+// for the default level.
+func ParseLevelSpec(text string) (map[string]Level, error) {
+	var result = make(map[string]Level)
+
+	for _, item := range strings.Split(text, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+
+		var name, value, named = strings.Cut(item, "=")
+
+		if !named {
+			name, value = "", item
+		} else if name = strings.TrimSpace(name); name == "" {
+			return nil, fmt.Errorf("missing component name in the logging level: %q", item)
+		}
+
+		l, err := ParseLevel(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+
+		result[name] = l
+	}
+
+	return result, nil
+}
+
+// toSlog converts the level into the [slog.Level].
+func (l Level) toSlog() slog.Level {
+	switch l {
+	case DebugLevel:
+		return slog.LevelDebug
+	case InfoLevel:
+		return slog.LevelInfo
+	case WarnLevel:
+		return slog.LevelWarn
+	}
+
+	return slog.LevelError
+}
+
+// levelHandler is a [slog.Handler] that filters the records by the (dynamic) level of the component.
+type levelHandler struct {
+	next   slog.Handler
+	levels *ComponentLevels
+	name   string // the component name (empty for the root logger)
+}
+
+var _ slog.Handler = (*levelHandler)(nil) // verify interface implementation
+
+func (h *levelHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return l >= h.levels.Get(h.name).toSlog() && h.next.Enabled(ctx, l)
+}
+
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error { return h.next.Handle(ctx, r) }
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{next: h.next.WithAttrs(attrs), levels: h.levels, name: h.name}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{next: h.next.WithGroup(name), levels: h.levels, name: h.name}
+}
@@ -0,0 +1,91 @@
+package logger_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gh.tarampamp.am/error-pages/internal/logger"
+)
+
+func TestParseLevelSpec(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		giveText   string
+		wantLevels map[string]logger.Level
+		wantErrMsg string
+	}{
+		"empty":         {giveText: "", wantLevels: map[string]logger.Level{}},
+		"default only":  {giveText: "warn", wantLevels: map[string]logger.Level{"": logger.WarnLevel}},
+		"components":    {giveText: "http=warn, render=DEBUG", wantLevels: map[string]logger.Level{"http": logger.WarnLevel, "render": logger.DebugLevel}}, //nolint:lll
+		"mixed":         {giveText: "error,http = info,", wantLevels: map[string]logger.Level{"": logger.ErrorLevel, "http": logger.InfoLevel}},            //nolint:lll
+		"wrong level":   {giveText: "http=foo", wantErrMsg: `unrecognized logging level: "foo"`},
+		"missing name":  {giveText: "=debug", wantErrMsg: `missing component name in the logging level: "=debug"`},
+		"wrong default": {giveText: "bar,http=info", wantErrMsg: `unrecognized logging level: "bar"`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var levels, err = logger.ParseLevelSpec(tt.giveText)
+
+			if tt.wantErrMsg != "" {
+				assert.EqualError(t, err, tt.wantErrMsg)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLevels, levels)
+		})
+	}
+}
+
+func TestLogger_ComponentLevels(t *testing.T) {
+	t.Parallel()
+
+	var (
+		buf    bytes.Buffer
+		log, _ = logger.New(logger.InfoLevel, logger.JSONFormat, &buf)
+		http   = log.Named("http")
+		render = log.Named("render")
+		levels = log.ComponentLevels()
+	)
+
+	levels.Apply(map[string]logger.Level{"http": logger.WarnLevel, "render": logger.DebugLevel})
+
+	assert.Equal(t, "info,http=warn,render=debug", levels.String())
+	assert.Equal(t, logger.InfoLevel, log.Level())
+	assert.Equal(t, logger.WarnLevel, http.Level())
+	assert.Equal(t, logger.DebugLevel, render.Level())
+	assert.Equal(t, logger.InfoLevel, log.Named("cache").Level()) // the default one
+
+	log.Debug("root debug")
+	log.Info("root info")
+	http.Info("http info")
+	http.Warn("http warn")
+	render.Debug("render debug")
+
+	var output = buf.String()
+
+	assert.NotContains(t, output, "root debug")
+	assert.Contains(t, output, "root info")
+	assert.NotContains(t, output, "http info")
+	assert.Contains(t, output, "http warn")
+	assert.Contains(t, output, "render debug")
+
+	// change the levels at runtime
+	buf.Reset()
+	levels.Apply(map[string]logger.Level{"": logger.ErrorLevel, "http": logger.DebugLevel})
+
+	assert.Equal(t, "error,http=debug,render=debug", levels.String())
+
+	log.Warn("root warn")
+	http.Debug("http debug")
+
+	output = buf.String()
+
+	assert.NotContains(t, output, "root warn")
+	assert.Contains(t, output, "http debug")
+	assert.Contains(t, output, `"logger":"http"`)
+}
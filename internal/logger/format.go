@@ -11,6 +11,8 @@ type Format uint8
 const (
 	ConsoleFormat Format = iota // useful for console output (for humans)
 	JSONFormat                  // useful for logging aggregation systems (for robots)
+	LogfmtFormat                // key=value pairs, useful for Loki and similar systems
+	ECSFormat                   // JSON with the Elastic Common Schema field names, useful for Elasticsearch
 )
 
 // String returns a lower-case ASCII representation of the log format.
@@ -20,6 +22,10 @@ func (f Format) String() string {
 		return "console"
 	case JSONFormat:
 		return "json"
+	case LogfmtFormat:
+		return "logfmt"
+	case ECSFormat:
+		return "ecs"
 	}
 
 	return fmt.Sprintf("format(%d)", f)
@@ -27,7 +33,7 @@ func (f Format) String() string {
 
 // Formats returns a slice of all logging formats.
 func Formats() []Format {
-	return []Format{ConsoleFormat, JSONFormat}
+	return []Format{ConsoleFormat, JSONFormat, LogfmtFormat, ECSFormat}
 }
 
 // FormatStrings returns a slice of all logging formats as strings.
@@ -62,6 +68,10 @@ func ParseFormat[T string | []byte](text T) (Format, error) {
 		return ConsoleFormat, nil
 	case "json":
 		return JSONFormat, nil
+	case "logfmt":
+		return LogfmtFormat, nil
+	case "ecs":
+		return ECSFormat, nil
 	}
 
 	return Format(0), fmt.Errorf("unrecognized logging format: %q", text)
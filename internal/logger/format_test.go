@@ -16,6 +16,8 @@ func TestFormat_String(t *testing.T) {
 	}{
 		"json":      {giveFormat: logger.JSONFormat, wantString: "json"},
 		"console":   {giveFormat: logger.ConsoleFormat, wantString: "console"},
+		"logfmt":    {giveFormat: logger.LogfmtFormat, wantString: "logfmt"},
+		"ecs":       {giveFormat: logger.ECSFormat, wantString: "ecs"},
 		"<unknown>": {giveFormat: logger.Format(255), wantString: "format(255)"},
 	} {
 		t.Run(name, func(t *testing.T) {
@@ -37,6 +39,10 @@ func TestParseFormat(t *testing.T) {
 		"console (string)":       {giveString: "console", wantFormat: logger.ConsoleFormat},
 		"json":                   {giveBytes: []byte("json"), wantFormat: logger.JSONFormat},
 		"json (string)":          {giveString: "json", wantFormat: logger.JSONFormat},
+		"logfmt":                 {giveBytes: []byte("logfmt"), wantFormat: logger.LogfmtFormat},
+		"logfmt (string)":        {giveString: "LOGFMT", wantFormat: logger.LogfmtFormat},
+		"ecs":                    {giveBytes: []byte("ecs"), wantFormat: logger.ECSFormat},
+		"ecs (string)":           {giveString: "Ecs", wantFormat: logger.ECSFormat},
 		"foobar":                 {giveBytes: []byte("foobar"), wantError: errors.New("unrecognized logging format: \"foobar\"")}, //nolint:lll
 	} {
 		t.Run(name, func(t *testing.T) {
@@ -62,9 +68,12 @@ func TestParseFormat(t *testing.T) {
 }
 
 func TestFormats(t *testing.T) {
-	require.Equal(t, []logger.Format{logger.ConsoleFormat, logger.JSONFormat}, logger.Formats())
+	require.Equal(t,
+		[]logger.Format{logger.ConsoleFormat, logger.JSONFormat, logger.LogfmtFormat, logger.ECSFormat},
+		logger.Formats(),
+	)
 }
 
 func TestFormatStrings(t *testing.T) {
-	require.Equal(t, []string{"console", "json"}, logger.FormatStrings())
+	require.Equal(t, []string{"console", "json", "logfmt", "ecs"}, logger.FormatStrings())
 }
@@ -6,6 +6,7 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"slices"
 	"strings"
 	"time"
 )
@@ -47,33 +48,95 @@ var (
 
 		return a
 	}
+
+	// logfmtFormatAttrReplacer is a replacer for logfmt format. It makes the keys logfmt-compatible (no spaces) and
+	// uses the RFC 3339 timestamps.
+	logfmtFormatAttrReplacer = func(_ []string, a slog.Attr) slog.Attr { //nolint:gochecknoglobals
+		switch a.Key {
+		case internalAttrKeyLoggerName:
+			return slog.String("logger", a.Value.String())
+		case "level":
+			return slog.String(a.Key, strings.ToLower(a.Value.String()))
+		default:
+			if ts, ok := a.Value.Any().(time.Time); ok && a.Key == "time" {
+				return slog.String(a.Key, ts.Format(time.RFC3339Nano))
+			}
+		}
+
+		if strings.ContainsAny(a.Key, " =\"") {
+			a.Key = strings.NewReplacer(" ", "_", "=", "_", `"`, "").Replace(a.Key)
+		}
+
+		return a
+	}
+
+	// ecsFormatAttrReplacer is a replacer for ECS format. It renames the attributes to the Elastic Common Schema
+	// field names (https://www.elastic.co/guide/en/ecs/current/ecs-field-reference.html).
+	ecsFormatAttrReplacer = func(groups []string, a slog.Attr) slog.Attr { //nolint:gochecknoglobals
+		if len(groups) > 0 {
+			return a // nested attributes are not renamed
+		}
+
+		switch a.Key {
+		case "level":
+			return slog.String("log.level", strings.ToLower(a.Value.String()))
+		case "time":
+			if ts, ok := a.Value.Any().(time.Time); ok {
+				return slog.String("@timestamp", ts.UTC().Format("2006-01-02T15:04:05.000Z"))
+			}
+		}
+
+		if name, ok := ecsFieldNames[a.Key]; ok {
+			a.Key = name
+		}
+
+		return a
+	}
+
+	// ecsFieldNames maps the attribute keys used across the application (including the HTTP access log ones) to
+	// the ECS field names.
+	ecsFieldNames = map[string]string{ //nolint:gochecknoglobals
+		"msg":                     "message",
+		"error":                   "error.message",
+		internalAttrKeyLoggerName: "log.logger",
+		"status code":             "http.response.status_code",
+		"method":                  "http.request.method",
+		"url":                     "url.original",
+		"referer":                 "http.request.referrer",
+		"useragent":               "user_agent.original",
+		"content type":            "http.response.mime_type",
+		"remote addr":             "client.address",
+		"duration":                "event.duration", // nanoseconds, as ECS requires
+		"request id":              "http.request.id",
+		"request headers":         "http.request.headers",
+		"response headers":        "http.response.headers",
+	}
 )
 
+// ecsVersion is the version of the Elastic Common Schema the ECS format follows.
+const ecsVersion = "8.11.0"
+
 // Logger is a simple logger that wraps [slog.Logger]. It provides a more convenient API for logging and
 // formatting messages.
 type Logger struct {
-	ctx  context.Context
-	slog *slog.Logger
-	lvl  Level
+	ctx     context.Context
+	slog    *slog.Logger
+	handler slog.Handler     // the handler without the level filtering (used to create named loggers)
+	levels  *ComponentLevels // shared between the logger and all its named loggers
+	name    string           // the component name (empty for the root logger)
+	writer  io.Writer        // the writer provided to the constructor (nil means the default one)
 }
 
-// New creates a new logger with the given level and format. Optionally, you can specify the writer to write logs to.
+// New creates a new logger with the given (default) level and format. Optionally, you can specify the writer to
+// write logs to. The levels of the named loggers can be changed using the [Logger.ComponentLevels].
 func New(l Level, f Format, writer ...io.Writer) (*Logger, error) {
-	var options slog.HandlerOptions
-
-	switch l {
-	case DebugLevel:
-		options.Level = slog.LevelDebug
-	case InfoLevel:
-		options.Level = slog.LevelInfo
-	case WarnLevel:
-		options.Level = slog.LevelWarn
-	case ErrorLevel:
-		options.Level = slog.LevelError
-	default:
+	if !slices.Contains(Levels(), l) {
 		return nil, errors.New("unsupported logging level")
 	}
 
+	// the level filtering is done by the levelHandler, so the underlying handler accepts everything
+	var options = slog.HandlerOptions{Level: slog.LevelDebug}
+
 	var (
 		handler slog.Handler
 		target  io.Writer
@@ -94,25 +157,58 @@ func New(l Level, f Format, writer ...io.Writer) (*Logger, error) {
 		options.ReplaceAttr = jsonFormatAttrReplacer
 
 		handler = slog.NewJSONHandler(target, &options)
+	case LogfmtFormat:
+		options.ReplaceAttr = logfmtFormatAttrReplacer
+
+		handler = slog.NewTextHandler(target, &options)
+	case ECSFormat:
+		options.ReplaceAttr = ecsFormatAttrReplacer
+
+		handler = slog.NewJSONHandler(target, &options).WithAttrs([]slog.Attr{slog.String("ecs.version", ecsVersion)})
 	default:
 		return nil, errors.New("unsupported logging format")
 	}
 
-	return &Logger{ctx: context.Background(), slog: slog.New(handler), lvl: l}, nil
-}
+	var log = newLogger(handler, newComponentLevels(l), "")
 
-// Level returns the logger level.
-func (l *Logger) Level() Level { return l.lvl }
+	if len(writer) > 0 {
+		log.writer = writer[0]
+	}
 
-// Named creates a new logger with the same properties as the original logger and the given name.
-func (l *Logger) Named(name string) *Logger {
+	return log, nil
+}
+
+// newLogger creates a new logger that filters the records by the level of the named component.
+func newLogger(handler slog.Handler, levels *ComponentLevels, name string) *Logger {
 	return &Logger{
-		ctx:  l.ctx,
-		slog: l.slog.With(slog.String(internalAttrKeyLoggerName, name)),
-		lvl:  l.lvl,
+		ctx:     context.Background(),
+		slog:    slog.New(&levelHandler{next: handler, levels: levels, name: name}),
+		handler: handler,
+		levels:  levels,
+		name:    name,
 	}
 }
 
+// Writer returns the writer provided to the [New] function, or nil if the logs are written to the default one
+// (stderr). It can be used to write other logs (e.g., the HTTP access log lines) to the same destination.
+func (l *Logger) Writer() io.Writer { return l.writer }
+
+// Level returns the current logger level (the level of the named component, or the default one).
+func (l *Logger) Level() Level { return l.levels.Get(l.name) }
+
+// ComponentLevels returns the levels registry, shared between the logger and all its named loggers.
+func (l *Logger) ComponentLevels() *ComponentLevels { return l.levels }
+
+// Named creates a new logger with the same properties as the original logger and the given name. The name is
+// also the component name for the per-component logging levels.
+func (l *Logger) Named(name string) *Logger {
+	var named = newLogger(l.handler.WithAttrs([]slog.Attr{slog.String(internalAttrKeyLoggerName, name)}), l.levels, name)
+
+	named.writer = l.writer
+
+	return named
+}
+
 // Debug logs a message at DebugLevel.
 func (l *Logger) Debug(msg string, f ...Attr) { l.slog.LogAttrs(l.ctx, slog.LevelDebug, msg, f...) }
 
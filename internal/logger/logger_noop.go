@@ -8,7 +8,7 @@ import (
 // NewNop returns a no-op Logger. It never writes out logs or internal errors. The common use case is to use it
 // in tests.
 func NewNop() *Logger {
-	return &Logger{ctx: context.Background(), slog: slog.New(&noopHandler{}), lvl: DebugLevel}
+	return newLogger(noopHandler{}, newComponentLevels(DebugLevel), "")
 }
 
 type noopHandler struct{}
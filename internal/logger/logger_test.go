@@ -2,6 +2,8 @@ package logger_test
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"strconv"
 	"testing"
 	"time"
@@ -100,6 +102,73 @@ func TestLogger_JSONFormat(t *testing.T) {
 	assert.Contains(t, output, `"Duration":1000000`)
 }
 
+func TestLogger_LogfmtFormat(t *testing.T) {
+	var (
+		buf         bytes.Buffer
+		log, logErr = logger.New(logger.DebugLevel, logger.LogfmtFormat, &buf)
+
+		now = time.Now()
+	)
+
+	require.NoError(t, logErr)
+
+	log.Named("test_name").Info("info message",
+		logger.String("String", "value with spaces"),
+		logger.Int("status code", 404),
+		logger.Duration("Duration", time.Millisecond),
+	)
+
+	var output = buf.String()
+
+	assert.Contains(t, output, `time=`+now.Format("2006-01-02T15:04:")) // match without seconds
+	assert.Contains(t, output, `level=info`)
+	assert.Contains(t, output, `msg="info message"`)
+	assert.Contains(t, output, `logger=test_name`)
+	assert.Contains(t, output, `String="value with spaces"`)
+	assert.Contains(t, output, `status_code=404`)
+	assert.Contains(t, output, `Duration=1ms`)
+}
+
+func TestLogger_ECSFormat(t *testing.T) {
+	var (
+		buf         bytes.Buffer
+		log, logErr = logger.New(logger.DebugLevel, logger.ECSFormat, &buf)
+	)
+
+	require.NoError(t, logErr)
+
+	log.Named("test_name").Warn("HTTP request processed",
+		logger.Int("status code", 404),
+		logger.String("method", "GET"),
+		logger.String("url", "/foo"),
+		logger.String("useragent", "curl"),
+		logger.Duration("duration", time.Millisecond),
+		logger.Error(errors.New("some error")),
+		logger.String("custom", "value"),
+	)
+
+	var record map[string]any
+
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+	assert.Regexp(t, `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{3}Z$`, record["@timestamp"])
+	assert.Equal(t, "warn", record["log.level"])
+	assert.Equal(t, "test_name", record["log.logger"])
+	assert.Equal(t, "HTTP request processed", record["message"])
+	assert.Equal(t, "8.11.0", record["ecs.version"])
+	assert.EqualValues(t, 404, record["http.response.status_code"])
+	assert.Equal(t, "GET", record["http.request.method"])
+	assert.Equal(t, "/foo", record["url.original"])
+	assert.Equal(t, "curl", record["user_agent.original"])
+	assert.EqualValues(t, 1000000, record["event.duration"])
+	assert.Equal(t, "some error", record["error.message"])
+	assert.Equal(t, "value", record["custom"])
+
+	for _, key := range []string{"time", "level", "msg", "status code", "logger", "named_logger"} {
+		assert.NotContains(t, record, key)
+	}
+}
+
 func TestLogger_Debug(t *testing.T) {
 	var (
 		buf         bytes.Buffer
@@ -211,6 +280,20 @@ func TestLogger_Named_JSONFormat(t *testing.T) {
 	assert.Contains(t, output, `"logger":"test_name"`)
 }
 
+func TestLogger_Writer(t *testing.T) {
+	var buf bytes.Buffer
+
+	log, _ := logger.New(logger.InfoLevel, logger.JSONFormat, &buf)
+
+	assert.Same(t, &buf, log.Writer())
+	assert.Same(t, &buf, log.Named("test_name").Writer())
+
+	log, _ = logger.New(logger.InfoLevel, logger.JSONFormat)
+
+	assert.Nil(t, log.Writer()) // the default one (stderr)
+	assert.Nil(t, logger.NewNop().Writer())
+}
+
 func TestLogger_Named_ConsoleFormat(t *testing.T) {
 	var (
 		buf    bytes.Buffer
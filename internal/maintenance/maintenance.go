@@ -0,0 +1,115 @@
+// Package maintenance tracks the maintenance mode, which is active during the scheduled window, when the flag file
+// exists, or when enabled manually (e.g., using the admin HTTP endpoint).
+package maintenance
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Mode is the maintenance mode state. It's safe for concurrent use.
+type Mode struct {
+	start, end time.Time // the scheduled window (zero values mean unbounded)
+	scheduled  bool      // is the window configured?
+	flagFile   string    // the maintenance is active while this file exists (empty means not used)
+
+	manual atomic.Pointer[time.Time] // the end of the manually enabled maintenance (nil if disabled, zero if unknown)
+	flag   atomic.Pointer[time.Time] // the end read from the flag file (nil if the file is missing, zero if unknown)
+	now    func() time.Time
+}
+
+// Option allows to customize the maintenance mode.
+type Option func(*Mode)
+
+// WithWindow schedules the maintenance window. One of the bounds may be zero (e.g., the maintenance is active from
+// the start time until it's disabled).
+func WithWindow(start, end time.Time) Option {
+	return func(m *Mode) { m.start, m.end, m.scheduled = start, end, !start.IsZero() || !end.IsZero() }
+}
+
+// WithFlagFile makes the maintenance active while the file exists. The file may contain the maintenance end time
+// in RFC 3339 format (e.g., "2024-01-01T10:00:00Z"). The file is read on creation, and then by the [Mode.Refresh]
+// (see [Mode.Watch]).
+func WithFlagFile(path string) Option { return func(m *Mode) { m.flagFile = path } }
+
+// WithClock sets the current time source (useful for testing).
+func WithClock(now func() time.Time) Option { return func(m *Mode) { m.now = now } }
+
+// New creates a new maintenance mode state.
+func New(opts ...Option) *Mode {
+	var m = Mode{now: time.Now}
+
+	for _, opt := range opts {
+		opt(&m)
+	}
+
+	m.Refresh()
+
+	return &m
+}
+
+// Enable enables the maintenance manually until the given time (zero means until it's disabled).
+func (m *Mode) Enable(until time.Time) { m.manual.Store(&until) }
+
+// Disable disables the manually enabled maintenance (the scheduled window and flag file are not affected).
+func (m *Mode) Disable() { m.manual.Store(nil) }
+
+// Refresh reads the flag file (if configured) and updates the maintenance state.
+func (m *Mode) Refresh() {
+	if m.flagFile == "" {
+		return
+	}
+
+	content, err := os.ReadFile(m.flagFile)
+	if err != nil {
+		m.flag.Store(nil) // the file is missing (or not readable)
+
+		return
+	}
+
+	var until, parseErr = time.Parse(time.RFC3339, strings.TrimSpace(string(content)))
+	if parseErr != nil {
+		until = time.Time{} // the end is unknown
+	}
+
+	m.flag.Store(&until)
+}
+
+// Watch refreshes the flag file state with the given interval until the context is canceled, so the file is not
+// read on every request.
+func (m *Mode) Watch(ctx context.Context, interval time.Duration) {
+	var ticker = time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Refresh()
+		}
+	}
+}
+
+// Active reports whether the maintenance is active now, and its expected end (zero if unknown). The manually
+// enabled maintenance takes precedence over the flag file, and the flag file over the scheduled window.
+func (m *Mode) Active() (end time.Time, active bool) {
+	var now = m.now()
+
+	if until := m.manual.Load(); until != nil && (until.IsZero() || now.Before(*until)) {
+		return *until, true
+	}
+
+	if until := m.flag.Load(); until != nil && (until.IsZero() || now.Before(*until)) {
+		return *until, true
+	}
+
+	if m.scheduled && (m.start.IsZero() || !now.Before(m.start)) && (m.end.IsZero() || now.Before(m.end)) {
+		return m.end, true
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,171 @@
+package maintenance_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gh.tarampamp.am/error-pages/internal/maintenance"
+)
+
+func TestMode_Window(t *testing.T) {
+	t.Parallel()
+
+	var (
+		start = time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		end   = start.Add(2 * time.Hour)
+	)
+
+	for name, tt := range map[string]struct {
+		giveStart, giveEnd, giveNow time.Time
+		wantActive                  bool
+		wantEnd                     time.Time
+	}{
+		"before":           {giveStart: start, giveEnd: end, giveNow: start.Add(-time.Second)},
+		"at the start":     {giveStart: start, giveEnd: end, giveNow: start, wantActive: true, wantEnd: end},
+		"inside":           {giveStart: start, giveEnd: end, giveNow: start.Add(time.Hour), wantActive: true, wantEnd: end},
+		"at the end":       {giveStart: start, giveEnd: end, giveNow: end},
+		"start only":       {giveStart: start, giveNow: start.Add(24 * time.Hour), wantActive: true},
+		"end only":         {giveEnd: end, giveNow: start, wantActive: true, wantEnd: end},
+		"end only, passed": {giveEnd: end, giveNow: end.Add(time.Second)},
+		"not scheduled":    {giveNow: start},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var m = maintenance.New(
+				maintenance.WithWindow(tt.giveStart, tt.giveEnd),
+				maintenance.WithClock(func() time.Time { return tt.giveNow }),
+			)
+
+			gotEnd, active := m.Active()
+
+			assert.Equal(t, tt.wantActive, active)
+			assert.Equal(t, tt.wantEnd, gotEnd)
+		})
+	}
+}
+
+func TestMode_FlagFile(t *testing.T) {
+	t.Parallel()
+
+	var (
+		file = filepath.Join(t.TempDir(), "maintenance")
+		now  = time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		m    = maintenance.New(
+			maintenance.WithFlagFile(file),
+			maintenance.WithClock(func() time.Time { return now }),
+		)
+	)
+
+	_, active := m.Active()
+	assert.False(t, active) // the file does not exist
+
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+	_, active = m.Active()
+	assert.False(t, active) // not refreshed yet
+
+	m.Refresh()
+
+	end, active := m.Active()
+	assert.True(t, active)
+	assert.True(t, end.IsZero())
+
+	require.NoError(t, os.WriteFile(file, []byte("2024-01-01T12:00:00Z\n"), 0o600))
+	m.Refresh()
+
+	end, active = m.Active()
+	assert.True(t, active)
+	assert.Equal(t, now.Add(2*time.Hour), end.UTC())
+
+	require.NoError(t, os.WriteFile(file, []byte("2024-01-01T09:00:00Z"), 0o600))
+	m.Refresh()
+
+	_, active = m.Active()
+	assert.False(t, active) // already ended
+
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	m.Refresh()
+	require.NoError(t, os.Remove(file))
+	m.Refresh()
+
+	_, active = m.Active()
+	assert.False(t, active) // the file is removed
+}
+
+func TestMode_FlagFile_ReadOnCreation(t *testing.T) {
+	t.Parallel()
+
+	var file = filepath.Join(t.TempDir(), "maintenance")
+
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+	_, active := maintenance.New(maintenance.WithFlagFile(file)).Active()
+	assert.True(t, active)
+}
+
+func TestMode_Watch(t *testing.T) {
+	t.Parallel()
+
+	var (
+		file        = filepath.Join(t.TempDir(), "maintenance")
+		m           = maintenance.New(maintenance.WithFlagFile(file))
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	defer cancel()
+
+	go m.Watch(ctx, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+	assert.Eventually(t, func() bool { _, active := m.Active(); return active }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.Remove(file))
+
+	assert.Eventually(t, func() bool { _, active := m.Active(); return !active }, time.Second, 10*time.Millisecond)
+}
+
+func TestMode_Manual(t *testing.T) {
+	t.Parallel()
+
+	var (
+		now = time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		m   = maintenance.New(
+			maintenance.WithWindow(now.Add(time.Hour), now.Add(2*time.Hour)),
+			maintenance.WithClock(func() time.Time { return now }),
+		)
+	)
+
+	_, active := m.Active()
+	assert.False(t, active)
+
+	m.Enable(time.Time{})
+
+	end, active := m.Active()
+	assert.True(t, active)
+	assert.True(t, end.IsZero())
+
+	m.Enable(now.Add(30 * time.Minute))
+
+	end, active = m.Active()
+	assert.True(t, active)
+	assert.Equal(t, now.Add(30*time.Minute), end)
+
+	m.Enable(now.Add(-time.Minute)) // expired
+
+	_, active = m.Active()
+	assert.False(t, active)
+
+	m.Enable(time.Time{})
+	m.Disable()
+
+	_, active = m.Active()
+	assert.False(t, active)
+}
@@ -0,0 +1,231 @@
+// Package overrides provides per-namespace error page customizations, loaded from a directory (e.g., the mounted
+// Kubernetes ConfigMap) and reloaded when its content changes.
+package overrides
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gh.tarampamp.am/error-pages/internal/config"
+	"gh.tarampamp.am/error-pages/internal/logger"
+)
+
+// Override contains the error page customizations for a single namespace.
+type Override struct {
+	// Template is the template content to use instead of the default one (empty means not overridden).
+	Template string
+
+	// Codes hold the HTTP codes descriptions, which take precedence over the global ones.
+	Codes config.Codes
+
+	// SupportURL is the link to the team support (e.g., a chat channel or an issue tracker).
+	SupportURL string
+}
+
+// settingsFile is the per-namespace JSON settings file content.
+type settingsFile struct {
+	Template   string       `json:"template"` // the name of the configured template
+	Codes      config.Codes `json:"codes"`
+	SupportURL string       `json:"support_url"`
+}
+
+// dataLink is the symlink that Kubernetes atomically swaps when the projected volume (ConfigMap) content is updated.
+const dataLink = "..data"
+
+// Dir holds the overrides loaded from a directory. The directory contains the files named after the namespaces:
+//
+//   - `<namespace>.html` - the template content
+//   - `<namespace>.json` - the settings: `{"template": "<name>", "codes": {...}, "support_url": "..."}`
+//
+// The `.html` file takes precedence over the template name in the settings. It's safe for concurrent use.
+type Dir struct {
+	path      string
+	templates map[string]string // the configured templates (for the names lookup)
+	log       *logger.Logger
+
+	version   string                              // the last loaded content version (see [Dir.snapshot])
+	overrides atomic.Pointer[map[string]Override] // map[namespace]override
+}
+
+// Load reads the overrides from the directory. The templates are used to resolve the template names in the
+// settings files.
+func Load(path string, templates map[string]string, log *logger.Logger) (*Dir, error) {
+	var d = Dir{path: path, templates: templates, log: log}
+
+	if _, err := d.Reload(); err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// Get returns the override for the given namespace.
+func (d *Dir) Get(namespace string) (Override, bool) {
+	if namespace == "" {
+		return Override{}, false
+	}
+
+	o, ok := (*d.overrides.Load())[namespace]
+
+	return o, ok
+}
+
+// Reload reads the overrides again if the directory content has been changed. It returns true if the overrides
+// were reloaded. In case of an error, the previously loaded overrides are kept.
+func (d *Dir) Reload() (bool, error) {
+	var version, root, err = d.snapshot()
+	if err != nil {
+		return false, err
+	}
+
+	if version == d.version && d.overrides.Load() != nil {
+		return false, nil // not changed
+	}
+
+	loaded, err := d.read(root)
+	if err != nil {
+		return false, err
+	}
+
+	d.overrides.Store(&loaded)
+	d.version = version
+
+	return true, nil
+}
+
+// Watch checks the directory for changes with the given interval until the context is canceled. Must not be
+// called concurrently with [Dir.Reload].
+func (d *Dir) Watch(ctx context.Context, interval time.Duration) {
+	var ticker = time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if reloaded, err := d.Reload(); err != nil {
+				d.log.Warn("Failed to reload the namespace overrides", logger.Error(err))
+			} else if reloaded {
+				d.log.Info("Namespace overrides reloaded", logger.Strings("namespaces", d.Namespaces()...))
+			}
+		}
+	}
+}
+
+// Namespaces returns the sorted list of namespaces with overrides.
+func (d *Dir) Namespaces() []string {
+	var (
+		overrides = *d.overrides.Load()
+		list      = make([]string, 0, len(overrides))
+	)
+
+	for ns := range overrides {
+		list = append(list, ns)
+	}
+
+	sort.Strings(list)
+
+	return list
+}
+
+// snapshot returns the directory content version and the path to read the files from. For the Kubernetes
+// projected volumes, the version is the `..data` symlink target, and the files are read from the target directory
+// directly (so the content is consistent even if the symlink is swapped while reading). Otherwise, the version is
+// based on the files names, sizes and modification times.
+func (d *Dir) snapshot() (version, root string, _ error) {
+	if target, err := os.Readlink(filepath.Join(d.path, dataLink)); err == nil {
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(d.path, target)
+		}
+
+		return target, target, nil
+	}
+
+	entries, err := os.ReadDir(d.path)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot read the overrides directory: %w", err)
+	}
+
+	var b strings.Builder
+
+	for _, entry := range entries {
+		if info, infoErr := entry.Info(); infoErr == nil {
+			_, _ = fmt.Fprintf(&b, "%s:%d:%d;", entry.Name(), info.Size(), info.ModTime().UnixNano())
+		}
+	}
+
+	return b.String(), d.path, nil
+}
+
+// read reads the overrides from the given directory.
+func (d *Dir) read(root string) (map[string]Override, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the overrides directory: %w", err)
+	}
+
+	var result = make(map[string]Override)
+
+	for _, entry := range entries {
+		var name = entry.Name()
+
+		if strings.HasPrefix(name, ".") || entry.IsDir() {
+			continue // skip the hidden files (including the Kubernetes `..data` and timestamped directories)
+		}
+
+		var ext = filepath.Ext(name)
+
+		if ext != ".html" && ext != ".json" {
+			continue
+		}
+
+		var ns = strings.TrimSuffix(name, ext)
+
+		content, readErr := os.ReadFile(filepath.Join(root, name))
+		if readErr != nil {
+			if errors.Is(readErr, fs.ErrNotExist) {
+				continue // a dangling symlink
+			}
+
+			return nil, fmt.Errorf("cannot read the %s file: %w", name, readErr)
+		}
+
+		var o = result[ns]
+
+		switch ext {
+		case ".html":
+			o.Template = string(content)
+		case ".json":
+			var settings settingsFile
+
+			if err = json.Unmarshal(content, &settings); err != nil {
+				return nil, fmt.Errorf("cannot parse the %s file: %w", name, err)
+			}
+
+			if settings.Template != "" && o.Template == "" {
+				tpl, found := d.templates[settings.Template]
+				if !found {
+					return nil, fmt.Errorf("unknown template %q in the %s file", settings.Template, name)
+				}
+
+				o.Template = tpl
+			}
+
+			o.Codes, o.SupportURL = settings.Codes, settings.SupportURL
+		}
+
+		result[ns] = o
+	}
+
+	return result, nil
+}
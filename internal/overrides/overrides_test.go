@@ -0,0 +1,153 @@
+package overrides_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gh.tarampamp.am/error-pages/internal/config"
+	"gh.tarampamp.am/error-pages/internal/logger"
+	"gh.tarampamp.am/error-pages/internal/overrides"
+)
+
+// writeConfigMap writes the files the same way as Kubernetes does for the projected volumes: the files are placed
+// into a new timestamped directory, and the `..data` symlink is atomically swapped to point to it.
+func writeConfigMap(t *testing.T, dir, version string, files map[string]string) {
+	t.Helper()
+
+	var dataDir = filepath.Join(dir, "..20240101_"+version)
+
+	require.NoError(t, os.Mkdir(dataDir, 0o755))
+
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dataDir, name), []byte(content), 0o600))
+
+		// the visible files are the symlinks to the `..data` directory
+		_ = os.Symlink(filepath.Join("..data", name), filepath.Join(dir, name))
+	}
+
+	var tmpLink = filepath.Join(dir, "..data_tmp")
+
+	require.NoError(t, os.Symlink(filepath.Base(dataDir), tmpLink))
+	require.NoError(t, os.Rename(tmpLink, filepath.Join(dir, "..data")))
+}
+
+func TestDir_ConfigMap(t *testing.T) {
+	t.Parallel()
+
+	var dir = t.TempDir()
+
+	writeConfigMap(t, dir, "1", map[string]string{
+		"team-a.json": `{"template": "foo", "codes": {"404": {"message": "Nope"}}, "support_url": "https://a/support"}`,
+		"team-b.html": "<b>{{ code }}</b>",
+		"team-b.json": `{"template": "foo", "support_url": "https://b/support"}`,
+		"readme.md":   "ignored",
+	})
+
+	d, err := overrides.Load(dir, map[string]string{"foo": "FOO"}, logger.NewNop())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"team-a", "team-b"}, d.Namespaces())
+
+	a, found := d.Get("team-a")
+	require.True(t, found)
+	assert.Equal(t, "FOO", a.Template)
+	assert.Equal(t, config.Codes{"404": {Message: "Nope"}}, a.Codes)
+	assert.Equal(t, "https://a/support", a.SupportURL)
+
+	b, found := d.Get("team-b")
+	require.True(t, found)
+	assert.Equal(t, "<b>{{ code }}</b>", b.Template) // the .html file takes precedence
+	assert.Equal(t, "https://b/support", b.SupportURL)
+
+	_, found = d.Get("team-c")
+	assert.False(t, found)
+
+	_, found = d.Get("")
+	assert.False(t, found)
+
+	reloaded, err := d.Reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded) // not changed
+
+	// the symlink swap
+	writeConfigMap(t, dir, "2", map[string]string{
+		"team-a.json": `{"support_url": "https://a/new-support"}`,
+	})
+
+	reloaded, err = d.Reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+
+	assert.Equal(t, []string{"team-a"}, d.Namespaces())
+
+	a, found = d.Get("team-a")
+	require.True(t, found)
+	assert.Empty(t, a.Template)
+	assert.Equal(t, "https://a/new-support", a.SupportURL)
+
+	// the broken content is not applied
+	writeConfigMap(t, dir, "3", map[string]string{"team-a.json": `{`})
+
+	_, err = d.Reload()
+	require.ErrorContains(t, err, "cannot parse the team-a.json file")
+
+	a, _ = d.Get("team-a")
+	assert.Equal(t, "https://a/new-support", a.SupportURL) // the previous content is kept
+}
+
+func TestDir_PlainDirectory(t *testing.T) {
+	t.Parallel()
+
+	var dir = t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team-a.html"), []byte("A"), 0o600))
+
+	d, err := overrides.Load(dir, nil, logger.NewNop())
+	require.NoError(t, err)
+
+	a, _ := d.Get("team-a")
+	assert.Equal(t, "A", a.Template)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team-b.html"), []byte("B"), 0o600))
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+
+	go d.Watch(ctx, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		b, found := d.Get("team-b")
+
+		return found && b.Template == "B"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing directory", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := overrides.Load(filepath.Join(t.TempDir(), "missing"), nil, logger.NewNop())
+
+		assert.ErrorContains(t, err, "cannot read the overrides directory")
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		t.Parallel()
+
+		var dir = t.TempDir()
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "team-a.json"), []byte(`{"template": "bar"}`), 0o600))
+
+		_, err := overrides.Load(dir, map[string]string{"foo": "FOO"}, logger.NewNop())
+
+		assert.ErrorContains(t, err, `unknown template "bar" in the team-a.json file`)
+	})
+}
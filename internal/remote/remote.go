@@ -0,0 +1,85 @@
+// Package remote loads the content of the periodically refreshed sources (the status page, the known site URLs
+// list) from a local file or an HTTP(S) URL.
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// IsURL reports whether the location is an HTTP(S) URL (otherwise, it's a local file path).
+func IsURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// Fetcher reads the content from the local file or HTTP(S) URL.
+type Fetcher struct {
+	Location string       // the local file path or HTTP(S) URL
+	Name     string       // the content name, used in the error messages (e.g., "status page")
+	Accept   string       // the `Accept` HTTP header value (optional)
+	MaxSize  int64        // the HTTP response content size limit (the rest is discarded)
+	Client   *http.Client // the HTTP client (with the timeout set)
+}
+
+// Fetch reads the content from the file or URL.
+func (f Fetcher) Fetch(ctx context.Context) ([]byte, error) {
+	if !IsURL(f.Location) {
+		content, err := os.ReadFile(f.Location)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the %s file: %w", f.Name, err)
+		}
+
+		return content, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.Location, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create the %s request: %w", f.Name, err)
+	}
+
+	if f.Accept != "" {
+		req.Header.Set("Accept", f.Accept)
+	}
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch the %s: %w", f.Name, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected %s response status code: %d", f.Name, resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, f.MaxSize))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the %s response: %w", f.Name, err)
+	}
+
+	return content, nil
+}
+
+// Watch calls the refresh function immediately and then with the given interval until the context is canceled.
+// The refresh errors (except the ones caused by the context cancellation) are passed to the onError function.
+func Watch(ctx context.Context, interval time.Duration, refresh func(context.Context) error, onError func(error)) {
+	var ticker = time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := refresh(ctx); err != nil && ctx.Err() == nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
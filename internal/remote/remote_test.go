@@ -0,0 +1,108 @@
+package remote_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gh.tarampamp.am/error-pages/internal/remote"
+)
+
+func TestIsURL(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, remote.IsURL("https://status.example.com/api/v2/summary.json"))
+	assert.True(t, remote.IsURL("http://127.0.0.1:8080/status"))
+	assert.False(t, remote.IsURL("/etc/status.json"))
+	assert.False(t, remote.IsURL("status.atom"))
+}
+
+func TestFetcher_Fetch_URL(t *testing.T) {
+	t.Parallel()
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(r.Header.Get("Accept") + "|0123456789"))
+	}))
+
+	t.Cleanup(srv.Close)
+
+	var f = remote.Fetcher{Location: srv.URL, Name: "test", Accept: "text/plain", MaxSize: 15, Client: srv.Client()}
+
+	content, err := f.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain|0123", string(content)) // the size is limited
+
+	f.Location = srv.URL + "/broken"
+
+	_, err = f.Fetch(context.Background())
+	require.EqualError(t, err, "unexpected test response status code: 502")
+
+	f.Location = "http://127.0.0.1:0"
+
+	_, err = f.Fetch(context.Background())
+	require.ErrorContains(t, err, "cannot fetch the test")
+}
+
+func TestFetcher_Fetch_File(t *testing.T) {
+	t.Parallel()
+
+	var file = filepath.Join(t.TempDir(), "urls.txt")
+
+	require.NoError(t, os.WriteFile(file, []byte("/foo\n/bar"), 0o600))
+
+	var f = remote.Fetcher{Location: file, Name: "test"}
+
+	content, err := f.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "/foo\n/bar", string(content)) // the size limit is for the HTTP responses only
+
+	f.Location = file + ".missing"
+
+	_, err = f.Fetch(context.Background())
+	require.ErrorContains(t, err, "cannot read the test file")
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls, errs atomic.Int32
+		ctx, cancel = context.WithCancel(context.Background())
+		done        = make(chan struct{})
+	)
+
+	go func() {
+		defer close(done)
+
+		remote.Watch(ctx, 10*time.Millisecond, func(context.Context) error {
+			if calls.Add(1)%2 == 0 {
+				return errors.New("failed")
+			}
+
+			return nil
+		}, func(error) { errs.Add(1) })
+	}()
+
+	assert.Eventually(t, func() bool { return calls.Load() >= 4 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+
+	assert.Positive(t, errs.Load())
+	assert.LessOrEqual(t, errs.Load(), calls.Load()/2) // every second call fails
+}
@@ -0,0 +1,228 @@
+// Package statuspage provides the current incident from a status page - a local file or an HTTP(S) URL, polled
+// periodically. The Statuspage and Cachet API JSON responses, Atom feeds, and a simple JSON object are supported.
+package statuspage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gh.tarampamp.am/error-pages/internal/logger"
+	"gh.tarampamp.am/error-pages/internal/remote"
+)
+
+// Incident is the current status page incident.
+type Incident struct {
+	Title string // the incident title (name)
+	Body  string // the latest incident update text
+	URL   string // the link to the incident page
+}
+
+// Source is the status page source. It's safe for concurrent use.
+type Source struct {
+	fetcher remote.Fetcher
+	log     *logger.Logger
+
+	incident atomic.Pointer[Incident] // nil if there is no active incident (or it's not fetched yet)
+}
+
+// maxSize limits the status page content size.
+const maxSize = 1 << 20 // 1 MiB
+
+// New creates a new status page source for the given location (a local file path or an HTTP(S) URL). Nothing is
+// fetched until [Source.Refresh] or [Source.Watch] is called.
+func New(location string, log *logger.Logger) *Source {
+	return &Source{
+		fetcher: remote.Fetcher{
+			Location: location,
+			Name:     "status page",
+			Accept:   "application/json, application/atom+xml;q=0.9",
+			MaxSize:  maxSize,
+			Client:   &http.Client{Timeout: 10 * time.Second}, //nolint:mnd
+		},
+		log: log,
+	}
+}
+
+// Current returns the current incident, if any.
+func (s *Source) Current() (Incident, bool) {
+	if i := s.incident.Load(); i != nil {
+		return *i, true
+	}
+
+	return Incident{}, false
+}
+
+// Refresh fetches the status page and updates the current incident. In case of an error, the previous incident
+// is kept.
+func (s *Source) Refresh(ctx context.Context) error {
+	content, err := s.fetcher.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	incident, err := Parse(content)
+	if err != nil {
+		return err
+	}
+
+	s.incident.Store(incident)
+
+	return nil
+}
+
+// Watch refreshes the status page immediately and then with the given interval until the context is canceled.
+// The errors are logged only, so the status page failures never break the error pages rendering.
+func (s *Source) Watch(ctx context.Context, interval time.Duration) {
+	remote.Watch(ctx, interval, s.Refresh, func(err error) {
+		s.log.Warn("Failed to refresh the status page", logger.String("source", s.fetcher.Location), logger.Error(err))
+	})
+}
+
+// Parse extracts the current incident from the status page content (nil means there is no active incident). The
+// content format is detected automatically:
+//
+//   - Atom feed - the latest entry is the current incident (so the feed should contain the active incidents only)
+//   - Statuspage API (`/api/v2/incidents/unresolved.json` or `/api/v2/summary.json`) - the first unresolved one
+//   - Cachet API (`/api/v1/incidents`) - the first not fixed (and not scheduled) one
+//   - simple JSON object: `{"title": "...", "body": "...", "url": "..."}` (an empty title means no incident)
+//
+// The incident link is kept only if it's an HTTP(S) URL, since it's rendered on the error pages.
+func Parse(content []byte) (*Incident, error) {
+	var (
+		incident *Incident
+		err      error
+	)
+
+	if content = bytes.TrimSpace(content); len(content) > 0 && content[0] == '<' {
+		incident, err = parseAtom(content)
+	} else {
+		incident, err = parseJSON(content)
+	}
+
+	if incident != nil && !isHTTPURL(incident.URL) {
+		incident.URL = "" // e.g., the "javascript:" links
+	}
+
+	return incident, err
+}
+
+// isHTTPURL checks if the string is an absolute HTTP(S) URL.
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+
+	return err == nil && (strings.EqualFold(u.Scheme, "http") || strings.EqualFold(u.Scheme, "https")) && u.Host != ""
+}
+
+// atomFeed is the Atom feed (https://datatracker.ietf.org/doc/html/rfc4287), the needed fields only.
+type atomFeed struct {
+	Entries []struct {
+		Title   string `xml:"title"`
+		Summary string `xml:"summary"`
+		Content string `xml:"content"`
+		Links   []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+	} `xml:"entry"`
+}
+
+func parseAtom(content []byte) (*Incident, error) {
+	var feed atomFeed
+
+	if err := xml.Unmarshal(content, &feed); err != nil {
+		return nil, fmt.Errorf("cannot parse the status page Atom feed: %w", err)
+	}
+
+	if len(feed.Entries) == 0 {
+		return nil, nil //nolint:nilnil // no incident
+	}
+
+	var (
+		entry    = feed.Entries[0]
+		incident = Incident{Title: strings.TrimSpace(entry.Title), Body: strings.TrimSpace(entry.Content)}
+	)
+
+	if incident.Body == "" {
+		incident.Body = strings.TrimSpace(entry.Summary)
+	}
+
+	for _, link := range entry.Links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			incident.URL = link.Href
+
+			break
+		}
+	}
+
+	return &incident, nil
+}
+
+// statusJSON combines the supported JSON formats, the needed fields only.
+type statusJSON struct {
+	// Statuspage (https://developer.statuspage.io/#tag/incidents)
+	Incidents []struct {
+		Name      string `json:"name"`
+		Status    string `json:"status"` // investigating, identified, monitoring, resolved, postmortem
+		Shortlink string `json:"shortlink"`
+		Updates   []struct {
+			Body string `json:"body"`
+		} `json:"incident_updates"` // the latest update is the first one
+	} `json:"incidents"`
+
+	// Cachet (https://docs.cachethq.io/reference/incidents)
+	Data []struct {
+		Name      string `json:"name"`
+		Message   string `json:"message"`
+		Status    int    `json:"status"` // 0 - scheduled, 1 - investigating, 2 - identified, 3 - watching, 4 - fixed
+		Permalink string `json:"permalink"`
+	} `json:"data"`
+
+	// the simple format
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url"`
+}
+
+func parseJSON(content []byte) (*Incident, error) {
+	var status statusJSON
+
+	if err := json.Unmarshal(content, &status); err != nil {
+		return nil, fmt.Errorf("cannot parse the status page JSON: %w", err)
+	}
+
+	for _, i := range status.Incidents {
+		if i.Status == "resolved" || i.Status == "postmortem" {
+			continue
+		}
+
+		var incident = Incident{Title: i.Name, URL: i.Shortlink}
+
+		if len(i.Updates) > 0 {
+			incident.Body = i.Updates[0].Body
+		}
+
+		return &incident, nil
+	}
+
+	const cachetScheduled, cachetFixed = 0, 4
+
+	for _, i := range status.Data {
+		if i.Status != cachetScheduled && i.Status != cachetFixed { // the scheduled maintenance is not started yet
+			return &Incident{Title: i.Name, Body: i.Message, URL: i.Permalink}, nil
+		}
+	}
+
+	if status.Title != "" {
+		return &Incident{Title: status.Title, Body: status.Body, URL: status.URL}, nil
+	}
+
+	return nil, nil //nolint:nilnil // no incident
+}
@@ -0,0 +1,170 @@
+package statuspage_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gh.tarampamp.am/error-pages/internal/logger"
+	"gh.tarampamp.am/error-pages/internal/statuspage"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		giveContent  string
+		wantIncident *statuspage.Incident
+		wantErr      string
+	}{
+		"statuspage": {
+			giveContent: `{"page": {"id": "x"}, "incidents": [
+				{"name": "Old", "status": "resolved", "shortlink": "https://stspg.io/old"},
+				{"name": "Database outage", "status": "identified", "shortlink": "https://stspg.io/db",
+					"incident_updates": [{"body": "We are failing over"}, {"body": "Investigating"}]}
+			]}`,
+			wantIncident: &statuspage.Incident{
+				Title: "Database outage", Body: "We are failing over", URL: "https://stspg.io/db",
+			},
+		},
+		"statuspage without incidents": {
+			giveContent: `{"page": {"id": "x"}, "incidents": []}`,
+		},
+		"cachet": {
+			giveContent: `{"meta": {}, "data": [
+				{"name": "Fixed", "message": "Done", "status": 4},
+				{"name": "Planned upgrade", "message": "Next week", "status": 0},
+				{"name": "API is slow", "message": "Watching", "status": 3, "permalink": "https://status/incidents/2"}
+			]}`,
+			wantIncident: &statuspage.Incident{
+				Title: "API is slow", Body: "Watching", URL: "https://status/incidents/2",
+			},
+		},
+		"cachet scheduled only": {
+			giveContent: `{"meta": {}, "data": [{"name": "Planned upgrade", "message": "Next week", "status": 0}]}`,
+		},
+		"simple": {
+			giveContent:  `{"title": "Maintenance", "body": "Upgrading", "url": "https://status"}`,
+			wantIncident: &statuspage.Incident{Title: "Maintenance", Body: "Upgrading", URL: "https://status"},
+		},
+		"simple with not HTTP link": {
+			giveContent:  `{"title": "Maintenance", "url": "javascript:alert(1)"}`,
+			wantIncident: &statuspage.Incident{Title: "Maintenance"},
+		},
+		"simple without incident": {
+			giveContent: `{}`,
+		},
+		"atom": {
+			giveContent: `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Status</title>
+  <entry>
+    <title>Network issues</title>
+    <link rel="alternate" type="text/html" href="https://status/incidents/1"/>
+    <summary>Short</summary>
+    <content type="html">We are investigating</content>
+  </entry>
+  <entry><title>Older</title></entry>
+</feed>`,
+			wantIncident: &statuspage.Incident{
+				Title: "Network issues", Body: "We are investigating", URL: "https://status/incidents/1",
+			},
+		},
+		"atom without entries": {
+			giveContent: `<feed xmlns="http://www.w3.org/2005/Atom"><title>Status</title></feed>`,
+		},
+		"broken JSON": {giveContent: `{`, wantErr: "cannot parse the status page JSON"},
+		"broken XML":  {giveContent: `<feed>`, wantErr: "cannot parse the status page Atom feed"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			incident, err := statuspage.Parse([]byte(tt.giveContent))
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantIncident, incident)
+		})
+	}
+}
+
+func TestSource_URL(t *testing.T) {
+	t.Parallel()
+
+	var (
+		broken atomic.Bool
+		srv    = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if broken.Load() {
+				w.WriteHeader(http.StatusBadGateway)
+
+				return
+			}
+
+			_, _ = w.Write([]byte(`{"title": "Outage", "body": "Fixing", "url": "https://status"}`))
+		}))
+	)
+
+	t.Cleanup(srv.Close)
+
+	var src = statuspage.New(srv.URL, logger.NewNop())
+
+	_, found := src.Current()
+	assert.False(t, found) // not fetched yet
+
+	require.NoError(t, src.Refresh(context.Background()))
+
+	incident, found := src.Current()
+	require.True(t, found)
+	assert.Equal(t, statuspage.Incident{Title: "Outage", Body: "Fixing", URL: "https://status"}, incident)
+
+	broken.Store(true)
+
+	require.ErrorContains(t, src.Refresh(context.Background()), "unexpected status page response status code: 502")
+
+	incident, found = src.Current() // the previous incident is kept
+	require.True(t, found)
+	assert.Equal(t, "Outage", incident.Title)
+
+	srv.Close()
+
+	require.ErrorContains(t, src.Refresh(context.Background()), "cannot fetch the status page")
+}
+
+func TestSource_File(t *testing.T) {
+	t.Parallel()
+
+	var file = filepath.Join(t.TempDir(), "status.json")
+
+	require.NoError(t, os.WriteFile(file, []byte(`{"title": "Outage"}`), 0o600))
+
+	var (
+		src         = statuspage.New(file, logger.NewNop())
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	defer cancel()
+
+	go src.Watch(ctx, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool { _, found := src.Current(); return found }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(file, []byte(`{}`), 0o600)) // the incident is resolved
+
+	assert.Eventually(t, func() bool { _, found := src.Current(); return !found }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.Remove(file))
+	require.ErrorContains(t, src.Refresh(context.Background()), "cannot read the status page file")
+}
@@ -0,0 +1,71 @@
+package suggest
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lru is a bounded least-recently-used cache. It's safe for concurrent use.
+type lru[K comparable, V any] struct {
+	size int
+
+	mu    sync.Mutex
+	order *list.List          // the most recently used items are at the front
+	items map[K]*list.Element // the values are *lruItem[K, V]
+}
+
+type lruItem[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// newLRU creates a new cache holding up to the given number of items.
+func newLRU[K comparable, V any](size int) *lru[K, V] {
+	return &lru[K, V]{size: size, order: list.New(), items: make(map[K]*list.Element, size)}
+}
+
+// Get returns the cached value and marks it as recently used.
+func (c *lru[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.order.MoveToFront(el)
+
+		return el.Value.(*lruItem[K, V]).value, true //nolint:forcetypeassert
+	}
+
+	var zero V
+
+	return zero, false
+}
+
+// Put adds (or updates) the value, evicting the least recently used one if the cache is full.
+func (c *lru[K, V]) Put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruItem[K, V]).value = value //nolint:forcetypeassert
+		c.order.MoveToFront(el)
+
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruItem[K, V]{key: key, value: value})
+
+	if c.order.Len() > c.size {
+		var oldest = c.order.Back()
+
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem[K, V]).key) //nolint:forcetypeassert
+	}
+}
+
+// Len returns the number of the cached items.
+func (c *lru[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
@@ -0,0 +1,92 @@
+package suggest
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU(t *testing.T) {
+	t.Parallel()
+
+	var c = newLRU[string, int](2)
+
+	c.Put("a", 1)
+	c.Put("b", 2)
+
+	got, ok := c.Get("a") // "a" becomes the most recently used
+	assert.True(t, ok)
+	assert.Equal(t, 1, got)
+
+	c.Put("c", 3) // "b" is evicted
+
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Len())
+
+	c.Put("a", 10) // updated, nothing is evicted
+
+	got, ok = c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 10, got)
+
+	got, ok = c.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 3, got)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestSnapshot_Candidates(t *testing.T) {
+	t.Parallel()
+
+	var s = snapshot{grams: make(map[string][]int32)}
+
+	for j := range maxCandidates + 10 {
+		var path = "products/item-" + strconv.Itoa(j)
+
+		for _, g := range gramsOf(path) {
+			s.grams[g] = append(s.grams[g], int32(j)) //nolint:gosec
+		}
+
+		s.entries = append(s.entries, entry{url: path, path: path, tokens: tokenize(path)})
+	}
+
+	assert.Len(t, s.candidates("products/item-7"), maxCandidates)
+	assert.Equal(t, int32(7), s.candidates("products/item-7")[0]) // the most overlapping first
+	assert.Empty(t, s.candidates("wp-login.php"))
+}
+
+func TestSnapshot_PostingsBounded(t *testing.T) {
+	t.Parallel()
+
+	var s = snapshot{grams: make(map[string][]int32)}
+
+	for j := range maxEntries { // every entry shares the most of the n-grams with the others
+		var path = "catalog/products/category/item-" + strconv.Itoa(j)
+
+		for _, g := range gramsOf(path) {
+			s.grams[g] = append(s.grams[g], int32(j)) //nolint:gosec
+		}
+
+		s.entries = append(s.entries, entry{url: path, path: path, tokens: tokenize(path)})
+	}
+
+	var missing = strings.Repeat("catalog/products/category/", 10) + "item-12345" // long and unique
+
+	var total int
+
+	for _, list := range s.postings(missing) {
+		total += len(list)
+	}
+
+	assert.LessOrEqual(t, total, maxPostings)
+
+	// the rarest n-grams are scanned first, so the closest entries are still found
+	var found = s.candidates("catalog/products/category/item-12345")
+
+	require.NotEmpty(t, found)
+	assert.Equal(t, int32(12345), found[0])
+}
@@ -0,0 +1,431 @@
+// Package suggest provides the "did you mean" suggestions for the missing pages, based on the known site URLs
+// (a sitemap or a plain URL list, loaded from a local file or an HTTP(S) URL and refreshed periodically).
+package suggest
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gh.tarampamp.am/error-pages/internal/logger"
+	"gh.tarampamp.am/error-pages/internal/remote"
+)
+
+const (
+	maxSize       = 10 << 20 // the URLs list content size limit (10 MiB, the same as for the sitemaps)
+	maxPathLength = 256      // longer paths are truncated before comparing (to limit the CPU usage)
+	minScore      = 0.5      // the minimal similarity score for the suggestions (from 0 to 1)
+	maxEntries    = 50_000   // the known URLs limit (the rest are ignored)
+	maxCandidates = 200      // the edit distance is calculated for the best pre-filtered candidates only
+	maxPostings   = 10_000   // the n-grams postings (entry indexes) scanned per lookup limit (to bound the cost)
+	maxLookupPath = 64       // the missing path n-grams are taken from its beginning of this length only
+	memoSize      = 1024     // the number of the memoized suggestions (per the missing path and limit)
+	gramSize      = 3        // the path n-grams length, used for the candidates pre-filtering
+)
+
+// entry is the known site URL.
+type entry struct {
+	url    string   // the URL as it's listed
+	path   string   // the normalized path (see [pathOf])
+	tokens []string // the path tokens (see [tokenize])
+}
+
+// snapshot is the loaded URLs list with the lookup structures. It's never modified after the creation, except the
+// memo, which is safe for concurrent use and dropped together with the snapshot on the next refresh.
+type snapshot struct {
+	entries []entry
+	grams   map[string][]int32 // the path n-grams (see [gramsOf]) to the entries indexes
+	memo    *lru[memoKey, []string]
+}
+
+// memoKey is the memoized suggestions key.
+type memoKey struct {
+	path  string
+	limit int
+}
+
+// Index holds the known site URLs. It's safe for concurrent use.
+type Index struct {
+	fetcher remote.Fetcher
+	log     *logger.Logger
+
+	index atomic.Pointer[snapshot] // nil if not loaded yet
+}
+
+// New creates a new index for the given URLs list location (a local file path or an HTTP(S) URL). Nothing is loaded
+// until [Index.Refresh] or [Index.Watch] is called.
+func New(location string, log *logger.Logger) *Index {
+	return &Index{
+		fetcher: remote.Fetcher{
+			Location: location,
+			Name:     "site URLs",
+			MaxSize:  maxSize,
+			Client:   &http.Client{Timeout: 30 * time.Second}, //nolint:mnd
+		},
+		log: log,
+	}
+}
+
+// Len returns the number of the known URLs.
+func (i *Index) Len() int {
+	if snap := i.index.Load(); snap != nil {
+		return len(snap.entries)
+	}
+
+	return 0
+}
+
+// Refresh loads the URLs list again. In case of an error, the previously loaded URLs are kept.
+func (i *Index) Refresh(ctx context.Context) error {
+	content, err := i.fetcher.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	urls, err := Parse(content)
+	if err != nil {
+		return err
+	}
+
+	var snap = snapshot{
+		entries: make([]entry, 0, min(len(urls), maxEntries)),
+		grams:   make(map[string][]int32),
+		memo:    newLRU[memoKey, []string](memoSize),
+	}
+
+	for _, u := range urls {
+		var path = pathOf(u)
+		if path == "" {
+			continue
+		}
+
+		if len(snap.entries) == maxEntries {
+			i.log.Warn("Too many site URLs, the rest are ignored",
+				logger.String("source", i.fetcher.Location),
+				logger.Int("total", len(urls)),
+				logger.Int("limit", maxEntries),
+			)
+
+			break
+		}
+
+		for _, g := range gramsOf(path) {
+			snap.grams[g] = append(snap.grams[g], int32(len(snap.entries))) //nolint:gosec // limited by maxEntries
+		}
+
+		snap.entries = append(snap.entries, entry{url: u, path: path, tokens: tokenize(path)})
+	}
+
+	i.index.Store(&snap)
+
+	return nil
+}
+
+// Watch loads the URLs list immediately and then refreshes it with the given interval until the context is
+// canceled. The errors are logged only, so the failures never break the error pages rendering.
+func (i *Index) Watch(ctx context.Context, interval time.Duration) {
+	remote.Watch(ctx, interval, i.Refresh, func(err error) {
+		i.log.Warn("Failed to refresh the site URLs", logger.String("source", i.fetcher.Location), logger.Error(err))
+	})
+}
+
+// Suggest returns up to the limit of the known URLs closest to the given missing one (the most similar first). The
+// similarity is based on the edit distance between the paths and their common tokens (path segments and words).
+//
+// Only the known URLs sharing the path n-grams with the missing one are compared (the most overlapping first, up to
+// [maxCandidates]; the lookup cost is bounded, see [snapshot.postings]), and the results are memoized until the
+// next refresh.
+func (i *Index) Suggest(missing string, limit int) []string {
+	var snap = i.index.Load()
+
+	if snap == nil || limit <= 0 {
+		return nil
+	}
+
+	var path = pathOf(missing)
+	if path == "" {
+		return nil
+	}
+
+	var key = memoKey{path: path, limit: limit}
+
+	if cached, ok := snap.memo.Get(key); ok {
+		return slices.Clone(cached)
+	}
+
+	var result = snap.suggest(path, limit)
+
+	snap.memo.Put(key, slices.Clone(result))
+
+	return result
+}
+
+// postings returns the entry indexes lists to scan for the path n-grams. The lookup cost doesn't depend on the
+// number of known URLs: the rarest (the most specific) n-grams go first, and no more than [maxPostings] indexes are
+// returned in total (the lists of the common n-grams are skipped). Only the beginning of the path (up to
+// [maxLookupPath]) is used, since the missing path is client-supplied.
+func (s *snapshot) postings(path string) [][]int32 {
+	var lists = make([][]int32, 0, maxLookupPath)
+
+	for _, g := range gramsOf(path[:min(len(path), maxLookupPath)]) {
+		if list := s.grams[g]; len(list) > 0 {
+			lists = append(lists, list)
+		}
+	}
+
+	slices.SortStableFunc(lists, func(a, b []int32) int { return cmp.Compare(len(a), len(b)) })
+
+	var budget = maxPostings
+
+	for i, list := range lists {
+		if len(list) > budget {
+			if i == 0 { // even the rarest n-gram is too common, so its list is cut
+				return [][]int32{list[:budget]}
+			}
+
+			return lists[:i] // the rest are even more common
+		}
+
+		budget -= len(list)
+	}
+
+	return lists
+}
+
+// candidates returns the indexes of the entries sharing the n-grams with the path, the most overlapping first (up to
+// [maxCandidates]).
+func (s *snapshot) candidates(path string) []int32 {
+	var shared = make(map[int32]int)
+
+	for _, list := range s.postings(path) {
+		for _, idx := range list {
+			shared[idx]++
+		}
+	}
+
+	type counted struct{ idx, count int32 }
+
+	var found = make([]counted, 0, len(shared))
+
+	for idx, count := range shared {
+		found = append(found, counted{idx: idx, count: int32(count)}) //nolint:gosec // limited by maxPostings
+	}
+
+	slices.SortFunc(found, func(a, b counted) int {
+		if c := cmp.Compare(b.count, a.count); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.idx, b.idx) // for the stable order
+	})
+
+	var result = make([]int32, 0, min(len(found), maxCandidates))
+
+	for _, c := range found[:min(len(found), maxCandidates)] {
+		result = append(result, c.idx)
+	}
+
+	return result
+}
+
+// suggest returns up to the limit of the known URLs closest to the normalized path (see [Index.Suggest]).
+func (s *snapshot) suggest(path string, limit int) []string {
+	type scored struct {
+		url   string
+		score float64
+	}
+
+	var (
+		tokens = tokenize(path)
+		found  []scored
+	)
+
+	for _, idx := range s.candidates(path) {
+		var e = s.entries[idx]
+
+		if e.path == path {
+			continue // the missing page itself
+		}
+
+		if score := similarity(path, tokens, e); score >= minScore {
+			found = append(found, scored{url: e.url, score: score})
+		}
+	}
+
+	slices.SortStableFunc(found, func(a, b scored) int {
+		if c := cmp.Compare(b.score, a.score); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.url, b.url) // for the stable order (the results are a part of the cache key)
+	})
+
+	var result = make([]string, 0, min(limit, len(found)))
+
+	for _, s := range found[:min(limit, len(found))] {
+		result = append(result, s.url)
+	}
+
+	return result
+}
+
+// similarity returns the similarity score (from 0 to 1) of the path with the known URL: the best of the edit
+// distance based one and the tokens Jaccard index.
+func similarity(path string, tokens []string, e entry) float64 {
+	var common int
+
+	for _, t := range tokens {
+		if slices.Contains(e.tokens, t) {
+			common++
+		}
+	}
+
+	var (
+		union = len(tokens) + len(e.tokens) - common
+		best  float64
+	)
+
+	if union > 0 {
+		best = float64(common) / float64(union)
+	}
+
+	var longest = max(len(path), len(e.path))
+
+	// the edit distance is at least the length difference, so skip the expensive calculation if it can't win
+	if diff := len(path) - len(e.path); 1-float64(max(diff, -diff))/float64(longest) < max(best, minScore) {
+		return best
+	}
+
+	return max(best, 1-float64(levenshtein(path, e.path))/float64(longest))
+}
+
+// levenshtein returns the edit distance between the strings (bytes are compared, which is fine for the paths).
+func levenshtein(a, b string) int {
+	var prev, curr = make([]int, len(b)+1), make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			var cost = 1
+
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+// pathOf returns the normalized URL path: lowercased, without the query, fragment, and leading/trailing slashes.
+func pathOf(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+
+	var path = strings.ToLower(strings.Trim(u.Path, "/"))
+
+	if len(path) > maxPathLength {
+		path = path[:maxPathLength]
+	}
+
+	return path
+}
+
+// gramsOf returns the unique n-grams of the normalized path, plus its tokens shorter than the n-gram (so the short
+// path segments can be matched too).
+func gramsOf(path string) []string {
+	var grams = make([]string, 0, len(path))
+
+	if len(path) < gramSize {
+		grams = append(grams, path)
+	}
+
+	for j := 0; j+gramSize <= len(path); j++ {
+		grams = append(grams, path[j:j+gramSize])
+	}
+
+	for _, t := range tokenize(path) {
+		if len(t) < gramSize {
+			grams = append(grams, t)
+		}
+	}
+
+	slices.Sort(grams)
+
+	return slices.Compact(grams)
+}
+
+// tokenize splits the normalized path into the tokens (path segments and words).
+func tokenize(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '-' || r == '_' || r == '.' || r == '+' || r == ' '
+	})
+}
+
+// sitemap is the sitemap (https://www.sitemaps.org/protocol.html), the needed fields only.
+type sitemap struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+}
+
+// Parse extracts the URLs from the content, which is either a sitemap XML (the sitemap index files are not
+// supported) or a plain list (one URL or path per line; the empty lines and lines starting with "#" are skipped).
+func Parse(content []byte) ([]string, error) {
+	content = bytes.TrimSpace(content)
+
+	if len(content) > 0 && content[0] == '<' {
+		var s sitemap
+
+		if err := xml.Unmarshal(content, &s); err != nil {
+			return nil, fmt.Errorf("cannot parse the sitemap: %w", err)
+		}
+
+		var urls = make([]string, 0, len(s.URLs))
+
+		for _, u := range s.URLs {
+			if loc := strings.TrimSpace(u.Loc); loc != "" {
+				urls = append(urls, loc)
+			}
+		}
+
+		return urls, nil
+	}
+
+	var (
+		urls    []string
+		scanner = bufio.NewScanner(bytes.NewReader(content))
+	)
+
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			urls = append(urls, line)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot parse the URLs list: %w", err)
+	}
+
+	return urls, nil
+}
@@ -0,0 +1,169 @@
+package suggest_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gh.tarampamp.am/error-pages/internal/logger"
+	"gh.tarampamp.am/error-pages/internal/suggest"
+)
+
+const testSitemap = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/</loc></url>
+  <url><loc>https://example.com/products/shoes</loc><lastmod>2024-01-01</lastmod></url>
+  <url><loc>https://example.com/products/shirts</loc></url>
+  <url><loc>https://example.com/blog/2024/how-to-choose-running-shoes</loc></url>
+  <url><loc>https://example.com/about-us</loc></url>
+  <url><loc>https://example.com/contact</loc></url>
+</urlset>`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	urls, err := suggest.Parse([]byte(testSitemap))
+	require.NoError(t, err)
+	assert.Len(t, urls, 6)
+	assert.Equal(t, "https://example.com/products/shoes", urls[1])
+
+	urls, err = suggest.Parse([]byte("# the site pages\n/products/shoes\n\n  /about-us  \nhttps://example.com/contact\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/products/shoes", "/about-us", "https://example.com/contact"}, urls)
+
+	_, err = suggest.Parse([]byte("<urlset>"))
+	require.ErrorContains(t, err, "cannot parse the sitemap")
+}
+
+func TestIndex_Suggest(t *testing.T) {
+	t.Parallel()
+
+	var file = filepath.Join(t.TempDir(), "sitemap.xml")
+
+	require.NoError(t, os.WriteFile(file, []byte(testSitemap), 0o600))
+
+	var idx = suggest.New(file, logger.NewNop())
+
+	assert.Nil(t, idx.Suggest("/prodcuts/shoes", 3)) // not loaded yet
+
+	require.NoError(t, idx.Refresh(context.Background()))
+	assert.Equal(t, 5, idx.Len()) // the root page is skipped
+
+	for name, tt := range map[string]struct {
+		giveURI   string
+		giveLimit int
+		want      []string
+	}{
+		"typo": {
+			giveURI: "/prodcuts/shoes", giveLimit: 3,
+			want: []string{"https://example.com/products/shoes", "https://example.com/products/shirts"},
+		},
+		"restructured": {
+			giveURI: "/articles/how-to-choose-running-shoes?utm=1", giveLimit: 3,
+			want: []string{"https://example.com/blog/2024/how-to-choose-running-shoes"},
+		},
+		"case and trailing slash": {
+			giveURI: "/About_Us/", giveLimit: 3,
+			want: []string{"https://example.com/about-us"},
+		},
+		"limit": {
+			giveURI: "/prodcuts/shoes", giveLimit: 1,
+			want: []string{"https://example.com/products/shoes"},
+		},
+		"full URL": {
+			giveURI: "https://example.com/contacts", giveLimit: 3,
+			want: []string{"https://example.com/contact"},
+		},
+		"nothing similar": {giveURI: "/wp-login.php", giveLimit: 3, want: []string{}},
+		"root":            {giveURI: "/", giveLimit: 3},
+		"zero limit":      {giveURI: "/prodcuts/shoes"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, idx.Suggest(tt.giveURI, tt.giveLimit))
+		})
+	}
+}
+
+func TestIndex_Limits(t *testing.T) {
+	t.Parallel()
+
+	var (
+		file  = filepath.Join(t.TempDir(), "urls.txt")
+		lines strings.Builder
+	)
+
+	for j := range 50_010 {
+		_, _ = fmt.Fprintf(&lines, "/products/item-%d\n", j)
+	}
+
+	require.NoError(t, os.WriteFile(file, []byte(lines.String()), 0o600))
+
+	var idx = suggest.New(file, logger.NewNop())
+
+	require.NoError(t, idx.Refresh(context.Background()))
+	assert.Equal(t, 50_000, idx.Len()) // the rest are ignored
+
+	var got = idx.Suggest("/products/item-7", 3)
+
+	assert.Equal(t, []string{"/products/item-70", "/products/item-71", "/products/item-72"}, got)
+
+	got[0] = "changed" // the memoized result is not affected
+	assert.Equal(t, "/products/item-70", idx.Suggest("/products/item-7", 3)[0])
+
+	// the memoized results are dropped on refresh
+	require.NoError(t, os.WriteFile(file, []byte("/products/item-8\n"), 0o600))
+	require.NoError(t, idx.Refresh(context.Background()))
+	assert.Equal(t, []string{"/products/item-8"}, idx.Suggest("/products/item-7", 3))
+}
+
+func TestIndex_URL(t *testing.T) {
+	t.Parallel()
+
+	var (
+		broken atomic.Bool
+		srv    = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			if broken.Load() {
+				w.WriteHeader(http.StatusInternalServerError)
+
+				return
+			}
+
+			_, _ = w.Write([]byte("/products/shoes\n/about-us\n"))
+		}))
+	)
+
+	t.Cleanup(srv.Close)
+
+	var (
+		idx         = suggest.New(srv.URL, logger.NewNop())
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	defer cancel()
+
+	go idx.Watch(ctx, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool { return idx.Len() == 2 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"/products/shoes"}, idx.Suggest("/product/shoes", 3))
+
+	broken.Store(true)
+
+	require.ErrorContains(t, idx.Refresh(context.Background()), "unexpected site URLs response status code: 500")
+	assert.Equal(t, 2, idx.Len()) // the previous URLs are kept
+
+	require.ErrorContains(t, suggest.New(filepath.Join(t.TempDir(), "missing"), logger.NewNop()).Refresh(ctx),
+		"cannot read the site URLs file",
+	)
+}
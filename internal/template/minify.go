@@ -12,7 +12,12 @@ var htmlMinify = func() *minify.M { //nolint:gochecknoglobals
 	var m = minify.New()
 
 	m.AddFunc("text/css", css.Minify)
-	m.Add("text/html", &html.Minifier{KeepDocumentTags: true, KeepEndTags: true, KeepQuotes: true})
+	m.Add("text/html", &html.Minifier{
+		KeepDocumentTags:    true,
+		KeepEndTags:         true,
+		KeepQuotes:          true,
+		KeepSpecialComments: true, // server-side includes directives (e.g., `<!--# echo var="host" -->`)
+	})
 	m.AddFunc("image/svg+xml", svg.Minify)
 	m.AddFunc("application/javascript", js.Minify)
 
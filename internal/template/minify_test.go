@@ -92,3 +92,22 @@ func TestMiniHTML(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestMiniHTML_SSIDirectives(t *testing.T) {
+	t.Parallel()
+
+	for name, directive := range map[string]string{
+		"nginx":  `<!--# echo var="request_id" default="" -->`,
+		"apache": `<!--#config echomsg="" --><!--#echo encoding="entity" var="UNIQUE_ID" -->`,
+		"esi":    `<esi:vars>$url_encode($(HTTP_X_REQUEST_ID))</esi:vars>`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var got, err = template.MiniHTML("<html>\n<body>\n\t<!-- comment -->\n\t<p>" + directive + "</p>\n</body>\n</html>")
+
+			assert.NoError(t, err)
+			assert.Equal(t, "<html><body><p>"+directive+"</p></body></html>", got) // the regular comment is removed
+		})
+	}
+}
@@ -4,19 +4,47 @@ import "reflect"
 
 //nolint:lll
 type Props struct {
-	Code               uint16 `token:"code"`          // http status code
-	Message            string `token:"message"`       // status message
-	Description        string `token:"description"`   // status description
-	OriginalURI        string `token:"original_uri"`  // (ingress-nginx) URI that caused the error
-	Namespace          string `token:"namespace"`     // (ingress-nginx) namespace where the backend Service is located
-	IngressName        string `token:"ingress_name"`  // (ingress-nginx) name of the Ingress where the backend is defined
-	ServiceName        string `token:"service_name"`  // (ingress-nginx) name of the Service backing the backend
-	ServicePort        string `token:"service_port"`  // (ingress-nginx) port number of the Service backing the backend
-	RequestID          string `token:"request_id"`    // (ingress-nginx) unique ID that identifies the request - same as for backend service
-	ForwardedFor       string `token:"forwarded_for"` // the value of the `X-Forwarded-For` header
-	Host               string `token:"host"`          // the value of the `Host` header
-	ShowRequestDetails bool   `token:"show_details"`  // (config) show request details?
-	L10nDisabled       bool   `token:"l10n_disabled"` // (config) disable localization feature?
+	Code                uint16   `token:"code"`                  // http status code
+	Message             string   `token:"message"`               // status message
+	Description         string   `token:"description"`           // status description
+	OriginalURI         string   `token:"original_uri"`          // (ingress-nginx, envoy, traefik) URI that caused the error
+	Namespace           string   `token:"namespace"`             // (ingress-nginx) namespace where the backend Service is located
+	IngressName         string   `token:"ingress_name"`          // (ingress-nginx) name of the Ingress where the backend is defined
+	ServiceName         string   `token:"service_name"`          // (ingress-nginx) name of the Service backing the backend
+	ServicePort         string   `token:"service_port"`          // (ingress-nginx) port number of the Service backing the backend
+	RequestID           string   `token:"request_id"`            // (ingress-nginx) unique ID that identifies the request - same as for backend service
+	ForwardedFor        string   `token:"forwarded_for"`         // the value of the `X-Forwarded-For` header
+	Host                string   `token:"host"`                  // the value of the `Host` header
+	UpstreamServiceTime string   `token:"upstream_service_time"` // (envoy) time in milliseconds spent by the upstream processing the request
+	Details             []Detail `token:"details"`               // (config) additional details taken from the configured HTTP headers
+	SupportURL          string   `token:"support_url"`           // (namespace overrides) link to the team support
+	IncidentTitle       string   `token:"incident_title"`        // (status page) title of the current incident
+	IncidentBody        string   `token:"incident_body"`         // (status page) latest update of the current incident
+	IncidentURL         string   `token:"incident_url"`          // (status page) link to the current incident page
+	Maintenance         bool     `token:"maintenance"`           // (maintenance) is the maintenance mode active?
+	MaintenanceEnd      string   `token:"maintenance_end"`       // (maintenance) expected end time in RFC 3339 format (empty if unknown)
+	MaintenanceLeft     int64    `token:"maintenance_countdown"` // (maintenance) seconds left until the expected end (zero if unknown)
+	Suggestions         []string `token:"suggestions"`           // (suggestions) known site URLs closest to the missing one (404 only)
+	BasePath            string   `token:"base_path"`             // (config) URL path prefix under which the routes are served
+	TraceID             string   `token:"trace_id"`              // (tracing) ID of the trace the error page rendering belongs to
+	ShowRequestDetails  bool     `token:"show_details"`          // (config) show request details?
+	L10nDisabled        bool     `token:"l10n_disabled"`         // (config) disable localization feature?
+}
+
+// Detail is an additional error page detail (e.g., the cluster name taken from the proxy HTTP header).
+type Detail struct {
+	Name, Value string
+}
+
+// Detail returns the value of the additional detail with the given name, or an empty string if it's not found.
+func (p Props) Detail(name string) string {
+	for _, d := range p.Details {
+		if d.Name == name {
+			return d.Value
+		}
+	}
+
+	return ""
 }
 
 // Values convert the Props struct into a map where each key is a token associated with its corresponding value.
@@ -12,31 +12,66 @@ func TestProps_Values(t *testing.T) {
 	t.Parallel()
 
 	assert.Equal(t, template.Props{
-		Code:               1,
-		Message:            "b",
-		Description:        "c",
-		OriginalURI:        "d",
-		Namespace:          "e",
-		IngressName:        "f",
-		ServiceName:        "g",
-		ServicePort:        "h",
-		RequestID:          "i",
-		ForwardedFor:       "j",
-		L10nDisabled:       true,
-		ShowRequestDetails: false,
+		Code:                1,
+		Message:             "b",
+		Description:         "c",
+		OriginalURI:         "d",
+		Namespace:           "e",
+		IngressName:         "f",
+		ServiceName:         "g",
+		ServicePort:         "h",
+		RequestID:           "i",
+		ForwardedFor:        "j",
+		BasePath:            "/k",
+		TraceID:             "l",
+		UpstreamServiceTime: "m",
+		Details:             []template.Detail{{Name: "n", Value: "o"}},
+		SupportURL:          "p",
+		IncidentTitle:       "q",
+		IncidentBody:        "r",
+		IncidentURL:         "s",
+		Maintenance:         true,
+		MaintenanceEnd:      "t",
+		MaintenanceLeft:     21,
+		Suggestions:         []string{"u"},
+		L10nDisabled:        true,
+		ShowRequestDetails:  false,
 	}.Values(), map[string]any{
-		"code":          uint16(1),
-		"message":       "b",
-		"description":   "c",
-		"original_uri":  "d",
-		"namespace":     "e",
-		"ingress_name":  "f",
-		"service_name":  "g",
-		"service_port":  "h",
-		"request_id":    "i",
-		"forwarded_for": "j",
-		"host":          "", // empty because it's not set
-		"l10n_disabled": true,
-		"show_details":  false,
+		"code":                  uint16(1),
+		"message":               "b",
+		"description":           "c",
+		"original_uri":          "d",
+		"namespace":             "e",
+		"ingress_name":          "f",
+		"service_name":          "g",
+		"service_port":          "h",
+		"request_id":            "i",
+		"forwarded_for":         "j",
+		"host":                  "", // empty because it's not set
+		"upstream_service_time": "m",
+		"details":               []template.Detail{{Name: "n", Value: "o"}},
+		"support_url":           "p",
+		"incident_title":        "q",
+		"incident_body":         "r",
+		"incident_url":          "s",
+		"maintenance":           true,
+		"maintenance_end":       "t",
+		"maintenance_countdown": int64(21),
+		"suggestions":           []string{"u"},
+		"base_path":             "/k",
+		"trace_id":              "l",
+		"l10n_disabled":         true,
+		"show_details":          false,
 	})
 }
+
+func TestProps_Detail(t *testing.T) {
+	t.Parallel()
+
+	var props = template.Props{Details: []template.Detail{{Name: "cluster", Value: "eu-1"}, {Name: "pod", Value: "web-0"}}}
+
+	assert.Equal(t, "eu-1", props.Detail("cluster"))
+	assert.Equal(t, "web-0", props.Detail("pod"))
+	assert.Empty(t, props.Detail("unknown"))
+	assert.Empty(t, template.Props{}.Detail("cluster"))
+}
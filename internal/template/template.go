@@ -15,6 +15,9 @@ import (
 	"gh.tarampamp.am/error-pages/l10n"
 )
 
+// AssetsPathPrefix is the URL path prefix (relative to the base path) under which the static assets are served.
+const AssetsPathPrefix = "/_assets"
+
 var builtInFunctions = template.FuncMap{ //nolint:gochecknoglobals
 	// the current time in unix format (seconds since 1970 UTC):
 	//	`{{ nowUnix }}`	// `1631610000`
@@ -116,6 +119,16 @@ func Render(content string, props Props) (string, error) {
 	maps.Copy(fns, template.FuncMap{ // add custom functions
 		"hide_details": func() bool { return !props.ShowRequestDetails }, // inverted logic
 		"l10n_enabled": func() bool { return !props.L10nDisabled },       // inverted logic
+
+		// value of the additional detail (see the detail headers configuration):
+		//	`{{ detail "cluster" }}`	// `eu-west-1`
+		"detail": props.Detail,
+
+		// URL of the static asset (respecting the base path):
+		//	`{{ asset "logo.png" }}`	// `/_assets/logo.png` (or `/_errors/_assets/logo.png` with the base path)
+		"asset": func(name string) string {
+			return props.BasePath + AssetsPathPrefix + "/" + strings.TrimLeft(name, "/")
+		},
 	})
 
 	// allow the direct access to the properties tokens, e.g. `{{ service_port | json }}`
@@ -127,6 +127,25 @@ func TestRender(t *testing.T) {
 			wantResult:   "Y",
 		},
 
+		"fn asset": {
+			giveTemplate: `{{ asset "logo.png" }} {{ asset "/fonts/font.woff2" }}`,
+			giveProps:    template.Props{},
+			wantResult:   "/_assets/logo.png /_assets/fonts/font.woff2",
+		},
+		"fn asset (with base path)": {
+			giveTemplate: `{{ asset "logo.png" }}`,
+			giveProps:    template.Props{BasePath: "/_errors"},
+			wantResult:   "/_errors/_assets/logo.png",
+		},
+		"fn detail": {
+			giveTemplate: `{{ detail "cluster" }}|{{ detail "missing" }}|{{ range details }}{{ .Name }}={{ .Value }};{{ end }}`,
+			giveProps: template.Props{Details: []template.Detail{
+				{Name: "cluster", Value: "eu-1"},
+				{Name: "pod", Value: "web-0"},
+			}},
+			wantResult: "eu-1||cluster=eu-1;pod=web-0;",
+		},
+
 		"complete example with every property and function": {
 			giveProps: template.Props{
 				Code:               404,
@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+type (
+	// TraceID is a unique identifier of the trace.
+	TraceID [16]byte
+
+	// SpanID is a unique identifier of the span within the trace.
+	SpanID [8]byte
+
+	// SpanContext contains the identifying trace information about the span.
+	SpanContext struct {
+		TraceID    TraceID
+		SpanID     SpanID
+		Sampled    bool
+		TraceState string
+	}
+)
+
+// String returns the lower-case hex representation of the trace ID.
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+// IsValid checks if the trace ID is not all zeros.
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+// String returns the lower-case hex representation of the span ID.
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// IsValid checks if the span ID is not all zeros.
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+// IsValid checks if the span context has valid trace and span IDs.
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+
+// Traceparent returns the span context in the W3C `traceparent` header format.
+func (sc SpanContext) Traceparent() string {
+	var flags = "00"
+
+	if sc.Sampled {
+		flags = "01"
+	}
+
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent parses the W3C `traceparent` header value (https://www.w3.org/TR/trace-context/#traceparent-header)
+// and returns the span context of the remote parent span. The trace state is set as is.
+func ParseTraceparent(traceparent, tracestate string) (SpanContext, bool) {
+	// version-trace_id-parent_id-flags, e.g. 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+	var parts = strings.Split(strings.TrimSpace(traceparent), "-")
+
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" { //nolint:mnd // version "ff" is invalid
+		return SpanContext{}, false
+	}
+
+	if parts[0] == "00" && len(parts) != 4 { //nolint:mnd // version 00 has exactly 4 parts
+		return SpanContext{}, false
+	}
+
+	var (
+		sc    SpanContext
+		flags [1]byte
+	)
+
+	if !decodeHex(sc.TraceID[:], parts[1]) || !decodeHex(sc.SpanID[:], parts[2]) || !decodeHex(flags[:], parts[3]) {
+		return SpanContext{}, false
+	}
+
+	if !sc.IsValid() {
+		return SpanContext{}, false
+	}
+
+	sc.Sampled = flags[0]&0x01 == 0x01
+	sc.TraceState = strings.TrimSpace(tracestate)
+
+	return sc, true
+}
+
+// decodeHex decodes the lower-case hex string into the destination, the length must match exactly.
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return false
+	}
+
+	_, err := hex.Decode(dst, []byte(s))
+
+	return err == nil
+}
+
+// newTraceID generates a new random trace ID.
+func newTraceID() (id TraceID) { _, _ = rand.Read(id[:]); return } //nolint:nlreturn
+
+// newSpanID generates a new random span ID.
+func newSpanID() (id SpanID) { _, _ = rand.Read(id[:]); return } //nolint:nlreturn
@@ -0,0 +1,55 @@
+package tracing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gh.tarampamp.am/error-pages/internal/tracing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		var sc, ok = tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", " congo=t61rcWkgMzE ")
+
+		assert.True(t, ok)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+		assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+		assert.True(t, sc.Sampled)
+		assert.Equal(t, "congo=t61rcWkgMzE", sc.TraceState)
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+	})
+
+	t.Run("not sampled", func(t *testing.T) {
+		var sc, ok = tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", "")
+
+		assert.True(t, ok)
+		assert.False(t, sc.Sampled)
+	})
+
+	t.Run("future version", func(t *testing.T) {
+		var _, ok = tracing.ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-what-ever", "")
+
+		assert.True(t, ok)
+	})
+
+	for name, give := range map[string]string{
+		"empty":            "",
+		"garbage":          "foo-bar",
+		"invalid version":  "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"extra parts (00)": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-00",
+		"zero trace id":    "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"zero span id":     "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"short trace id":   "00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"upper case":       "00-4BF92F3577B34DA6A3CE929D0E0E4736-00F067AA0BA902B7-01",
+		"not hex":          "00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01",
+	} {
+		t.Run(name, func(t *testing.T) {
+			var _, ok = tracing.ParseTraceparent(give, "")
+
+			assert.False(t, ok)
+		})
+	}
+}
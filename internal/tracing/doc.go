@@ -0,0 +1,5 @@
+// Package tracing implements a minimal OpenTelemetry-compatible tracer: it continues the incoming W3C trace
+// contexts (the `traceparent` and `tracestate` HTTP headers) and exports the finished spans to the OTLP/HTTP
+// collector using the JSON encoding. Only the server spans of a single service are produced, so the official
+// OpenTelemetry SDK and its exporters (with the protobuf and gRPC dependencies) are not worth pulling in.
+package tracing
@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type (
+	// Span represents a single operation within a trace. Call the End method when the operation is finished.
+	Span struct {
+		tracer    *Tracer
+		name      string
+		ctx       SpanContext
+		parentID  SpanID
+		startedAt time.Time
+		endedAt   time.Time
+
+		mu    sync.Mutex
+		attrs []Attribute
+		once  sync.Once
+	}
+
+	// Attribute is a key-value pair describing the span (the value is a string, int64, or bool).
+	Attribute struct {
+		Key   string
+		Value any
+	}
+)
+
+// String creates a string attribute.
+func String(key, value string) Attribute { return Attribute{Key: key, Value: value} }
+
+// Int creates an integer attribute.
+func Int(key string, value int) Attribute { return Attribute{Key: key, Value: int64(value)} }
+
+// Bool creates a boolean attribute.
+func Bool(key string, value bool) Attribute { return Attribute{Key: key, Value: value} }
+
+// Context returns the span context.
+func (s *Span) Context() SpanContext { return s.ctx }
+
+// SetAttributes adds the attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	s.attrs = append(s.attrs, attrs...)
+	s.mu.Unlock()
+}
+
+// End finishes the span and queues it for the export (if sampled). Only the first call has an effect.
+func (s *Span) End() {
+	s.once.Do(func() {
+		s.endedAt = time.Now()
+
+		if s.ctx.Sampled && s.tracer != nil {
+			s.tracer.enqueue(s)
+		}
+	})
+}
+
+// encodeAttributes converts the attributes into the OTLP JSON representation (note that the int64 values are
+// encoded as strings).
+func encodeAttributes(attrs []Attribute) []map[string]any {
+	var result = make([]map[string]any, 0, len(attrs))
+
+	for _, a := range attrs {
+		var value map[string]any
+
+		switch v := a.Value.(type) {
+		case bool:
+			value = map[string]any{"boolValue": v}
+		case int64:
+			value = map[string]any{"intValue": fmt.Sprint(v)}
+		default:
+			value = map[string]any{"stringValue": fmt.Sprint(v)}
+		}
+
+		result = append(result, map[string]any{"key": a.Key, "value": value})
+	}
+
+	return result
+}
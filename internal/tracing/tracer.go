@@ -0,0 +1,257 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gh.tarampamp.am/error-pages/internal/logger"
+)
+
+type (
+	// Tracer creates spans and exports the sampled ones to the OTLP/HTTP collector in batches. It's safe for
+	// concurrent use. To flush the queued spans and stop the exporting goroutine, call the Close method.
+	Tracer struct {
+		log      *logger.Logger
+		opts     options
+		endpoint string
+		resource []Attribute
+
+		queue   chan *Span
+		stopCh  chan struct{}
+		doneCh  chan struct{}
+		stopped sync.Once
+
+		exportCtx    context.Context    // canceled when the Close context is done, so the exporting is aborted
+		cancelExport context.CancelFunc // cancels the exportCtx
+	}
+
+	// Option allows to customize the tracer.
+	Option func(*options)
+
+	options struct {
+		httpClient    *http.Client
+		scopeName     string
+		scopeVersion  string
+		batchSize     int
+		flushInterval time.Duration
+	}
+)
+
+// WithHTTPClient sets the HTTP client used to send the spans to the collector.
+func WithHTTPClient(c *http.Client) Option { return func(o *options) { o.httpClient = c } }
+
+// WithScope sets the instrumentation scope name and version.
+func WithScope(name, version string) Option {
+	return func(o *options) { o.scopeName, o.scopeVersion = name, version }
+}
+
+// WithBatch sets the maximal number of spans in a single export request and the interval between the exports.
+func WithBatch(size int, flushInterval time.Duration) Option {
+	return func(o *options) { o.batchSize, o.flushInterval = size, flushInterval }
+}
+
+// New creates a new tracer that exports the spans to the OTLP/HTTP collector. The endpoint is the collector base
+// URL (e.g., "http://localhost:4318"); the "/v1/traces" path is appended if missing. The goroutine for the spans
+// exporting is started immediately.
+func New(log *logger.Logger, endpoint, serviceName string, opts ...Option) *Tracer {
+	var o = options{
+		httpClient:    &http.Client{Timeout: 10 * time.Second}, //nolint:mnd
+		scopeName:     "error-pages",
+		batchSize:     512,             //nolint:mnd
+		flushInterval: 5 * time.Second, //nolint:mnd
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if endpoint = strings.TrimRight(endpoint, "/"); !strings.HasSuffix(endpoint, "/v1/traces") {
+		endpoint += "/v1/traces"
+	}
+
+	var exportCtx, cancelExport = context.WithCancel(context.Background())
+
+	var t = &Tracer{
+		log:      log,
+		opts:     o,
+		endpoint: endpoint,
+		resource: []Attribute{String("service.name", serviceName)},
+		queue:    make(chan *Span, o.batchSize*4), //nolint:mnd
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+
+		exportCtx:    exportCtx,
+		cancelExport: cancelExport,
+	}
+
+	go t.loop()
+
+	return t
+}
+
+// Start creates a new server span. If the parent (remote) span context is valid, the span continues its trace and
+// respects its sampling decision, otherwise a new sampled trace is started.
+func (t *Tracer) Start(name string, parent SpanContext) *Span {
+	var span = Span{tracer: t, name: name, startedAt: time.Now()}
+
+	if parent.IsValid() {
+		span.ctx = SpanContext{TraceID: parent.TraceID, Sampled: parent.Sampled, TraceState: parent.TraceState}
+		span.parentID = parent.SpanID
+	} else {
+		span.ctx = SpanContext{TraceID: newTraceID(), Sampled: true}
+	}
+
+	span.ctx.SpanID = newSpanID()
+
+	return &span
+}
+
+// Close flushes the queued spans and stops the exporting goroutine. The context limits the flushing time: when
+// it's done, the in-flight export requests are aborted, and the goroutine is stopped before returning.
+func (t *Tracer) Close(ctx context.Context) error {
+	t.stopped.Do(func() { close(t.stopCh) })
+
+	defer t.cancelExport()
+
+	select {
+	case <-t.doneCh:
+		return nil
+	case <-ctx.Done():
+		t.cancelExport()
+		<-t.doneCh // the remaining exports fail fast with the canceled context
+
+		return ctx.Err()
+	}
+}
+
+// enqueue adds the finished span to the export queue. If the queue is full, the span is dropped.
+func (t *Tracer) enqueue(s *Span) {
+	select {
+	case <-t.stopCh:
+		return // the tracer is closed
+	default:
+	}
+
+	select {
+	case t.queue <- s:
+	default:
+		t.log.Warn("The tracing spans queue is full, the span is dropped", logger.String("name", s.name))
+	}
+}
+
+// loop collects the spans into batches and exports them periodically or when the batch is full.
+func (t *Tracer) loop() {
+	defer close(t.doneCh)
+
+	var (
+		ticker = time.NewTicker(t.opts.flushInterval)
+		batch  = make([]*Span, 0, t.opts.batchSize)
+	)
+
+	defer ticker.Stop()
+
+	var flush = func() {
+		if len(batch) > 0 {
+			if err := t.export(batch); err != nil {
+				t.log.Error("Failed to export the tracing spans", logger.Int("count", len(batch)), logger.Error(err))
+			}
+
+			batch = batch[:0]
+		}
+	}
+
+	for {
+		select {
+		case s := <-t.queue:
+			if batch = append(batch, s); len(batch) >= t.opts.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-t.stopCh:
+			for { // drain the queue
+				select {
+				case s := <-t.queue:
+					if batch = append(batch, s); len(batch) >= t.opts.batchSize {
+						flush()
+					}
+				default:
+					flush()
+
+					return
+				}
+			}
+		}
+	}
+}
+
+// export sends the spans to the collector using the OTLP/HTTP JSON encoding.
+func (t *Tracer) export(spans []*Span) error {
+	var body, mErr = json.Marshal(t.payload(spans))
+	if mErr != nil {
+		return mErr
+	}
+
+	req, rErr := http.NewRequestWithContext(t.exportCtx, http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if rErr != nil {
+		return rErr
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.opts.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected response status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// payload builds the ExportTraceServiceRequest message (https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding).
+func (t *Tracer) payload(spans []*Span) map[string]any {
+	var list = make([]map[string]any, 0, len(spans))
+
+	for _, s := range spans {
+		var span = map[string]any{
+			"traceId":           s.ctx.TraceID.String(),
+			"spanId":            s.ctx.SpanID.String(),
+			"name":              s.name,
+			"kind":              2, //nolint:mnd // SPAN_KIND_SERVER
+			"startTimeUnixNano": fmt.Sprint(s.startedAt.UnixNano()),
+			"endTimeUnixNano":   fmt.Sprint(s.endedAt.UnixNano()),
+			"attributes":        encodeAttributes(s.attrs),
+		}
+
+		if s.parentID.IsValid() {
+			span["parentSpanId"] = s.parentID.String()
+		}
+
+		if s.ctx.TraceState != "" {
+			span["traceState"] = s.ctx.TraceState
+		}
+
+		list = append(list, span)
+	}
+
+	return map[string]any{
+		"resourceSpans": []any{map[string]any{
+			"resource": map[string]any{"attributes": encodeAttributes(t.resource)},
+			"scopeSpans": []any{map[string]any{
+				"scope": map[string]any{"name": t.opts.scopeName, "version": t.opts.scopeVersion},
+				"spans": list,
+			}},
+		}},
+	}
+}
@@ -0,0 +1,194 @@
+package tracing_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gh.tarampamp.am/error-pages/internal/logger"
+	"gh.tarampamp.am/error-pages/internal/tracing"
+)
+
+// otlpReceiver is a local stand-in for the OTLP/HTTP collector that records the received spans.
+type otlpReceiver struct {
+	mu       sync.Mutex
+	paths    []string
+	requests []map[string]any
+}
+
+func (r *otlpReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var body, _ = io.ReadAll(req.Body)
+
+	var payload map[string]any
+
+	if req.Header.Get("Content-Type") != "application/json" || json.Unmarshal(body, &payload) != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	r.mu.Lock()
+	r.paths, r.requests = append(r.paths, req.URL.Path), append(r.requests, payload)
+	r.mu.Unlock()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// spans returns the spans from all received requests.
+func (r *otlpReceiver) spans() (spans []map[string]any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, req := range r.requests {
+		for _, rs := range req["resourceSpans"].([]any) {
+			for _, ss := range rs.(map[string]any)["scopeSpans"].([]any) {
+				for _, s := range ss.(map[string]any)["spans"].([]any) {
+					spans = append(spans, s.(map[string]any))
+				}
+			}
+		}
+	}
+
+	return
+}
+
+func TestTracer(t *testing.T) {
+	t.Parallel()
+
+	var (
+		receiver = new(otlpReceiver)
+		srv      = httptest.NewServer(receiver)
+	)
+
+	t.Cleanup(srv.Close)
+
+	var tracer = tracing.New(logger.NewNop(), srv.URL, "test-service", tracing.WithScope("test", "1.2.3"))
+
+	parent, ok := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "foo=bar")
+	require.True(t, ok)
+
+	var child = tracer.Start("child", parent)
+	child.SetAttributes(tracing.String("str", "value"), tracing.Int("int", 404), tracing.Bool("bool", true))
+	child.End()
+	child.End() // the second call is ignored
+
+	assert.Equal(t, parent.TraceID, child.Context().TraceID)
+	assert.NotEqual(t, parent.SpanID, child.Context().SpanID)
+
+	var root = tracer.Start("root", tracing.SpanContext{})
+	root.End()
+
+	assert.True(t, root.Context().IsValid())
+	assert.NotEqual(t, parent.TraceID, root.Context().TraceID)
+
+	notSampledParent, ok := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", "")
+	require.True(t, ok)
+
+	tracer.Start("not-sampled", notSampledParent).End()
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, tracer.Close(ctx))
+	require.NoError(t, tracer.Close(ctx)) // idempotent
+
+	tracer.Start("after-close", tracing.SpanContext{}).End() // must not panic
+
+	receiver.mu.Lock()
+	require.Equal(t, []string{"/v1/traces"}, receiver.paths)
+
+	var resourceSpans = receiver.requests[0]["resourceSpans"].([]any)[0].(map[string]any)
+	receiver.mu.Unlock()
+
+	assert.Equal(t, map[string]any{"attributes": []any{
+		map[string]any{"key": "service.name", "value": map[string]any{"stringValue": "test-service"}},
+	}}, resourceSpans["resource"])
+	assert.Equal(t,
+		map[string]any{"name": "test", "version": "1.2.3"},
+		resourceSpans["scopeSpans"].([]any)[0].(map[string]any)["scope"],
+	)
+
+	var spans = receiver.spans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "child", spans[0]["name"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0]["traceId"])
+	assert.Equal(t, child.Context().SpanID.String(), spans[0]["spanId"])
+	assert.Equal(t, "00f067aa0ba902b7", spans[0]["parentSpanId"])
+	assert.Equal(t, "foo=bar", spans[0]["traceState"])
+	assert.EqualValues(t, 2, spans[0]["kind"])
+	assert.NotEmpty(t, spans[0]["startTimeUnixNano"])
+	assert.NotEmpty(t, spans[0]["endTimeUnixNano"])
+	assert.Equal(t, []any{
+		map[string]any{"key": "str", "value": map[string]any{"stringValue": "value"}},
+		map[string]any{"key": "int", "value": map[string]any{"intValue": "404"}},
+		map[string]any{"key": "bool", "value": map[string]any{"boolValue": true}},
+	}, spans[0]["attributes"])
+
+	assert.Equal(t, "root", spans[1]["name"])
+	assert.NotContains(t, spans[1], "parentSpanId")
+}
+
+func TestTracer_Batching(t *testing.T) {
+	t.Parallel()
+
+	var (
+		receiver = new(otlpReceiver)
+		srv      = httptest.NewServer(receiver)
+	)
+
+	t.Cleanup(srv.Close)
+
+	// the endpoint with the path is used as is
+	var tracer = tracing.New(logger.NewNop(), srv.URL+"/v1/traces/", "svc", tracing.WithBatch(2, time.Hour))
+
+	for range 5 {
+		tracer.Start("span", tracing.SpanContext{}).End()
+	}
+
+	require.NoError(t, tracer.Close(context.Background()))
+
+	receiver.mu.Lock()
+	assert.Equal(t, []string{"/v1/traces", "/v1/traces", "/v1/traces"}, receiver.paths) // 2 + 2 + 1
+	receiver.mu.Unlock()
+
+	assert.Len(t, receiver.spans(), 5)
+}
+
+func TestTracer_CloseTimeout(t *testing.T) {
+	t.Parallel()
+
+	var (
+		requested = make(chan struct{})
+		srv       = httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+			_, _ = io.Copy(io.Discard, req.Body) // otherwise, the aborted request is not noticed
+
+			close(requested)
+			<-req.Context().Done() // the collector hangs until the request is aborted
+		}))
+	)
+
+	t.Cleanup(srv.Close)
+
+	var tracer = tracing.New(logger.NewNop(), srv.URL, "svc", tracing.WithBatch(1, time.Hour))
+
+	tracer.Start("span", tracing.SpanContext{}).End()
+
+	<-requested
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var startedAt = time.Now()
+
+	require.ErrorIs(t, tracer.Close(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(startedAt), 5*time.Second) // not the HTTP client timeout
+}
@@ -1022,6 +1022,91 @@ Object.defineProperty(window, 'l10n', {
         ['ro', 'Marcă temporală'],
         ['it', 'Timestamp'],
       ])],
+      [tkn('Scheduled maintenance'), new Map([
+        ['fr', 'Maintenance programmée'],
+        ['ru', 'Плановое обслуживание'],
+        ['uk', 'Планове обслуговування'],
+        ['pt', 'Manutenção programada'],
+        ['nl', 'Gepland onderhoud'],
+        ['de', 'Geplante Wartung'],
+        ['es', 'Mantenimiento programado'],
+        ['zh', '计划维护'],
+        ['id', 'Pemeliharaan terjadwal'],
+        ['pl', 'Planowana konserwacja'],
+        ['ko', '예정된 점검'],
+        ['hu', 'Tervezett karbantartás'],
+        ['no', 'Planlagt vedlikehold'],
+        ['ro', 'Mentenanță programată'],
+        ['it', 'Manutenzione programmata'],
+      ])],
+      [tkn('Scheduled maintenance until'), new Map([
+        ['fr', 'Maintenance programmée jusqu\'au'],
+        ['ru', 'Плановое обслуживание до'],
+        ['uk', 'Планове обслуговування до'],
+        ['pt', 'Manutenção programada até'],
+        ['nl', 'Gepland onderhoud tot'],
+        ['de', 'Geplante Wartung bis'],
+        ['es', 'Mantenimiento programado hasta'],
+        ['zh', '计划维护，预计结束于'],
+        ['id', 'Pemeliharaan terjadwal hingga'],
+        ['pl', 'Planowana konserwacja do'],
+        ['ko', '예정된 점검 종료 시각:'],
+        ['hu', 'Tervezett karbantartás eddig:'],
+        ['no', 'Planlagt vedlikehold til'],
+        ['ro', 'Mentenanță programată până la'],
+        ['it', 'Manutenzione programmata fino al'],
+      ])],
+      [tkn('We are performing the scheduled maintenance. We will be back soon'), new Map([
+        ['fr', 'Nous effectuons une maintenance programmée. Nous serons bientôt de retour'],
+        ['ru', 'Мы проводим плановое обслуживание. Скоро вернёмся'],
+        ['uk', 'Ми проводимо планове обслуговування. Скоро повернемося'],
+        ['pt', 'Estamos realizando uma manutenção programada. Voltaremos em breve'],
+        ['nl', 'We voeren gepland onderhoud uit. We zijn snel terug'],
+        ['de', 'Wir führen eine geplante Wartung durch. Wir sind bald zurück'],
+        ['es', 'Estamos realizando un mantenimiento programado. Volveremos pronto'],
+        ['zh', '我们正在进行计划维护，很快就会恢复'],
+        ['id', 'Kami sedang melakukan pemeliharaan terjadwal. Kami akan segera kembali'],
+        ['pl', 'Przeprowadzamy planowaną konserwację. Wkrótce wracamy'],
+        ['ko', '예정된 점검을 진행하고 있습니다. 곧 돌아오겠습니다'],
+        ['hu', 'Tervezett karbantartást végzünk. Hamarosan visszatérünk'],
+        ['no', 'Vi utfører planlagt vedlikehold. Vi er snart tilbake'],
+        ['ro', 'Efectuăm o mentenanță programată. Revenim în curând'],
+        ['it', 'Stiamo effettuando una manutenzione programmata. Torneremo presto'],
+      ])],
+      [tkn('More details'), new Map([
+        ['fr', 'Plus de détails'],
+        ['ru', 'Подробнее'],
+        ['uk', 'Докладніше'],
+        ['pt', 'Mais detalhes'],
+        ['nl', 'Meer details'],
+        ['de', 'Weitere Details'],
+        ['es', 'Más detalles'],
+        ['zh', '更多详情'],
+        ['id', 'Detail selengkapnya'],
+        ['pl', 'Więcej szczegółów'],
+        ['ko', '자세히 보기'],
+        ['hu', 'További részletek'],
+        ['no', 'Flere detaljer'],
+        ['ro', 'Mai multe detalii'],
+        ['it', 'Maggiori dettagli'],
+      ])],
+      [tkn('Maybe you were looking for'), new Map([
+        ['fr', 'Vous cherchiez peut-être'],
+        ['ru', 'Возможно, вы искали'],
+        ['uk', 'Можливо, ви шукали'],
+        ['pt', 'Talvez você estivesse procurando'],
+        ['nl', 'Misschien zocht u'],
+        ['de', 'Vielleicht suchten Sie'],
+        ['es', 'Quizás estaba buscando'],
+        ['zh', '您可能在找'],
+        ['id', 'Mungkin Anda mencari'],
+        ['pl', 'Być może szukałeś'],
+        ['ko', '찾으시는 페이지가 이것인가요'],
+        ['hu', 'Talán ezt kereste'],
+        ['no', 'Kanskje du lette etter'],
+        ['ro', 'Poate căutați'],
+        ['it', 'Forse stavi cercando'],
+      ])],
       [tkn('client-side error'), new Map([
         ['fr', 'Erreur Client'],
         ['ru', 'ошибка на стороне клиента'],
@@ -0,0 +1,211 @@
+// Package errorpages renders the error pages in-process, using the same built-in templates, HTTP codes descriptions
+// (with the wildcards support), response formats and localization script as the error pages server.
+package errorpages
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+
+	"github.com/valyala/fasthttp"
+
+	"gh.tarampamp.am/error-pages/internal/config"
+	"gh.tarampamp.am/error-pages/internal/http/handlers/error_page"
+	"gh.tarampamp.am/error-pages/internal/template"
+)
+
+// Format is the error page response format.
+type Format string
+
+// The supported response formats.
+const (
+	FormatHTML      Format = "html"
+	FormatJSON      Format = "json"
+	FormatXML       Format = "xml"
+	FormatPlainText Format = "plaintext"
+)
+
+// ContentType returns the value for the `Content-Type` response header.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	case FormatJSON:
+		return "application/json; charset=utf-8"
+	case FormatXML:
+		return "application/xml; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// CodeDescription is the HTTP code description.
+type CodeDescription struct {
+	Message     string // a short description of the HTTP error
+	Description string // a longer description of the HTTP error
+}
+
+// Config is the renderer configuration. The zero value is valid and uses the defaults.
+type Config struct {
+	// Template is the name of the built-in template (see [Templates]). The first one is used if empty.
+	Template string
+
+	// TemplateContent is the custom HTML template content, which takes precedence over the Template.
+	TemplateContent string
+
+	// Codes hold the HTTP codes descriptions, added to the built-in ones (and overriding them). The codes may be
+	// written in a non-strict manner, e.g. "4xx" or "5**".
+	Codes map[string]CodeDescription
+
+	// ShowDetails enables the request details on the error pages.
+	ShowDetails bool
+
+	// DisableL10n disables the error pages localization.
+	DisableL10n bool
+
+	// DisableMinification disables the HTML minification.
+	DisableMinification bool
+
+	// RespondWithSameHTTPCode makes the [Renderer.Handler] respond with the same HTTP code as the rendered error
+	// page (otherwise, 200 is used).
+	RespondWithSameHTTPCode bool
+}
+
+// Details are the request details, shown on the error pages when the [Config.ShowDetails] is enabled (except the
+// request ID, which is always passed to the templates).
+type Details struct {
+	OriginalURI  string // URI that caused the error
+	Namespace    string // namespace where the backend service is located
+	IngressName  string // name of the ingress where the backend is defined
+	ServiceName  string // name of the service backing the backend
+	ServicePort  string // port number of the service backing the backend
+	RequestID    string // unique ID that identifies the request
+	ForwardedFor string // the value of the `X-Forwarded-For` header
+	Host         string // the value of the `Host` header
+}
+
+// Renderer renders the error pages. It's safe for concurrent use.
+type Renderer struct {
+	cfg      config.Config
+	template string // the HTML template content
+	mini     bool   // minify the HTML?
+}
+
+// Templates returns the sorted list of the built-in template names.
+func Templates() []string {
+	var cfg = config.New()
+
+	return cfg.Templates.Names()
+}
+
+// New creates a new renderer using the given configuration.
+func New(c Config) (*Renderer, error) {
+	var r = Renderer{cfg: config.New(), mini: !c.DisableMinification}
+
+	r.cfg.ShowDetails, r.cfg.L10n.Disable = c.ShowDetails, c.DisableL10n
+	r.cfg.RespondWithSameHTTPCode = c.RespondWithSameHTTPCode
+
+	switch {
+	case c.TemplateContent != "":
+		r.template = c.TemplateContent
+	case c.Template != "":
+		tpl, found := r.cfg.Templates.Get(c.Template)
+		if !found {
+			return nil, fmt.Errorf("template %q not found", c.Template)
+		}
+
+		r.template = tpl
+	default:
+		r.template, _ = r.cfg.Templates.Get(r.cfg.TemplateName)
+	}
+
+	r.cfg.Codes = maps.Clone(r.cfg.Codes)
+
+	for code, desc := range c.Codes {
+		r.cfg.Codes[code] = config.CodeDescription{Message: desc.Message, Description: desc.Description}
+	}
+
+	return &r, nil
+}
+
+// Describe returns the description of the given HTTP code. The standard status text (or the fallback) is used as
+// the message if the code is not described.
+func (r *Renderer) Describe(code uint16) CodeDescription {
+	if desc, found := r.cfg.Codes.Find(code); found {
+		return CodeDescription{Message: desc.Message, Description: desc.Description}
+	}
+
+	if text := http.StatusText(int(code)); text != "" {
+		return CodeDescription{Message: text}
+	}
+
+	return CodeDescription{Message: "Unknown Status Code"} // fallback
+}
+
+// Render renders the error page for the given HTTP code in the given format. The details may be nil.
+func (r *Renderer) Render(code uint16, format Format, details *Details) ([]byte, error) {
+	var (
+		desc  = r.Describe(code)
+		props = template.Props{
+			Code:               code,
+			Message:            desc.Message,
+			Description:        desc.Description,
+			ShowRequestDetails: r.cfg.ShowDetails,
+			L10nDisabled:       r.cfg.L10n.Disable,
+		}
+	)
+
+	if details != nil {
+		props.RequestID = details.RequestID
+
+		if r.cfg.ShowDetails {
+			props.OriginalURI = details.OriginalURI
+			props.Namespace = details.Namespace
+			props.IngressName = details.IngressName
+			props.ServiceName = details.ServiceName
+			props.ServicePort = details.ServicePort
+			props.ForwardedFor = details.ForwardedFor
+			props.Host = details.Host
+		}
+	}
+
+	var tpl string
+
+	switch format {
+	case FormatHTML:
+		tpl = r.template
+	case FormatJSON:
+		tpl = r.cfg.Formats.JSON
+	case FormatXML:
+		tpl = r.cfg.Formats.XML
+	default:
+		tpl = r.cfg.Formats.PlainText
+	}
+
+	content, err := template.Render(tpl, props)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the %s template: %w", format, err)
+	}
+
+	if format == FormatHTML && r.mini {
+		if content, err = template.MiniHTML(content); err != nil {
+			return nil, fmt.Errorf("HTML minification failed: %w", err)
+		}
+	}
+
+	return []byte(content), nil
+}
+
+// NegotiateFormat detects the response format preferred by the client, based on the `Content-Type`, `X-Format` and
+// `Accept` request headers (in that order). The plain text is used if the format is not recognized.
+func (r *Renderer) NegotiateFormat(req *http.Request) Format {
+	var headers fasthttp.RequestHeader
+
+	for _, name := range [...]string{"Content-Type", "X-Format", "Accept"} {
+		if value := req.Header.Get(name); value != "" {
+			headers.Set(name, value)
+		}
+	}
+
+	return Format(error_page.PreferredFormatName(&headers))
+}
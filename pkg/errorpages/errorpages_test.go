@@ -0,0 +1,129 @@
+package errorpages_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gh.tarampamp.am/error-pages/pkg/errorpages"
+)
+
+func TestTemplates(t *testing.T) {
+	t.Parallel()
+
+	assert.Contains(t, errorpages.Templates(), "ghost")
+	assert.Contains(t, errorpages.Templates(), "l7")
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	_, err := errorpages.New(errorpages.Config{Template: "ghost"})
+	require.NoError(t, err)
+
+	_, err = errorpages.New(errorpages.Config{Template: "foo"})
+	require.ErrorContains(t, err, `template "foo" not found`)
+}
+
+func TestRenderer_Describe(t *testing.T) {
+	t.Parallel()
+
+	r, err := errorpages.New(errorpages.Config{Codes: map[string]errorpages.CodeDescription{
+		"404": {Message: "Nope", Description: "Nothing here"},
+		"45x": {Message: "Custom"},
+	}})
+	require.NoError(t, err)
+
+	assert.Equal(t, errorpages.CodeDescription{Message: "Nope", Description: "Nothing here"}, r.Describe(404))
+	assert.Equal(t, errorpages.CodeDescription{Message: "Custom"}, r.Describe(451))
+	assert.Equal(t, "Internal Server Error", r.Describe(500).Message) // built-in
+	assert.Equal(t, "Early Hints", r.Describe(103).Message)           // standard status text
+	assert.Equal(t, "Unknown Status Code", r.Describe(599).Message)   // fallback
+}
+
+func TestRenderer_Render(t *testing.T) {
+	t.Parallel()
+
+	r, err := errorpages.New(errorpages.Config{
+		TemplateContent:     "<p>{{ code }} {{ message }} [{{ host }}] [{{ request_id }}] {{ l10n_enabled }}</p>",
+		ShowDetails:         true,
+		DisableL10n:         true,
+		DisableMinification: true,
+	})
+	require.NoError(t, err)
+
+	var details = &errorpages.Details{Host: "example.com", RequestID: "abc"}
+
+	html, err := r.Render(404, errorpages.FormatHTML, details)
+	require.NoError(t, err)
+	assert.Equal(t, "<p>404 Not Found [example.com] [abc] false</p>", string(html))
+
+	json, err := r.Render(503, errorpages.FormatJSON, details)
+	require.NoError(t, err)
+	assert.Contains(t, string(json), `"code": 503`)
+	assert.Contains(t, string(json), `"host": "example.com"`)
+
+	xml, err := r.Render(404, errorpages.FormatXML, nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(xml), "<code>404</code>")
+
+	text, err := r.Render(404, errorpages.FormatPlainText, nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(text), "Error 404: Not Found")
+
+	broken, err := errorpages.New(errorpages.Config{TemplateContent: "{{ foo"})
+	require.NoError(t, err)
+
+	_, err = broken.Render(404, errorpages.FormatHTML, nil)
+	require.ErrorContains(t, err, "failed to render the html template")
+}
+
+func TestRenderer_Render_BuiltIn(t *testing.T) {
+	t.Parallel()
+
+	r, err := errorpages.New(errorpages.Config{Template: "ghost"})
+	require.NoError(t, err)
+
+	html, err := r.Render(502, errorpages.FormatHTML, nil)
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "Bad Gateway")
+	assert.Contains(t, string(html), "l10n") // the localization script is embedded
+}
+
+func TestRenderer_NegotiateFormat(t *testing.T) { //nolint:lll
+	t.Parallel()
+
+	r, err := errorpages.New(errorpages.Config{})
+	require.NoError(t, err)
+
+	for name, tt := range map[string]struct {
+		giveHeaders map[string]string
+		wantFormat  errorpages.Format
+	}{
+		"accept html":  {giveHeaders: map[string]string{"Accept": "text/html,application/xhtml+xml"}, wantFormat: errorpages.FormatHTML},
+		"accept json":  {giveHeaders: map[string]string{"Accept": "application/json"}, wantFormat: errorpages.FormatJSON},
+		"x-format xml": {giveHeaders: map[string]string{"X-Format": "application/xml"}, wantFormat: errorpages.FormatXML},
+		"content type": {giveHeaders: map[string]string{"Content-Type": "application/json", "Accept": "text/html"}, wantFormat: errorpages.FormatJSON},
+		"weights":      {giveHeaders: map[string]string{"Accept": "text/html;q=0.5,application/json;q=0.9"}, wantFormat: errorpages.FormatJSON},
+		"unknown":      {giveHeaders: map[string]string{"Accept": "image/png"}, wantFormat: errorpages.FormatPlainText},
+		"none":         {wantFormat: errorpages.FormatPlainText},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var req = httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+
+			for k, v := range tt.giveHeaders {
+				req.Header.Set(k, v)
+			}
+
+			var format = r.NegotiateFormat(req)
+
+			assert.Equal(t, tt.wantFormat, format)
+			assert.NotEmpty(t, format.ContentType())
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package errorpages
+
+import (
+	"mime"
+	"net/http"
+
+	"gh.tarampamp.am/error-pages/internal/config"
+	"gh.tarampamp.am/error-pages/internal/http/handlers/error_page"
+)
+
+// Handler returns the [http.Handler] equivalent to the error pages server handler: the code is taken from the URL
+// (e.g., `/404` or `/404.json`) or the `X-Code` request header (404 is used by default), the format is forced by
+// the URL extension or negotiated (see [Renderer.NegotiateFormat]), and the request details are taken from the
+// ingress-nginx headers.
+func (r *Renderer) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var code, format, ok = error_page.CodeFromURL(req.URL.Path)
+
+		if !ok {
+			var query = func(name string) string { return req.URL.Query().Get(name) }
+
+			if code, ok = error_page.CodeFromSources(r.cfg.CodeSources, req.Header.Get, query); !ok {
+				code = r.cfg.DefaultCodeToRender
+			}
+		}
+
+		var f = Format(format)
+
+		if f == "" {
+			f = r.NegotiateFormat(req)
+		}
+
+		var httpCode = http.StatusOK
+
+		if r.cfg.RespondWithSameHTTPCode {
+			httpCode = int(code)
+		}
+
+		// https://developers.google.com/search/docs/crawling-indexing/robots-meta-tag
+		// disallow indexing of the error pages
+		w.Header().Set("X-Robots-Tag", "noindex")
+
+		if retryAfter := error_page.RetryAfter(code); retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+
+		r.write(w, req, httpCode, code, f, &Details{
+			OriginalURI:  req.Header.Get("X-Original-URI"),
+			Namespace:    req.Header.Get("X-Namespace"),
+			IngressName:  req.Header.Get("X-Ingress-Name"),
+			ServiceName:  req.Header.Get("X-Service-Name"),
+			ServicePort:  req.Header.Get("X-Service-Port"),
+			RequestID:    req.Header.Get("X-Request-Id"),
+			ForwardedFor: req.Header.Get("X-Forwarded-For"),
+			Host:         req.Host,
+		})
+	})
+}
+
+// Middleware returns the middleware that replaces the bodies of the application responses having the given HTTP
+// codes (written in a non-strict manner, e.g. "404" or "5**"; the default is "404" and "5**") with the rendered
+// error pages. Only the responses without the content type or with the plain text one are replaced, so the
+// application's own HTML or JSON error responses are kept as is.
+func (r *Renderer) Middleware(codes ...string) func(http.Handler) http.Handler {
+	if len(codes) == 0 {
+		codes = []string{"404", "5**"}
+	}
+
+	var intercept = make(config.Codes, len(codes))
+
+	for _, c := range codes {
+		intercept[c] = config.CodeDescription{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			var iw = interceptWriter{ResponseWriter: w, intercept: func(code int) bool {
+				if code < 0 || code > 999 {
+					return false
+				}
+
+				_, found := intercept.Find(uint16(code))
+
+				return found
+			}}
+
+			next.ServeHTTP(&iw, req)
+
+			if iw.code == 0 {
+				return // not intercepted
+			}
+
+			r.write(w, req, iw.code, uint16(iw.code), r.NegotiateFormat(req), &Details{ //nolint:gosec
+				OriginalURI:  req.URL.RequestURI(),
+				RequestID:    req.Header.Get("X-Request-Id"),
+				ForwardedFor: req.Header.Get("X-Forwarded-For"),
+				Host:         req.Host,
+			})
+		})
+	}
+}
+
+// replacedHeaders describe the (intercepted) response body, so they must not be sent with the error page.
+var replacedHeaders = []string{ //nolint:gochecknoglobals
+	"Content-Length", "Content-Encoding", "Content-Range", "Accept-Ranges", "ETag", "Last-Modified",
+}
+
+// write renders the error page and writes it to the response with the given HTTP code.
+func (r *Renderer) write(w http.ResponseWriter, req *http.Request, httpCode int, code uint16, f Format, d *Details) {
+	content, err := r.Render(code, f, d)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	for _, h := range replacedHeaders {
+		w.Header().Del(h)
+	}
+
+	w.Header().Set("Content-Type", f.ContentType())
+	w.WriteHeader(httpCode)
+
+	if req.Method != http.MethodHead {
+		_, _ = w.Write(content)
+	}
+}
+
+// interceptWriter holds back the response with the intercepted HTTP code, so it can be replaced by the error page.
+type interceptWriter struct {
+	http.ResponseWriter
+
+	intercept   func(code int) bool
+	code        int // the intercepted HTTP code (zero if not intercepted)
+	wroteHeader bool
+}
+
+var _ http.Flusher = (*interceptWriter)(nil) // ensure the interface is implemented
+
+// WriteHeader implements the [http.ResponseWriter] interface. The informational (1xx) responses are forwarded as is,
+// since they are followed by the final one.
+func (w *interceptWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+
+	if code >= 100 && code <= 199 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+
+		return
+	}
+
+	w.wroteHeader = true
+
+	if w.intercept(code) && isPlainText(w.Header().Get("Content-Type")) {
+		w.code = code
+
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write implements the [http.ResponseWriter] interface. The body of the intercepted response is discarded.
+func (w *interceptWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.code != 0 {
+		return len(b), nil
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements the [http.Flusher] interface (the intercepted response is not flushed). Flushing before the
+// header is written sends the 200 OK (like the [http.ResponseWriter] does), so the codes written later are ignored.
+func (w *interceptWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.code == 0 {
+		_ = http.NewResponseController(w.ResponseWriter).Flush()
+	}
+}
+
+// Unwrap returns the original response writer (used by the [http.ResponseController]).
+func (w *interceptWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// isPlainText checks if the content type is empty or the plain text.
+func isPlainText(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+
+	return err == nil && mediaType == "text/plain"
+}
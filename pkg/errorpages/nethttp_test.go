@@ -0,0 +1,264 @@
+package errorpages_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gh.tarampamp.am/error-pages/pkg/errorpages"
+)
+
+func newRenderer(t *testing.T, cfg errorpages.Config) *errorpages.Renderer {
+	t.Helper()
+
+	cfg.TemplateContent = "<p>{{ code }} {{ message }} [{{ host }}] [{{ original_uri }}]</p>"
+	cfg.DisableMinification = true
+
+	r, err := errorpages.New(cfg)
+	require.NoError(t, err)
+
+	return r
+}
+
+func TestRenderer_Handler(t *testing.T) {
+	t.Parallel()
+
+	for name, tt := range map[string]struct {
+		giveConfig  errorpages.Config
+		giveURL     string
+		giveHeaders map[string]string
+		wantCode    int
+		wantType    string
+		wantBody    string
+	}{
+		"code in URL": {
+			giveURL:  "/503",
+			wantCode: http.StatusOK,
+			wantType: "text/plain; charset=utf-8",
+			wantBody: "Error 503: Service Unavailable",
+		},
+		"code in URL with the same HTTP code": {
+			giveConfig:  errorpages.Config{RespondWithSameHTTPCode: true},
+			giveURL:     "/404.html",
+			giveHeaders: map[string]string{"Accept": "text/html"},
+			wantCode:    http.StatusNotFound,
+			wantType:    "text/html; charset=utf-8",
+			wantBody:    "<p>404 Not Found [] []</p>", // the details are hidden
+		},
+		"format in URL": {
+			giveURL:     "/500.json",
+			giveHeaders: map[string]string{"Accept": "text/html"},
+			wantCode:    http.StatusOK,
+			wantType:    "application/json; charset=utf-8",
+			wantBody:    `"code": 500`,
+		},
+		"code in header with details": {
+			giveConfig: errorpages.Config{ShowDetails: true, RespondWithSameHTTPCode: true},
+			giveURL:    "/",
+			giveHeaders: map[string]string{
+				"X-Code": "502", "Accept": "text/html", "X-Original-URI": "/foo",
+			},
+			wantCode: http.StatusBadGateway,
+			wantType: "text/html; charset=utf-8",
+			wantBody: "<p>502 Bad Gateway [example.com] [/foo]</p>",
+		},
+		"default code": {
+			giveConfig: errorpages.Config{RespondWithSameHTTPCode: true},
+			giveURL:    "/foo",
+			wantCode:   http.StatusNotFound,
+			wantType:   "text/plain; charset=utf-8",
+			wantBody:   "Error 404: Not Found",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				req = httptest.NewRequest(http.MethodGet, "http://example.com"+tt.giveURL, http.NoBody)
+				rec = httptest.NewRecorder()
+			)
+
+			for k, v := range tt.giveHeaders {
+				req.Header.Set(k, v)
+			}
+
+			newRenderer(t, tt.giveConfig).Handler().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, tt.wantType, rec.Header().Get("Content-Type"))
+			assert.Equal(t, "noindex", rec.Header().Get("X-Robots-Tag"))
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestRenderer_Middleware(t *testing.T) { //nolint:lll
+	t.Parallel()
+
+	var app = http.NewServeMux()
+
+	app.HandleFunc("/ok", func(w http.ResponseWriter, _ *http.Request) { _, _ = io.WriteString(w, "fine") })
+	app.HandleFunc("/plain", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+	})
+	app.HandleFunc("/empty", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusBadGateway) })
+	app.HandleFunc("/json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = io.WriteString(w, `{"error": "own"}`)
+	})
+	app.HandleFunc("/gzip", func(w http.ResponseWriter, _ *http.Request) {
+		for k, v := range map[string]string{
+			"Content-Type": "text/plain", "Content-Encoding": "gzip", "Content-Range": "bytes 0-3/4",
+			"Accept-Ranges": "bytes", "ETag": `"abc"`, "Last-Modified": "Mon, 01 Jan 2024 00:00:00 GMT",
+		} {
+			w.Header().Set(k, v)
+		}
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = io.WriteString(w, "\x1f\x8b")
+	})
+	app.HandleFunc("/teapot", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "short and stout", http.StatusTeapot)
+	})
+
+	var handler = newRenderer(t, errorpages.Config{ShowDetails: true}).Middleware()(app)
+
+	for name, tt := range map[string]struct {
+		giveURL  string
+		wantCode int
+		wantBody string
+	}{
+		"success":           {giveURL: "/ok", wantCode: http.StatusOK, wantBody: "fine"},
+		"plain body":        {giveURL: "/plain", wantCode: http.StatusInternalServerError, wantBody: "<p>500 Internal Server Error [example.com] [/plain]</p>"},
+		"empty body":        {giveURL: "/empty", wantCode: http.StatusBadGateway, wantBody: "<p>502 Bad Gateway [example.com] [/empty]</p>"},
+		"not found":         {giveURL: "/missing?foo=bar", wantCode: http.StatusNotFound, wantBody: "<p>404 Not Found [example.com] [/missing?foo=bar]</p>"},
+		"own JSON response": {giveURL: "/json", wantCode: http.StatusInternalServerError, wantBody: `{"error": "own"}`},
+		"not intercepted":   {giveURL: "/teapot", wantCode: http.StatusTeapot, wantBody: "short and stout\n"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				req = httptest.NewRequest(http.MethodGet, "http://example.com"+tt.giveURL, http.NoBody)
+				rec = httptest.NewRecorder()
+			)
+
+			req.Header.Set("Accept", "text/html")
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+			assert.Equal(t, tt.wantBody, rec.Body.String())
+		})
+	}
+
+	t.Run("the app body headers are removed", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			req = httptest.NewRequest(http.MethodGet, "http://example.com/gzip", http.NoBody)
+			rec = httptest.NewRecorder()
+		)
+
+		req.Header.Set("Accept", "text/html")
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, "<p>503 Service Unavailable [example.com] [/gzip]</p>", rec.Body.String())
+
+		for _, h := range []string{"Content-Encoding", "Content-Range", "Accept-Ranges", "ETag", "Last-Modified"} {
+			assert.Empty(t, rec.Header().Get(h), h)
+		}
+	})
+}
+
+func TestRenderer_Middleware_Informational(t *testing.T) {
+	t.Parallel()
+
+	var srv = httptest.NewServer(newRenderer(t, errorpages.Config{}).Middleware()(http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Link", "</style.css>; rel=preload; as=style")
+			w.WriteHeader(http.StatusEarlyHints) // forwarded, and not treated as the final response
+
+			http.Error(w, "something went wrong", http.StatusInternalServerError)
+		},
+	)))
+
+	defer srv.Close()
+
+	var informational []int
+
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, _ textproto.MIMEHeader) error {
+			informational = append(informational, code)
+
+			return nil
+		},
+	}), http.MethodGet, srv.URL, http.NoBody)
+	require.NoError(t, err)
+
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{http.StatusEarlyHints}, informational)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Contains(t, string(body), "<p>500 Internal Server Error")
+}
+
+func TestRenderer_Middleware_Codes(t *testing.T) {
+	t.Parallel()
+
+	var handler = newRenderer(t, errorpages.Config{}).Middleware("4xx")(http.NotFoundHandler())
+
+	var (
+		req = httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		rec = httptest.NewRecorder()
+	)
+
+	req.Header.Set("Accept", "application/json")
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `"message": "Not Found"`)
+}
+
+func TestRenderer_Middleware_FlushBeforeHeader(t *testing.T) {
+	t.Parallel()
+
+	var handler = newRenderer(t, errorpages.Config{}).Middleware()(http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			_ = http.NewResponseController(w).Flush() // sends the 200 OK
+
+			http.Error(w, "not found", http.StatusNotFound) // too late to change the code
+		},
+	))
+
+	var (
+		req = httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		rec = httptest.NewRecorder()
+	)
+
+	handler.ServeHTTP(rec, req)
+
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "not found\n", rec.Body.String())
+}
@@ -10,6 +10,13 @@ import (
 //go:embed *.html
 var content embed.FS
 
+//go:embed maintenance/maintenance.html
+var maintenance string
+
+// Maintenance returns the built-in maintenance page template. It's not a part of the [BuiltIn] templates, since
+// it's used during the maintenance only (unless another template is set for it).
+func Maintenance() string { return maintenance }
+
 // BuiltIn returns a map of built-in templates. The key is the template name and the value is the template content.
 func BuiltIn() map[string]string {
 	var (
@@ -1,10 +1,13 @@
 package templates_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"gh.tarampamp.am/error-pages/internal/template"
 	"gh.tarampamp.am/error-pages/templates"
 )
 
@@ -20,3 +23,170 @@ func TestBuiltIn(t *testing.T) {
 		assert.NotEmpty(t, data)
 	}
 }
+
+func TestBuiltIn_RequestID(t *testing.T) {
+	t.Parallel()
+
+	for name, content := range templates.BuiltIn() {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// the request ID is shown even if the request details are hidden (without showing them)
+			withID, err := template.Render(content, template.Props{Code: 404, RequestID: "req-id-42", L10nDisabled: true})
+			require.NoError(t, err)
+
+			assert.Contains(t, withID, "req-id-42")
+			assert.NotContains(t, withID, "Timestamp")
+			assert.NotContains(t, withID, `"details"`) // the details block class or ID
+
+			withDetails, err := template.Render(content, template.Props{
+				Code: 404, RequestID: "req-id-42", Host: "example.com", ShowRequestDetails: true, L10nDisabled: true,
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, 1, strings.Count(withDetails, "req-id-42")) // in the details only
+			assert.Contains(t, withDetails, "example.com")
+			assert.Contains(t, withDetails, "Timestamp")
+
+			without, err := template.Render(content, template.Props{Code: 404, L10nDisabled: true})
+			require.NoError(t, err)
+
+			assert.NotContains(t, without, "Request ID")
+
+			// the request ID is provided by the client, so it's escaped
+			escaped, err := template.Render(content, template.Props{Code: 404, RequestID: "<b>x</b>", L10nDisabled: true})
+			require.NoError(t, err)
+
+			assert.Contains(t, escaped, "&lt;b&gt;x&lt;/b&gt;")
+			assert.NotContains(t, escaped, "<b>x</b>")
+		})
+	}
+}
+
+func TestBuiltIn_Maintenance(t *testing.T) {
+	t.Parallel()
+
+	for name, content := range templates.BuiltIn() {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			withEnd, err := template.Render(content, template.Props{
+				Code: 503, Maintenance: true, MaintenanceEnd: "2024-01-01T12:00:00Z", MaintenanceLeft: 60, L10nDisabled: true,
+			})
+			require.NoError(t, err)
+
+			assert.Contains(t, withEnd,
+				`<span data-l10n>Scheduled maintenance until</span> <time datetime="2024-01-01T12:00:00Z">`,
+			)
+
+			withoutEnd, err := template.Render(content, template.Props{Code: 503, Maintenance: true, L10nDisabled: true})
+			require.NoError(t, err)
+
+			assert.Contains(t, withoutEnd, "<span data-l10n>Scheduled maintenance</span>")
+			assert.NotContains(t, withoutEnd, "Scheduled maintenance until")
+
+			without, err := template.Render(content, template.Props{Code: 503, L10nDisabled: true})
+			require.NoError(t, err)
+
+			assert.NotContains(t, without, "Scheduled maintenance")
+		})
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	t.Parallel()
+
+	var content = templates.Maintenance()
+
+	assert.NotContains(t, templates.BuiltIn(), "maintenance") // not selectable as the usual template
+
+	withEnd, err := template.Render(content, template.Props{
+		Code: 503, Maintenance: true, MaintenanceEnd: "2024-01-01T12:00:00Z", MaintenanceLeft: 60, RequestID: "<b>x</b>",
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, withEnd, `<time datetime="2024-01-01T12:00:00Z">`)
+	assert.Contains(t, withEnd, `data-left="60"`)
+	assert.Contains(t, withEnd, "&lt;b&gt;x&lt;/b&gt;")
+
+	withoutEnd, err := template.Render(content, template.Props{Code: 503, Maintenance: true, L10nDisabled: true})
+	require.NoError(t, err)
+
+	assert.Contains(t, withoutEnd, "Scheduled maintenance")
+	assert.NotContains(t, withoutEnd, "<time")
+	assert.NotContains(t, withoutEnd, "localizeDocument") // the localization script is not included
+}
+
+func TestBuiltIn_Incident(t *testing.T) {
+	t.Parallel()
+
+	var all = templates.BuiltIn()
+
+	all["maintenance"] = templates.Maintenance()
+
+	for name, content := range all {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// the incident comes from the external status page, so it's escaped
+			withIncident, err := template.Render(content, template.Props{
+				Code:          503,
+				IncidentTitle: "DB <b>down</b>",
+				IncidentBody:  `We are <script>failing</script> over`,
+				IncidentURL:   `https://status.example.com/?a=1&b="2"`,
+				L10nDisabled:  true,
+			})
+			require.NoError(t, err)
+
+			assert.Contains(t, withIncident, "<strong>DB &lt;b&gt;down&lt;/b&gt;</strong>")
+			assert.Contains(t, withIncident, ": We are &lt;script&gt;failing&lt;/script&gt; over")
+			assert.Contains(t, withIncident,
+				`<a href="https://status.example.com/?a=1&amp;b=&#34;2&#34;" data-l10n>More details</a>`,
+			)
+			assert.NotContains(t, withIncident, "<b>down</b>")
+			assert.NotContains(t, withIncident, "<script>failing")
+
+			titleOnly, err := template.Render(content, template.Props{
+				Code: 503, IncidentTitle: "DB down", L10nDisabled: true,
+			})
+			require.NoError(t, err)
+
+			assert.Contains(t, titleOnly, "<strong>DB down</strong>")
+			assert.NotContains(t, titleOnly, "More details")
+
+			without, err := template.Render(content, template.Props{Code: 503, L10nDisabled: true})
+			require.NoError(t, err)
+
+			assert.NotContains(t, without, "<strong>")
+			assert.NotContains(t, without, "More details")
+		})
+	}
+}
+
+func TestBuiltIn_Suggestions(t *testing.T) {
+	t.Parallel()
+
+	for name, content := range templates.BuiltIn() {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			withSuggestions, err := template.Render(content, template.Props{
+				Code:         404,
+				Suggestions:  []string{"https://example.com/docs/install", `/search?q="x"&y=<b>`},
+				L10nDisabled: true,
+			})
+			require.NoError(t, err)
+
+			assert.Contains(t, withSuggestions, "<span data-l10n>Maybe you were looking for</span>: "+
+				`<a href="https://example.com/docs/install">https://example.com/docs/install</a>, `+
+				`<a href="/search?q=&#34;x&#34;&amp;y=&lt;b&gt;">/search?q=&#34;x&#34;&amp;y=&lt;b&gt;</a>`,
+			)
+			assert.NotContains(t, withSuggestions, "y=<b>")
+
+			without, err := template.Render(content, template.Props{Code: 404, L10nDisabled: true})
+			require.NoError(t, err)
+
+			assert.NotContains(t, without, "Maybe you were looking for")
+		})
+	}
+}